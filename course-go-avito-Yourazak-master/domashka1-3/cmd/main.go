@@ -2,9 +2,12 @@ package main
 
 import (
 	"avito-courier/internal/config"
+	"avito-courier/internal/gateway/blob"
 	"avito-courier/internal/gateway/order"
+	"avito-courier/internal/gateway/webhook"
 	"avito-courier/internal/handler"
 	"avito-courier/internal/middleware"
+	"avito-courier/internal/model"
 	"avito-courier/internal/repository"
 	"avito-courier/internal/router"
 	"avito-courier/internal/transport/kafka"
@@ -16,9 +19,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"os/signal"
-	"strconv"
 	"sync"
 	"syscall"
 	"time"
@@ -37,7 +38,7 @@ func main() {
 	log.Printf("Starting Courier Service")
 	log.Printf("Port: %s | Metrics: %v", cfg.Port, cfg.Metrics.Enabled)
 	log.Printf("Kafka: %s (topic: %s)", cfg.Kafka.Brokers, cfg.Kafka.OrderTopic)
-	log.Printf("Rate Limit: %v (%.1f RPS)", cfg.RateLimit.Enabled, cfg.RateLimit.RequestsPerSecond, cfg.RateLimit.Burst)
+	log.Printf("Rate Limit: %v (%.1f RPS, burst %d)", cfg.RateLimit.Enabled, cfg.RateLimit.RequestsPerSecond, cfg.RateLimit.Burst)
 
 	if cfg.Pprof.Enabled {
 		go func() {
@@ -59,21 +60,110 @@ func main() {
 
 	courierRepo := repository.NewCourierRepository(pool)
 	deliveryRepo := repository.NewDeliveryRepository(pool)
+	cursorRepo := repository.NewCursorRepository(pool)
+	shiftRepo := repository.NewShiftRepository(pool)
+	locationRepo := repository.NewLocationRepository(pool)
+	zoneRepo := repository.NewZoneRepository(pool)
+	capabilityRepo := repository.NewCapabilityRepository(pool)
+	historyRepo := repository.NewStatusHistoryRepository(pool)
+	pauseRepo := repository.NewPauseRepository(pool)
+	pendingRepo := repository.NewPendingAssignmentRepository(pool)
+	scheduledRepo := repository.NewScheduledDeliveryRepository(pool)
+	routeRepo := repository.NewRouteRepository(pool)
+	offerRepo := repository.NewOfferRepository(pool)
+	webhookRepo := repository.NewWebhookRepository(pool)
+
+	orderGateway := order.NewHTTPOrderGateway(cfg)
+	log.Println("Order gateway initialized")
+
+	blobStore, err := blob.NewLocalStore(cfg.Blobs.Dir)
+	if err != nil {
+		log.Fatalf("Failed to open blob store: %v", err)
+	}
 
 	deliveryFactory := usecase.NewDeliveryTimeFactory()
+	routePlanner := usecase.NewRoutePlanner(usecase.NewStraightLineEstimator(cfg.Assignment.TransportSpeeds), cfg.Assignment.StopServiceTime)
+
+	transportLimits := make(map[string]model.TransportLimits, len(cfg.Assignment.TransportLimits))
+	for transport, l := range cfg.Assignment.TransportLimits {
+		transportLimits[transport] = model.TransportLimits{
+			MaxWeightKg:   l.MaxWeightKg,
+			MaxVolumeL:    l.MaxVolumeL,
+			MaxDistanceKm: l.MaxDistanceKm,
+		}
+	}
 
-	deliveryUC := usecase.NewDeliveryUsecase(pool, courierRepo, deliveryRepo, deliveryFactory)
-	courierUC := usecase.NewCourierUsecase(courierRepo, deliveryUC)
+	zoneUC := usecase.NewZoneUsecase(zoneRepo, courierRepo)
+	deliveryUC := usecase.NewDeliveryUsecase(pool, courierRepo, deliveryRepo, pendingRepo, scheduledRepo, routeRepo, offerRepo, deliveryFactory, routePlanner, blobStore, orderGateway, zoneUC, usecase.AssignmentConfig{
+		SearchRadiusKm:   cfg.Assignment.SearchRadiusKm,
+		LocationMaxAge:   cfg.Assignment.LocationMaxAge,
+		CandidateLimit:   cfg.Assignment.CandidateLimit,
+		ZoneSpillover:    cfg.Assignment.ZoneSpillover,
+		TransportLimits:  transportLimits,
+		ScheduleLeadTime: cfg.Assignment.ScheduleLeadTime,
+		Batching:         cfg.Assignment.BatchEnabled,
+		MaxAttempts:      cfg.Assignment.MaxAttempts,
+		OfferMode:        cfg.Assignment.OfferMode,
+		OfferTTL:         cfg.Assignment.OfferTTL,
+		OfferCooldown:    cfg.Assignment.OfferCooldown,
+	})
+	courierUC := usecase.NewCourierUsecase(courierRepo, deliveryUC, cfg.Couriers.TransportTypes)
+	shiftUC := usecase.NewShiftUsecase(shiftRepo, courierRepo)
+	locationUC := usecase.NewLocationUsecase(locationRepo)
+	capabilityUC := usecase.NewCapabilityUsecase(capabilityRepo, courierRepo, transportLimits)
+	historyUC := usecase.NewStatusHistoryUsecase(historyRepo, courierRepo)
+	pauseUC := usecase.NewPauseUsecase(pauseRepo, courierRepo)
+	assignmentQueue := usecase.NewAssignmentQueue(pendingRepo, deliveryUC, repository.NewAdvisoryLock(pool, usecase.AssignmentQueueLockKey), usecase.AssignmentQueueConfig{
+		Interval:    cfg.Assignment.QueueInterval,
+		BatchSize:   cfg.Assignment.QueueBatchSize,
+		MaxAttempts: cfg.Assignment.QueueMaxAttempts,
+		BackoffBase: cfg.Assignment.QueueBackoffBase,
+		BackoffMax:  cfg.Assignment.QueueBackoffMax,
+	})
+	deliveryScheduler := usecase.NewDeliveryScheduler(scheduledRepo, deliveryUC, repository.NewAdvisoryLock(pool, usecase.DeliverySchedulerLockKey), usecase.DeliverySchedulerConfig{
+		Interval: cfg.Assignment.ScheduleInterval,
+		LeadTime: cfg.Assignment.ScheduleLeadTime,
+	})
+	routeUC := usecase.NewRouteUsecase(routeRepo, courierRepo, locationRepo, routePlanner)
+	webhookUC := usecase.NewWebhookUsecase(webhookRepo)
+	webhookDispatcher := usecase.NewWebhookDispatcher(webhookRepo, webhook.NewHTTPSender(cfg.Webhooks.Timeout), repository.NewAdvisoryLock(pool, usecase.WebhookDispatcherLockKey), usecase.WebhookDispatcherConfig{
+		Interval:     cfg.Webhooks.DispatchInterval,
+		BatchSize:    cfg.Webhooks.DispatchBatchSize,
+		MaxAttempts:  cfg.Webhooks.MaxAttempts,
+		BackoffBase:  cfg.Webhooks.BackoffBase,
+		BackoffMax:   cfg.Webhooks.BackoffMax,
+		DisableAfter: cfg.Webhooks.DisableAfter,
+	})
 
 	eventDeliveryUC := usecase.NewEventDeliveryUsecase(deliveryUC)
 	eventFactory := usecase.NewEventHandlerFactory(eventDeliveryUC)
 	log.Println("Event handler factory initialized")
 
-	orderGateway := order.NewHTTPOrderGateway(cfg)
-	log.Println("Order gateway initialized")
+	eventProcessor := usecase.NewOrderEventProcessor(eventFactory, orderGateway)
 
 	courierHandler := handler.NewCourierHandler(courierUC)
 	deliveryHandler := handler.NewDeliveryHandler(deliveryUC)
+	shiftHandler := handler.NewShiftHandler(shiftUC)
+	locationHandler := handler.NewLocationHandler(locationUC)
+	zoneHandler := handler.NewZoneHandler(zoneUC)
+	capabilityHandler := handler.NewCapabilityHandler(capabilityUC)
+	historyHandler := handler.NewStatusHistoryHandler(historyUC)
+	pauseHandler := handler.NewPauseHandler(pauseUC)
+	queueHandler := handler.NewAssignmentQueueHandler(assignmentQueue)
+	routeHandler := handler.NewRouteHandler(routeUC)
+	courierTokens := middleware.NewCourierTokens(cfg.Couriers.TokenSecret, cfg.Couriers.TokenTTL)
+	if cfg.Couriers.TokenSecret == "" {
+		log.Println("COURIER_TOKEN_SECRET is not set, courier API is disabled")
+	}
+	if cfg.Couriers.DispatcherKey == "" {
+		log.Println("DISPATCHER_API_KEY is not set, courier token issuance is disabled")
+	}
+	meHandler := handler.NewMeHandler(deliveryUC, pauseUC, courierUC, courierTokens, cfg.Couriers.DispatcherKey)
+	deliveryStream := usecase.NewDeliveryStream()
+	streamHandler := handler.NewStreamHandler(deliveryStream)
+	webhookHandler := handler.NewWebhookHandler(eventProcessor, cfg.Webhooks.PartnerSecrets, cfg.Webhooks.SignatureTolerance)
+	log.Printf("Webhook ingestion configured for %d partners", len(cfg.Webhooks.PartnerSecrets))
+	subscriptionHandler := handler.NewWebhookSubscriptionHandler(webhookUC)
 
 	var rateLimiter *middleware.RateLimiter
 	if cfg.RateLimit.Enabled {
@@ -91,7 +181,7 @@ func main() {
 		startPprofServer(cfg.Pprof.Port)
 	}
 
-	mux := router.NewRouter(courierHandler, deliveryHandler, rateLimiter)
+	mux := router.NewRouter(courierHandler, deliveryHandler, webhookHandler, shiftHandler, locationHandler, zoneHandler, capabilityHandler, historyHandler, pauseHandler, queueHandler, routeHandler, meHandler, streamHandler, subscriptionHandler, rateLimiter)
 
 	var httpHandler http.Handler = mux
 	if cfg.Metrics.Enabled {
@@ -99,14 +189,57 @@ func main() {
 		log.Printf("Prometheus metrics enabled at %s", cfg.Metrics.Path)
 	}
 
-	if cfg.ServiceOrderURL != "" {
-		poller := usecase.NewOrderPoller(orderGateway, deliveryUC)
+	shiftScheduler := usecase.NewShiftScheduler(shiftRepo, repository.NewAdvisoryLock(pool, usecase.ShiftSchedulerLockKey), cfg.Shifts.SchedulerInterval)
+	go shiftScheduler.Start(ctx)
+
+	pauseScheduler := usecase.NewPauseScheduler(pauseRepo, cfg.Couriers.ResumeInterval)
+	go pauseScheduler.Start(ctx)
+
+	go assignmentQueue.Start(ctx)
+	go deliveryScheduler.Start(ctx)
+
+	deliveryReleaser := usecase.NewDeliveryReleaser(deliveryUC, repository.NewAdvisoryLock(pool, usecase.DeliveryReleaserLockKey), usecase.DeliveryReleaserConfig{
+		Interval: cfg.Assignment.ReleaseInterval,
+	})
+	go deliveryReleaser.Start(ctx)
+
+	if cfg.Assignment.BatchEnabled {
+		routeBatcher := usecase.NewRouteBatcher(routeRepo, deliveryUC, repository.NewAdvisoryLock(pool, usecase.RouteBatcherLockKey), usecase.RouteBatcherConfig{
+			Window:    cfg.Assignment.BatchWindow,
+			MaxOrders: cfg.Assignment.BatchMaxOrders,
+			RadiusKm:  cfg.Assignment.BatchRadiusKm,
+			Capacity:  usecase.MaxCapacity(transportLimits),
+		})
+		go routeBatcher.Start(ctx)
+	}
+	if cfg.Assignment.OfferMode {
+		offerExpirer := usecase.NewOfferExpirer(deliveryUC, repository.NewAdvisoryLock(pool, usecase.OfferExpirerLockKey), usecase.OfferExpirerConfig{
+			Interval: cfg.Assignment.OfferInterval,
+		})
+		go offerExpirer.Start(ctx)
+	}
+	go repository.Listen(ctx, pool, "courier_available", func(string) { assignmentQueue.Wake() })
+	go repository.Listen(ctx, pool, model.DeliveryEventsChannel, deliveryStream.Publish)
+	go webhookDispatcher.Start(ctx)
+	go repository.Listen(ctx, pool, model.WebhookPendingChannel, func(string) { webhookDispatcher.Wake() })
+
+	log.Printf("Order ingest mode: %s", cfg.Poller.Mode)
+
+	if cfg.Poller.PollingEnabled() && cfg.ServiceOrderURL != "" {
+		pollerLock := repository.NewAdvisoryLock(pool, usecase.OrderPollerLockKey)
+		poller := usecase.NewOrderPoller(orderGateway, eventProcessor, cursorRepo, pollerLock, usecase.OrderPollerConfig{
+			Interval:   cfg.Poller.Interval,
+			Overlap:    cfg.Poller.Overlap,
+			BatchSize:  cfg.Poller.BatchSize,
+			MaxRetries: cfg.Poller.MaxRetries,
+		})
 		go poller.Start(ctx)
-		log.Println("Order poller started (5s ticker)")
+		log.Printf("Order poller started (interval %v, overlap %v, batch %d)",
+			cfg.Poller.Interval, cfg.Poller.Overlap, cfg.Poller.BatchSize)
 	}
 
-	if len(cfg.Kafka.Brokers) > 0 && cfg.Kafka.OrderTopic != "" {
-		consumer := kafka.NewConsumer(eventFactory, orderGateway)
+	if cfg.Poller.KafkaEnabled() && len(cfg.Kafka.Brokers) > 0 && cfg.Kafka.OrderTopic != "" {
+		consumer := kafka.NewConsumer(eventProcessor)
 		go consumer.StartConsumerGroup(ctx,
 			cfg.Kafka.Brokers,
 			cfg.Kafka.ConsumerGroup,
@@ -121,6 +254,7 @@ func main() {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	server.RegisterOnShutdown(deliveryStream.Close)
 
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -134,9 +268,59 @@ func main() {
 		log.Println("GET    /api/couriers              - List all couriers")
 		log.Println("GET    /api/couriers/{id}         - Get courier by ID")
 		log.Println("POST   /api/couriers              - Create new courier")
+		log.Println("POST   /api/couriers/import       - Bulk import couriers (CSV or NDJSON)")
+		log.Println("GET    /api/couriers/export       - Export couriers (CSV or NDJSON)")
 		log.Println("PUT    /api/couriers/{id}         - Update courier")
+		log.Println("PATCH  /api/couriers/{id}         - Partially update courier (merge patch, If-Match)")
+		log.Println("POST   /api/couriers/{id}/shifts  - Schedule courier shift")
+		log.Println("GET    /api/couriers/{id}/shifts  - List courier shifts")
+		log.Println("GET    /api/shifts/{id}           - Get shift")
+		log.Println("PUT    /api/shifts/{id}           - Update shift")
+		log.Println("DELETE /api/shifts/{id}           - Delete shift")
+		log.Println("POST   /api/couriers/{id}/location - Report courier GPS position")
+		log.Println("GET    /api/couriers/{id}/location - Latest courier position")
+		log.Println("PUT    /api/couriers/{id}/zones   - Set courier delivery zones")
+		log.Println("GET    /api/couriers/{id}/zones   - List courier delivery zones")
+		log.Println("GET    /api/couriers/{id}/capabilities - Courier transport limits")
+		log.Println("PUT    /api/couriers/{id}/capabilities - Override courier transport limits")
+		log.Println("DELETE /api/couriers/{id}/capabilities - Reset courier transport limits")
+		log.Println("GET    /api/couriers/{id}/history - Courier status change history")
+		log.Println("POST   /api/couriers/{id}/pause - Pause courier")
+		log.Println("POST   /api/couriers/{id}/resume - Resume courier")
+		log.Println("GET    /api/couriers/{id}/scheduled-deliveries - Upcoming scheduled deliveries")
+		log.Println("GET    /api/zones                 - List delivery zones")
+		log.Println("POST   /api/zones                 - Create delivery zone")
+		log.Println("GET    /api/zones/{id}            - Get delivery zone")
+		log.Println("PUT    /api/zones/{id}            - Update delivery zone")
+		log.Println("DELETE /api/zones/{id}            - Delete delivery zone")
 		log.Println("POST   /api/delivery/assign       - Assign courier to order")
 		log.Println("POST   /api/delivery/unassign     - Unassign courier from order")
+		log.Println("POST   /api/delivery/{id}/reassign - Move delivery to another courier")
+		log.Println("POST   /api/delivery/{id}/complete - Complete delivery with PIN, signature and photo")
+		log.Println("POST   /api/delivery/{id}/fail    - Record failed attempt, retry or return to sender")
+		log.Println("POST   /api/couriers/{id}/token   - Issue courier app token (dispatcher key)")
+		log.Println("GET    /api/me/deliveries         - Courier: list my active deliveries")
+		log.Println("POST   /api/me/deliveries/{id}/pickup  - Courier: confirm pickup")
+		log.Println("POST   /api/me/deliveries/{id}/deliver - Courier: complete my delivery")
+		log.Println("POST   /api/me/deliveries/{id}/fail    - Courier: report failed attempt")
+		log.Println("POST   /api/me/status             - Courier: pause or resume myself")
+		log.Println("GET    /api/me/offers             - Courier: list my pending offers")
+		log.Println("POST   /api/me/offers/{id}/accept - Courier: accept offer")
+		log.Println("POST   /api/me/offers/{id}/decline - Courier: decline offer")
+		log.Println("GET    /api/couriers/{id}/offer-stats - Courier offer acceptance rate")
+		log.Println("GET    /api/assignments/pending - Orders waiting for a courier")
+		log.Println("POST   /api/routes/plan           - Plan stop order and ETAs for a courier")
+		log.Println("GET    /api/routes/{id}           - Route with ordered stops")
+		log.Println("POST   /api/routes/{id}/stops/{seq}/complete - Complete stop and replan route")
+		log.Println("GET    /api/stream/deliveries     - Delivery events (SSE, ?courier_id=&order_id=)")
+		log.Println("GET    /api/stream/deliveries/ws  - Delivery events (WebSocket, ?courier_id=&order_id=)")
+		log.Println("POST   /api/webhooks/orders       - Ingest signed order events")
+		log.Println("POST   /api/webhooks/subscriptions - Subscribe to delivery lifecycle webhooks")
+		log.Println("GET    /api/webhooks/subscriptions - List webhook subscriptions")
+		log.Println("GET    /api/webhooks/subscriptions/{id} - Get webhook subscription")
+		log.Println("PUT    /api/webhooks/subscriptions/{id} - Update or re-enable webhook subscription")
+		log.Println("DELETE /api/webhooks/subscriptions/{id} - Delete webhook subscription")
+		log.Println("GET    /api/webhooks/subscriptions/{id}/deliveries - Webhook delivery log")
 		log.Println("GET    /health                    - Health check")
 		if cfg.Metrics.Enabled {
 			log.Printf("  GET    %s                    - Prometheus metrics", cfg.Metrics.Path)
@@ -234,15 +418,3 @@ func registerMetrics() {
 		),
 	)
 }
-
-func startBackgroundJobs(ctx context.Context, deliveryUC *usecase.DeliveryUsecase) {
-	intervalSec := 10
-	if v := os.Getenv("DELIVERY_RELEASE_INTERVAL_SECONDS"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n > 0 {
-			intervalSec = n
-		}
-	}
-
-	go deliveryUC.AutoRelease(ctx, time.Duration(intervalSec)*time.Second)
-	log.Printf("Auto-release job started (interval: %ds)", intervalSec)
-}
@@ -5,18 +5,25 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Port            string            `json:"port"`
-	DB              DBSettings        `json:"db"`
-	ServiceOrderURL string            `json:"service_order_url"`
-	Kafka           KafkaSettings     `json:"kafka"`
-	Metrics         MetricsSettings   `json:"metrics"`
-	RateLimit       RateLimitSettings `json:"rate_limit"`
-	Pprof           PprofSettings     `json:"pprof"`
+	Port            string             `json:"port"`
+	DB              DBSettings         `json:"db"`
+	ServiceOrderURL string             `json:"service_order_url"`
+	Kafka           KafkaSettings      `json:"kafka"`
+	Metrics         MetricsSettings    `json:"metrics"`
+	RateLimit       RateLimitSettings  `json:"rate_limit"`
+	Pprof           PprofSettings      `json:"pprof"`
+	Poller          PollerSettings     `json:"poller"`
+	Webhooks        WebhookSettings    `json:"webhooks"`
+	Shifts          ShiftSettings      `json:"shifts"`
+	Assignment      AssignmentSettings `json:"assignment"`
+	Couriers        CourierSettings    `json:"couriers"`
+	Blobs           BlobSettings       `json:"blobs"`
 }
 
 type DBSettings struct {
@@ -44,6 +51,124 @@ type RateLimitSettings struct {
 	Burst             int     `json:"burst"`
 }
 
+const (
+	IngestModePoll   = "poll"
+	IngestModeKafka  = "kafka"
+	IngestModeHybrid = "hybrid"
+)
+
+type PollerSettings struct {
+	Mode      string        `json:"mode"`
+	Interval  time.Duration `json:"interval"`
+	Overlap   time.Duration `json:"overlap"`
+	BatchSize int           `json:"batch_size"`
+	// MaxRetries is how many ticks an order event may fail before the
+	// poller gives up on it and moves past it.
+	MaxRetries int `json:"max_retries"`
+}
+
+func (p PollerSettings) PollingEnabled() bool {
+	return p.Mode == IngestModePoll || p.Mode == IngestModeHybrid
+}
+
+func (p PollerSettings) KafkaEnabled() bool {
+	return p.Mode == IngestModeKafka || p.Mode == IngestModeHybrid
+}
+
+type WebhookSettings struct {
+	PartnerSecrets map[string]string `json:"-"`
+	// SignatureTolerance is how old (or how far in the future) the signing
+	// timestamp of an incoming webhook may be.
+	SignatureTolerance time.Duration `json:"signature_tolerance"`
+	// The remaining settings drive outgoing webhooks: due deliveries are
+	// sent every DispatchInterval, retried up to MaxAttempts times with a
+	// backoff doubling from BackoffBase to BackoffMax, and a subscription is
+	// disabled after DisableAfter consecutive failures.
+	DispatchInterval  time.Duration `json:"dispatch_interval"`
+	DispatchBatchSize int           `json:"dispatch_batch_size"`
+	Timeout           time.Duration `json:"timeout"`
+	MaxAttempts       int           `json:"max_attempts"`
+	BackoffBase       time.Duration `json:"backoff_base"`
+	BackoffMax        time.Duration `json:"backoff_max"`
+	DisableAfter      int           `json:"disable_after"`
+}
+
+type ShiftSettings struct {
+	SchedulerInterval time.Duration `json:"scheduler_interval"`
+}
+
+type AssignmentSettings struct {
+	SearchRadiusKm float64       `json:"search_radius_km"`
+	LocationMaxAge time.Duration `json:"location_max_age"`
+	CandidateLimit int           `json:"candidate_limit"`
+	ZoneSpillover  bool          `json:"zone_spillover"`
+	// TransportLimits is keyed by transport type; zero means unlimited.
+	TransportLimits map[string]TransportLimit `json:"transport_limits"`
+	// QueueInterval and QueueBatchSize drive the worker that retries orders
+	// which found no courier.
+	QueueInterval  time.Duration `json:"queue_interval"`
+	QueueBatchSize int           `json:"queue_batch_size"`
+	// QueueMaxAttempts failed attempts give an order up; the wait between
+	// attempts doubles from QueueBackoffBase up to QueueBackoffMax.
+	QueueMaxAttempts int           `json:"queue_max_attempts"`
+	QueueBackoffBase time.Duration `json:"queue_backoff_base"`
+	QueueBackoffMax  time.Duration `json:"queue_backoff_max"`
+	// ScheduleLeadTime is how long before its window a scheduled delivery is
+	// assigned; ScheduleInterval is how often due ones are looked for.
+	ScheduleLeadTime time.Duration `json:"schedule_lead_time"`
+	ScheduleInterval time.Duration `json:"schedule_interval"`
+	// Batch* configure route batching: orders from events are collected for
+	// BatchWindow and nearby ones go to one courier as a route.
+	BatchEnabled   bool          `json:"batch_enabled"`
+	BatchWindow    time.Duration `json:"batch_window"`
+	BatchMaxOrders int           `json:"batch_max_orders"`
+	BatchRadiusKm  float64       `json:"batch_radius_km"`
+	// TransportSpeeds holds the average speed in km/h per transport type
+	// used for ETAs; StopServiceTime is spent at every route stop.
+	TransportSpeeds map[string]float64 `json:"transport_speeds"`
+	StopServiceTime time.Duration      `json:"stop_service_time"`
+	// MaxAttempts is how many failed handovers an order gets before it is
+	// returned to the pickup point.
+	MaxAttempts int `json:"max_attempts"`
+	// ReleaseInterval is how often deliveries past their deadline are
+	// expired and their couriers freed.
+	ReleaseInterval time.Duration `json:"release_interval"`
+	// Offer* configure offer mode: automatic assignments are offered to the
+	// courier, who has OfferTTL to accept. Expired offers are checked every
+	// OfferInterval; a courier who passed on an order is skipped for it for
+	// OfferCooldown.
+	OfferMode     bool          `json:"offer_mode"`
+	OfferTTL      time.Duration `json:"offer_ttl"`
+	OfferCooldown time.Duration `json:"offer_cooldown"`
+	OfferInterval time.Duration `json:"offer_interval"`
+}
+
+type CourierSettings struct {
+	// TransportTypes is the registry of transport types a courier may have.
+	TransportTypes []string `json:"transport_types"`
+	// ResumeInterval is how often paused couriers with a resume time are
+	// checked.
+	ResumeInterval time.Duration `json:"resume_interval"`
+	// TokenSecret signs the bearer tokens of the courier app; the courier
+	// API is disabled without it.
+	TokenSecret string        `json:"-"`
+	TokenTTL    time.Duration `json:"token_ttl"`
+	// DispatcherKey is the bearer key dispatchers use to issue courier
+	// tokens; token issuance is disabled without it.
+	DispatcherKey string `json:"-"`
+}
+
+type BlobSettings struct {
+	// Dir is where the local blob store keeps proof of delivery uploads.
+	Dir string `json:"dir"`
+}
+
+type TransportLimit struct {
+	MaxWeightKg   float64 `json:"max_weight_kg"`
+	MaxVolumeL    float64 `json:"max_volume_l"`
+	MaxDistanceKm float64 `json:"max_distance_km"`
+}
+
 func LoadConfig() *Config {
 	_ = godotenv.Load()
 
@@ -63,6 +188,52 @@ func LoadConfig() *Config {
 	pprofPort := getEnv("PPROF_PORT", "6060")
 	pprofEndpoint := getEnv("PPROF_ENDPOINT", "/debug/pprof")
 
+	pollerMode := getEnv("ORDER_INGEST_MODE", IngestModeHybrid)
+	pollerInterval := parseDuration(getEnv("POLLER_INTERVAL", "5s"), 5*time.Second)
+	pollerOverlap := parseDuration(getEnv("POLLER_OVERLAP", "5s"), 5*time.Second)
+	pollerBatchSize := parseInt(getEnv("POLLER_BATCH_SIZE", "100"))
+	pollerMaxRetries := parseInt(getEnv("POLLER_MAX_RETRIES", "5"))
+
+	webhookSecrets := parsePairs(getEnv("WEBHOOK_PARTNER_SECRETS", ""))
+	webhookTolerance := parseDuration(getEnv("WEBHOOK_SIGNATURE_TOLERANCE", "5m"), 5*time.Minute)
+	webhookInterval := parseDuration(getEnv("WEBHOOK_DISPATCH_INTERVAL", "5s"), 5*time.Second)
+	webhookBatchSize := parseInt(getEnv("WEBHOOK_DISPATCH_BATCH_SIZE", "50"))
+	webhookTimeout := parseDuration(getEnv("WEBHOOK_TIMEOUT", "10s"), 10*time.Second)
+	webhookMaxAttempts := parseInt(getEnv("WEBHOOK_MAX_ATTEMPTS", "10"))
+	webhookBackoffBase := parseDuration(getEnv("WEBHOOK_BACKOFF_BASE", "30s"), 30*time.Second)
+	webhookBackoffMax := parseDuration(getEnv("WEBHOOK_BACKOFF_MAX", "1h"), time.Hour)
+	webhookDisableAfter := parseInt(getEnv("WEBHOOK_DISABLE_AFTER", "20"))
+
+	shiftInterval := parseDuration(getEnv("SHIFT_SCHEDULER_INTERVAL", "30s"), 30*time.Second)
+
+	searchRadiusKm := parseFloat(getEnv("ASSIGN_SEARCH_RADIUS_KM", "10"))
+	locationMaxAge := parseDuration(getEnv("ASSIGN_LOCATION_MAX_AGE", "10m"), 10*time.Minute)
+	candidateLimit := parseInt(getEnv("ASSIGN_CANDIDATE_LIMIT", "20"))
+	zoneSpillover := getEnv("ASSIGN_ZONE_SPILLOVER", "true") == "true"
+	queueInterval := parseDuration(getEnv("ASSIGN_QUEUE_INTERVAL", "10s"), 10*time.Second)
+	queueBatchSize := parseInt(getEnv("ASSIGN_QUEUE_BATCH_SIZE", "50"))
+	queueMaxAttempts := parseInt(getEnv("ASSIGN_QUEUE_MAX_ATTEMPTS", "30"))
+	queueBackoffBase := parseDuration(getEnv("ASSIGN_QUEUE_BACKOFF_BASE", "10s"), 10*time.Second)
+	queueBackoffMax := parseDuration(getEnv("ASSIGN_QUEUE_BACKOFF_MAX", "5m"), 5*time.Minute)
+	scheduleLeadTime := parseDuration(getEnv("SCHEDULE_LEAD_TIME", "30m"), 30*time.Minute)
+	scheduleInterval := parseDuration(getEnv("SCHEDULE_INTERVAL", "30s"), 30*time.Second)
+	batchEnabled := getEnv("ASSIGN_BATCH_ENABLED", "false") == "true"
+	batchWindow := parseDuration(getEnv("ASSIGN_BATCH_WINDOW", "1m"), time.Minute)
+	batchMaxOrders := parseInt(getEnv("ASSIGN_BATCH_MAX_ORDERS", "4"))
+	batchRadiusKm := parseFloat(getEnv("ASSIGN_BATCH_RADIUS_KM", "2"))
+	transportSpeeds := parseSpeeds(getEnv("TRANSPORT_SPEEDS", "on_foot:5,scooter:15,car:25"))
+	stopServiceTime := parseDuration(getEnv("ROUTE_STOP_SERVICE_TIME", "2m"), 2*time.Minute)
+	maxAttempts := parseInt(getEnv("DELIVERY_MAX_ATTEMPTS", "3"))
+	releaseInterval := parseDuration(getEnv("DELIVERY_RELEASE_INTERVAL", "10s"), 10*time.Second)
+	offerMode := getEnv("ASSIGN_OFFER_MODE", "false") == "true"
+	offerTTL := parseDuration(getEnv("ASSIGN_OFFER_TTL", "60s"), time.Minute)
+	offerCooldown := parseDuration(getEnv("ASSIGN_OFFER_COOLDOWN", "10m"), 10*time.Minute)
+	offerInterval := parseDuration(getEnv("ASSIGN_OFFER_INTERVAL", "5s"), 5*time.Second)
+	transportTypes := parseList(getEnv("TRANSPORT_TYPES", "on_foot,scooter,car"))
+	resumeInterval := parseDuration(getEnv("PAUSE_RESUME_INTERVAL", "30s"), 30*time.Second)
+	tokenTTL := parseDuration(getEnv("COURIER_TOKEN_TTL", "24h"), 24*time.Hour)
+	transportLimits := parseTransportLimits(getEnv("TRANSPORT_LIMITS", "on_foot:5/20/3,scooter:15/60/15,car:50/500/0"))
+
 	cfg := &Config{
 		Port:            *flagPort,
 		ServiceOrderURL: getEnv("SERVICE_ORDER_URL", "http://localhost:8080"),
@@ -92,6 +263,63 @@ func LoadConfig() *Config {
 			Port:     pprofPort,
 			Endpoint: pprofEndpoint,
 		},
+		Poller: PollerSettings{
+			Mode:       pollerMode,
+			Interval:   pollerInterval,
+			Overlap:    pollerOverlap,
+			BatchSize:  pollerBatchSize,
+			MaxRetries: pollerMaxRetries,
+		},
+		Webhooks: WebhookSettings{
+			PartnerSecrets:     webhookSecrets,
+			SignatureTolerance: webhookTolerance,
+			DispatchInterval:   webhookInterval,
+			DispatchBatchSize:  webhookBatchSize,
+			Timeout:            webhookTimeout,
+			MaxAttempts:        webhookMaxAttempts,
+			BackoffBase:        webhookBackoffBase,
+			BackoffMax:         webhookBackoffMax,
+			DisableAfter:       webhookDisableAfter,
+		},
+		Shifts: ShiftSettings{
+			SchedulerInterval: shiftInterval,
+		},
+		Assignment: AssignmentSettings{
+			SearchRadiusKm:   searchRadiusKm,
+			LocationMaxAge:   locationMaxAge,
+			CandidateLimit:   candidateLimit,
+			ZoneSpillover:    zoneSpillover,
+			TransportLimits:  transportLimits,
+			QueueInterval:    queueInterval,
+			QueueBatchSize:   queueBatchSize,
+			QueueMaxAttempts: queueMaxAttempts,
+			QueueBackoffBase: queueBackoffBase,
+			QueueBackoffMax:  queueBackoffMax,
+			ScheduleLeadTime: scheduleLeadTime,
+			ScheduleInterval: scheduleInterval,
+			BatchEnabled:     batchEnabled,
+			BatchWindow:      batchWindow,
+			BatchMaxOrders:   batchMaxOrders,
+			BatchRadiusKm:    batchRadiusKm,
+			TransportSpeeds:  transportSpeeds,
+			StopServiceTime:  stopServiceTime,
+			MaxAttempts:      maxAttempts,
+			ReleaseInterval:  releaseInterval,
+			OfferMode:        offerMode,
+			OfferTTL:         offerTTL,
+			OfferCooldown:    offerCooldown,
+			OfferInterval:    offerInterval,
+		},
+		Couriers: CourierSettings{
+			TransportTypes: transportTypes,
+			ResumeInterval: resumeInterval,
+			TokenSecret:    getEnv("COURIER_TOKEN_SECRET", ""),
+			TokenTTL:       tokenTTL,
+			DispatcherKey:  getEnv("DISPATCHER_API_KEY", ""),
+		},
+		Blobs: BlobSettings{
+			Dir: getEnv("BLOB_STORE_DIR", "./data/blobs"),
+		},
 	}
 
 	validateConfig(cfg)
@@ -127,6 +355,80 @@ func parseInt(s string) int {
 	return val
 }
 
+func parseDuration(s string, defaultValue time.Duration) time.Duration {
+	val, err := time.ParseDuration(s)
+	if err != nil {
+		return defaultValue
+	}
+	return val
+}
+
+// parseList splits a comma-separated list, dropping empty items.
+func parseList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// parsePairs parses "key1:value1,key2:value2" into a map, skipping malformed
+// entries.
+func parsePairs(s string) map[string]string {
+	out := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || key == "" || value == "" {
+			continue
+		}
+		out[key] = value
+	}
+	return out
+}
+
+// parseTransportLimits parses "type:weight/volume/distance,..." as produced by
+// parsePairs. Missing trailing values are zero; malformed entries are skipped.
+func parseTransportLimits(s string) map[string]TransportLimit {
+	out := make(map[string]TransportLimit)
+	for transport, spec := range parsePairs(s) {
+		var values [3]float64
+		parts := strings.Split(spec, "/")
+		if len(parts) > len(values) {
+			continue
+		}
+		valid := true
+		for i, part := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil || v < 0 {
+				valid = false
+				break
+			}
+			values[i] = v
+		}
+		if !valid {
+			continue
+		}
+		out[transport] = TransportLimit{MaxWeightKg: values[0], MaxVolumeL: values[1], MaxDistanceKm: values[2]}
+	}
+	return out
+}
+
+// parseSpeeds parses "type:kmh,..." and skips malformed or non-positive
+// speeds.
+func parseSpeeds(s string) map[string]float64 {
+	out := make(map[string]float64)
+	for transport, value := range parsePairs(s) {
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || v <= 0 {
+			continue
+		}
+		out[transport] = v
+	}
+	return out
+}
+
 func validateConfig(cfg *Config) {
 	if cfg.Port == "" {
 		panic("PORT is required")
@@ -143,4 +445,9 @@ func validateConfig(cfg *Config) {
 	if cfg.DB.Name == "" {
 		panic("POSTGRES_DB is required")
 	}
+	switch cfg.Poller.Mode {
+	case IngestModePoll, IngestModeKafka, IngestModeHybrid:
+	default:
+		panic("ORDER_INGEST_MODE must be one of poll, kafka, hybrid")
+	}
 }
@@ -35,3 +35,52 @@ func TestLoadConfig(t *testing.T) {
 	assert.Equal(t, "test-pass", cfg.DB.Password)
 	assert.Equal(t, "test-db", cfg.DB.Name)
 }
+
+func TestPollerSettings_Modes(t *testing.T) {
+	testCases := []struct {
+		mode    string
+		polling bool
+		kafka   bool
+	}{
+		{IngestModePoll, true, false},
+		{IngestModeKafka, false, true},
+		{IngestModeHybrid, true, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.mode, func(t *testing.T) {
+			p := PollerSettings{Mode: tc.mode}
+			assert.Equal(t, tc.polling, p.PollingEnabled())
+			assert.Equal(t, tc.kafka, p.KafkaEnabled())
+		})
+	}
+}
+
+func TestParsePairs(t *testing.T) {
+	pairs := parsePairs("partner-a:secret-a, partner-b:secret:b,broken,:empty")
+
+	assert.Equal(t, map[string]string{
+		"partner-a": "secret-a",
+		"partner-b": "secret:b",
+	}, pairs)
+}
+
+func TestParseTransportLimits(t *testing.T) {
+	limits := parseTransportLimits("on_foot:5/20/3, car:50, bike:x/1, truck:1/2/3/4")
+
+	assert.Equal(t, map[string]TransportLimit{
+		"on_foot": {MaxWeightKg: 5, MaxVolumeL: 20, MaxDistanceKm: 3},
+		"car":     {MaxWeightKg: 50},
+	}, limits)
+}
+
+func TestParseSpeeds(t *testing.T) {
+	speeds := parseSpeeds("on_foot:5, car:25.5, bike:x, boat:0")
+
+	assert.Equal(t, map[string]float64{"on_foot": 5, "car": 25.5}, speeds)
+}
+
+func TestParseList(t *testing.T) {
+	assert.Equal(t, []string{"on_foot", "bike"}, parseList(" on_foot, ,bike,"))
+	assert.Nil(t, parseList(""))
+}
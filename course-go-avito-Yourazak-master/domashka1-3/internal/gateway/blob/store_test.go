@@ -0,0 +1,34 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStore_PutOpen(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+
+	err = store.Put(context.Background(), "deliveries/7/photo.jpg", strings.NewReader("jpeg bytes"))
+	assert.NoError(t, err)
+
+	f, err := store.Open(context.Background(), "deliveries/7/photo.jpg")
+	if assert.NoError(t, err) {
+		defer f.Close()
+		data, _ := io.ReadAll(f)
+		assert.Equal(t, "jpeg bytes", string(data))
+	}
+}
+
+func TestLocalStore_InvalidKey(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+
+	for _, key := range []string{"", "/etc/passwd", "../outside", "a/../../b", "a//b", ".."} {
+		assert.Equal(t, ErrInvalidKey, store.Put(context.Background(), key, strings.NewReader("x")), key)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"avito-courier/internal/config"
@@ -14,7 +15,9 @@ import (
 
 type OrderGateway interface {
 	GetOrdersByCursor(ctx context.Context, cursor time.Time) ([]model.OrderEvent, error)
+	GetOrdersPage(ctx context.Context, cursor time.Time, limit int) ([]model.OrderEvent, error)
 	GetOrderStatus(ctx context.Context, orderID string) (*model.OrderEvent, error)
+	GetOrder(ctx context.Context, orderID string) (*model.ExternalOrder, error)
 }
 
 type HTTPOrderGateway struct {
@@ -32,6 +35,14 @@ func NewHTTPOrderGateway(cfg *config.Config) *HTTPOrderGateway {
 }
 
 func (g *HTTPOrderGateway) GetOrdersByCursor(ctx context.Context, cursor time.Time) ([]model.OrderEvent, error) {
+	return g.fetchOrders(ctx, cursor, 0)
+}
+
+func (g *HTTPOrderGateway) GetOrdersPage(ctx context.Context, cursor time.Time, limit int) ([]model.OrderEvent, error) {
+	return g.fetchOrders(ctx, cursor, limit)
+}
+
+func (g *HTTPOrderGateway) fetchOrders(ctx context.Context, cursor time.Time, limit int) ([]model.OrderEvent, error) {
 	u, err := url.Parse(g.baseURL + "/public/api/v1/orders")
 	if err != nil {
 		return nil, fmt.Errorf("parse url: %w", err)
@@ -39,6 +50,9 @@ func (g *HTTPOrderGateway) GetOrdersByCursor(ctx context.Context, cursor time.Ti
 
 	q := u.Query()
 	q.Set("from", cursor.Format(time.RFC3339))
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
 	u.RawQuery = q.Encode()
 
 	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
@@ -91,3 +105,30 @@ func (g *HTTPOrderGateway) GetOrderStatus(ctx context.Context, orderID string) (
 
 	return &order, nil
 }
+
+func (g *HTTPOrderGateway) GetOrder(ctx context.Context, orderID string) (*model.ExternalOrder, error) {
+	url := fmt.Sprintf("%s/public/api/v1/orders/%s", g.baseURL, orderID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	var order model.ExternalOrder
+	if err := json.NewDecoder(resp.Body).Decode(&order); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &order, nil
+}
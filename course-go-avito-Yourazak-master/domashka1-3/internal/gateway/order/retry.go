@@ -78,6 +78,40 @@ func (g *HTTPOrderGatewayWithRetry) GetOrdersByCursor(ctx context.Context, curso
 	return nil, fmt.Errorf("failed after %d retries: %w", g.retryConfig.MaxRetries, lastErr)
 }
 
+func (g *HTTPOrderGatewayWithRetry) GetOrdersPage(ctx context.Context, cursor time.Time, limit int) ([]model.OrderEvent, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= g.retryConfig.MaxRetries; attempt++ {
+		orders, err := g.HTTPOrderGateway.GetOrdersPage(ctx, cursor, limit)
+
+		if err == nil {
+			return orders, nil
+		}
+
+		lastErr = err
+
+		if !g.shouldRetry(err) || attempt == g.retryConfig.MaxRetries {
+			break
+		}
+
+		middleware.GatewayRetriesTotal.WithLabelValues(
+			"GetOrdersPage",
+			getErrorCode(err),
+			fmt.Sprintf("%d", attempt),
+		).Inc()
+
+		delay := g.calculateDelay(attempt)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, fmt.Errorf("failed after %d retries: %w", g.retryConfig.MaxRetries, lastErr)
+}
+
 func (g *HTTPOrderGatewayWithRetry) GetOrderStatus(ctx context.Context, orderID string) (*model.OrderEvent, error) {
 	var lastErr error
 
@@ -112,6 +146,40 @@ func (g *HTTPOrderGatewayWithRetry) GetOrderStatus(ctx context.Context, orderID
 	return nil, fmt.Errorf("failed after %d retries: %w", g.retryConfig.MaxRetries, lastErr)
 }
 
+func (g *HTTPOrderGatewayWithRetry) GetOrder(ctx context.Context, orderID string) (*model.ExternalOrder, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= g.retryConfig.MaxRetries; attempt++ {
+		order, err := g.HTTPOrderGateway.GetOrder(ctx, orderID)
+
+		if err == nil {
+			return order, nil
+		}
+
+		lastErr = err
+
+		if !g.shouldRetry(err) || attempt == g.retryConfig.MaxRetries {
+			break
+		}
+
+		middleware.GatewayRetriesTotal.WithLabelValues(
+			"GetOrder",
+			getErrorCode(err),
+			fmt.Sprintf("%d", attempt),
+		).Inc()
+
+		delay := g.calculateDelay(attempt)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, fmt.Errorf("failed after %d retries: %w", g.retryConfig.MaxRetries, lastErr)
+}
+
 func (g *HTTPOrderGatewayWithRetry) shouldRetry(err error) bool {
 	errStr := err.Error()
 
@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"avito-courier/internal/middleware"
+	"avito-courier/internal/model"
+)
+
+// Headers sent with every webhook request next to middleware.SignatureHeader,
+// which carries the HMAC-SHA256 of middleware.TimestampHeader and the body
+// under the subscription secret.
+const (
+	IDHeader      = "X-Webhook-ID"
+	EventHeader   = "X-Webhook-Event"
+	AttemptHeader = "X-Webhook-Attempt"
+)
+
+// Sender posts a queued delivery to its subscription URL. It returns the
+// response status, or 0 when no response was received; any status other
+// than 2xx is an error.
+type Sender interface {
+	Send(ctx context.Context, d model.WebhookDelivery) (int, error)
+}
+
+type HTTPSender struct {
+	client *http.Client
+}
+
+func NewHTTPSender(timeout time.Duration) *HTTPSender {
+	return &HTTPSender{
+		client: &http.Client{
+			Timeout: timeout,
+			// A redirect would resend the body to a URL the merchant did
+			// not register.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (s *HTTPSender) Send(ctx context.Context, d model.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IDHeader, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(EventHeader, d.Event)
+	req.Header.Set(AttemptHeader, strconv.Itoa(d.Attempts+1))
+	timestamp := time.Now().Unix()
+	req.Header.Set(middleware.TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(middleware.SignatureHeader, middleware.SignPayload(d.Secret, timestamp, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"avito-courier/internal/middleware"
+	"avito-courier/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPSender_SendSigned(t *testing.T) {
+	payload := []byte(`{"event":"assigned","order_id":"o1"}`)
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	s := NewHTTPSender(time.Second)
+	status, err := s.Send(context.Background(), model.WebhookDelivery{
+		ID: 42, Event: model.WebhookEventAssigned, Payload: payload, Attempts: 2, URL: srv.URL, Secret: "merchant-secret-1",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, payload, body)
+	assert.Equal(t, "42", got.Header.Get(IDHeader))
+	assert.Equal(t, "assigned", got.Header.Get(EventHeader))
+	assert.Equal(t, "3", got.Header.Get(AttemptHeader))
+	timestamp, err := strconv.ParseInt(got.Header.Get(middleware.TimestampHeader), 10, 64)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), time.Unix(timestamp, 0), 5*time.Second)
+	assert.True(t, middleware.VerifyPayload("merchant-secret-1", timestamp, body, got.Header.Get(middleware.SignatureHeader)))
+}
+
+func TestHTTPSender_Failures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/moved" {
+			http.Redirect(w, r, "/elsewhere", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	s := NewHTTPSender(time.Second)
+
+	status, err := s.Send(context.Background(), model.WebhookDelivery{URL: srv.URL, Payload: []byte(`{}`)})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+
+	status, err = s.Send(context.Background(), model.WebhookDelivery{URL: srv.URL + "/moved", Payload: []byte(`{}`)})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusFound, status)
+
+	srv.Close()
+	status, err = s.Send(context.Background(), model.WebhookDelivery{URL: srv.URL, Payload: []byte(`{}`)})
+	assert.Error(t, err)
+	assert.Equal(t, 0, status)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"avito-courier/internal/usecase"
+)
+
+type AssignmentQueueHandler struct {
+	queueUC usecase.AssignmentQueueUsecase
+}
+
+func NewAssignmentQueueHandler(queueUC usecase.AssignmentQueueUsecase) *AssignmentQueueHandler {
+	return &AssignmentQueueHandler{queueUC: queueUC}
+}
+
+func (h *AssignmentQueueHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	snapshot, err := h.queueUC.Inspect(r.Context(), limit)
+	if err != nil {
+		if err == usecase.ErrBadInput {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(snapshot)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/usecase"
+)
+
+type CapabilityHandler struct {
+	capabilityUC usecase.CapabilityUsecase
+}
+
+func NewCapabilityHandler(capabilityUC usecase.CapabilityUsecase) *CapabilityHandler {
+	return &CapabilityHandler{capabilityUC: capabilityUC}
+}
+
+func (h *CapabilityHandler) Get(w http.ResponseWriter, r *http.Request) {
+	courierID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || courierID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	caps, err := h.capabilityUC.Get(r.Context(), courierID)
+	if err != nil {
+		writeCapabilityError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(caps)
+}
+
+// Set replaces the courier's overrides; a zero field falls back to the
+// transport type limit.
+func (h *CapabilityHandler) Set(w http.ResponseWriter, r *http.Request) {
+	courierID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || courierID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req model.TransportLimits
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	caps, err := h.capabilityUC.Set(r.Context(), courierID, req)
+	if err != nil {
+		writeCapabilityError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(caps)
+}
+
+func (h *CapabilityHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	courierID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || courierID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.capabilityUC.Reset(r.Context(), courierID); err != nil {
+		writeCapabilityError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeCapabilityError(w http.ResponseWriter, err error) {
+	switch err {
+	case usecase.ErrBadInput:
+		http.Error(w, "Invalid input data", http.StatusBadRequest)
+	case usecase.ErrNotFound:
+		http.Error(w, "Courier or capability override not found", http.StatusNotFound)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -2,9 +2,15 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"avito-courier/internal/model"
 	"avito-courier/internal/usecase"
@@ -24,11 +30,12 @@ func (h *CourierHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var courier model.Courier
-	if err := json.NewDecoder(r.Body).Decode(&courier); err != nil {
+	var dto model.CourierDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
+	courier := model.FromDTO(dto)
 
 	if err := h.courierUC.Create(r.Context(), &courier); err != nil {
 		switch err {
@@ -43,8 +50,9 @@ func (h *CourierHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	setCourierETag(w, courier)
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(courier)
+	json.NewEncoder(w).Encode(model.ToDTO(courier))
 }
 
 func (h *CourierHandler) GetByID(w http.ResponseWriter, r *http.Request) {
@@ -71,8 +79,9 @@ func (h *CourierHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	setCourierETag(w, courier)
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(courier)
+	json.NewEncoder(w).Encode(model.ToDTO(courier))
 }
 
 func (h *CourierHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -88,19 +97,31 @@ func (h *CourierHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var courier model.Courier
-	if err := json.NewDecoder(r.Body).Decode(&courier); err != nil {
+	var dto model.CourierDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
+	courier := model.FromDTO(dto)
+
+	version, ok := parseIfMatch(r)
+	if !ok {
+		http.Error(w, "Invalid If-Match header", http.StatusBadRequest)
+		return
+	}
 
 	courier.ID = id
+	courier.Version = version
 	if err := h.courierUC.Update(r.Context(), &courier); err != nil {
 		switch err {
+		case usecase.ErrVersionMismatch:
+			http.Error(w, "Courier was modified, reload and retry", http.StatusPreconditionFailed)
 		case usecase.ErrNotFound:
 			http.Error(w, "Courier not found", http.StatusNotFound)
 		case usecase.ErrBadInput:
 			http.Error(w, "Invalid input data", http.StatusBadRequest)
+		case usecase.ErrConflict:
+			http.Error(w, "Courier with this phone or employee ID already exists", http.StatusConflict)
 		default:
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 		}
@@ -108,8 +129,86 @@ func (h *CourierHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	setCourierETag(w, courier)
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(courier)
+	json.NewEncoder(w).Encode(model.ToDTO(courier))
+}
+
+// Patch applies a JSON Merge Patch (RFC 7386) to the courier. If-Match with
+// the ETag from a previous read makes the update fail with 412 when someone
+// else changed the courier in the meantime.
+func (h *CourierHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		http.Error(w, "Content-Type must be application/merge-patch+json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	version, ok := parseIfMatch(r)
+	if !ok {
+		http.Error(w, "Invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	courier, err := h.courierUC.Patch(r.Context(), id, patch, version)
+	if err != nil {
+		switch err {
+		case usecase.ErrVersionMismatch:
+			http.Error(w, "Courier was modified, reload and retry", http.StatusPreconditionFailed)
+		case usecase.ErrNotFound:
+			http.Error(w, "Courier not found", http.StatusNotFound)
+		case usecase.ErrBadInput:
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+		case usecase.ErrConflict:
+			http.Error(w, "Courier with this phone or employee ID already exists", http.StatusConflict)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setCourierETag(w, courier)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(model.ToDTO(courier))
+}
+
+func setCourierETag(w http.ResponseWriter, c model.Courier) {
+	if c.Version > 0 {
+		w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(c.Version, 10)))
+	}
+}
+
+// parseIfMatch returns the courier version from If-Match, or 0 when the
+// header is absent or "*".
+func parseIfMatch(r *http.Request) (int64, bool) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, true
+	}
+
+	value = strings.TrimPrefix(value, "W/")
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
 }
 
 func (h *CourierHandler) Delete(w http.ResponseWriter, r *http.Request) {
@@ -128,42 +227,103 @@ func (h *CourierHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, "Delete not implemented", http.StatusNotImplemented)
 }
 
+// GetAll lists couriers. Supported query parameters: status and
+// transport_type (comma-separated), q (name or phone substring), created_from
+// and created_to (RFC 3339), sort (id, name, created_at or updated_at, "-"
+// prefix for descending), limit, offset and cursor. The total number of
+// matches is returned in X-Total-Count and the next page in a Link header.
 func (h *CourierHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	couriers, err := h.courierUC.GetAll(r.Context())
+	q, err := parseCourierQuery(r.URL.Query())
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.courierUC.Search(r.Context(), q)
+	if err != nil {
+		if err == usecase.ErrBadInput {
+			http.Error(w, "Invalid query parameters", http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if page.Next != nil {
+		next := r.URL.Query()
+		if q.Offset > 0 {
+			next.Set("offset", strconv.Itoa(q.Offset+len(page.Items)))
+		} else {
+			next.Set("cursor", page.Next.Encode())
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, next.Encode()))
+	}
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(couriers)
+	json.NewEncoder(w).Encode(model.ToDTOs(page.Items))
 }
 
-func (h *CourierHandler) AssignOrder(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+func parseCourierQuery(values url.Values) (model.CourierQuery, error) {
+	q := model.CourierQuery{
+		Statuses:       splitParam(values.Get("status")),
+		TransportTypes: splitParam(values.Get("transport_type")),
+		Search:         strings.TrimSpace(values.Get("q")),
 	}
 
-	var req struct {
-		OrderID string `json:"order_id"`
+	var err error
+	if v := values.Get("created_from"); v != "" {
+		if q.CreatedFrom, err = time.Parse(time.RFC3339, v); err != nil {
+			return q, errors.New("invalid created_from")
+		}
+	}
+	if v := values.Get("created_to"); v != "" {
+		if q.CreatedTo, err = time.Parse(time.RFC3339, v); err != nil {
+			return q, errors.New("invalid created_to")
+		}
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
-		return
+	if v := values.Get("sort"); v != "" {
+		q.SortBy = strings.TrimPrefix(v, "-")
+		q.Desc = strings.HasPrefix(v, "-")
+		switch q.SortBy {
+		case model.CourierSortID, model.CourierSortName, model.CourierSortCreatedAt, model.CourierSortUpdatedAt:
+		default:
+			return q, errors.New("invalid sort")
+		}
 	}
 
-	if req.OrderID == "" {
-		http.Error(w, "order_id is required", http.StatusBadRequest)
-		return
+	if v := values.Get("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil || q.Limit <= 0 {
+			return q, errors.New("invalid limit")
+		}
 	}
+	if v := values.Get("offset"); v != "" {
+		if q.Offset, err = strconv.Atoi(v); err != nil || q.Offset < 0 {
+			return q, errors.New("invalid offset")
+		}
+	}
+	if v := values.Get("cursor"); v != "" {
+		cursor, ok := model.DecodeCourierCursor(v)
+		if !ok {
+			return q, errors.New("invalid cursor")
+		}
+		q.After = &cursor
+	}
+	return q, nil
+}
 
-	http.Error(w, "Assign not implemented in courier handler", http.StatusNotImplemented)
+func splitParam(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
 }
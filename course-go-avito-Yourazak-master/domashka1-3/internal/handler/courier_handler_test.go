@@ -1,17 +1,17 @@
 package handler
 
 import (
-	"avito-courier/internal/usecase"
 	"bytes"
 	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"avito-courier/internal/model"
-	_ "avito-courier/internal/usecase"
+	"avito-courier/internal/usecase"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -31,6 +31,11 @@ func (m *MockCourierService) GetAll(ctx context.Context) ([]model.Courier, error
 	return args.Get(0).([]model.Courier), args.Error(1)
 }
 
+func (m *MockCourierService) Search(ctx context.Context, q model.CourierQuery) (model.CourierPage, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).(model.CourierPage), args.Error(1)
+}
+
 func (m *MockCourierService) Create(ctx context.Context, c *model.Courier) error {
 	args := m.Called(ctx, c)
 	return args.Error(0)
@@ -41,6 +46,28 @@ func (m *MockCourierService) Update(ctx context.Context, c *model.Courier) error
 	return args.Error(0)
 }
 
+func (m *MockCourierService) Patch(ctx context.Context, id int, patch []byte, version int64) (model.Courier, error) {
+	args := m.Called(ctx, id, patch, version)
+	return args.Get(0).(model.Courier), args.Error(1)
+}
+
+func (m *MockCourierService) Import(ctx context.Context, rows []usecase.CourierImportRow, atomic bool) (usecase.CourierImportResult, error) {
+	args := m.Called(ctx, rows, atomic)
+	return args.Get(0).(usecase.CourierImportResult), args.Error(1)
+}
+
+func (m *MockCourierService) Export(ctx context.Context, q model.CourierQuery, fn func(model.Courier) error) error {
+	args := m.Called(ctx, q, fn)
+	if couriers, ok := args.Get(0).([]model.Courier); ok {
+		for _, c := range couriers {
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
 func TestCourierHandler_GetAll_Success(t *testing.T) {
 	mockService := new(MockCourierService)
 	handler := NewCourierHandler(mockService)
@@ -50,9 +77,9 @@ func TestCourierHandler_GetAll_Success(t *testing.T) {
 		{ID: 2, Name: "Jane", Phone: "+79123456780", Status: "busy", TransportType: "bike"},
 	}
 
-	mockService.On("GetAll", mock.Anything).Return(expectedCouriers, nil)
+	mockService.On("Search", mock.Anything, model.CourierQuery{}).Return(model.CourierPage{Items: expectedCouriers, Total: 2}, nil)
 
-	req := httptest.NewRequest("GET", "/couriers", nil)
+	req := httptest.NewRequest("GET", "/api/couriers", nil)
 	rr := httptest.NewRecorder()
 
 	handler.GetAll(rr, req)
@@ -64,13 +91,27 @@ func TestCourierHandler_GetAll_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, response, 2)
 	assert.Equal(t, "John", response[0].Name)
+	assert.Equal(t, "2", rr.Header().Get("X-Total-Count"))
+}
+
+func TestCourierHandler_GetAll_InvalidSort(t *testing.T) {
+	mockService := new(MockCourierService)
+	handler := NewCourierHandler(mockService)
+
+	req := httptest.NewRequest("GET", "/api/couriers?sort=phone", nil)
+	rr := httptest.NewRecorder()
+
+	handler.GetAll(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
 }
 
 func TestCourierHandler_GetAll_MethodNotAllowed(t *testing.T) {
 	mockService := new(MockCourierService)
 	handler := NewCourierHandler(mockService)
 
-	req := httptest.NewRequest("POST", "/couriers", nil)
+	req := httptest.NewRequest("POST", "/api/couriers", nil)
 	rr := httptest.NewRecorder()
 
 	handler.GetAll(rr, req)
@@ -89,7 +130,7 @@ func TestCourierHandler_GetByID_Success(t *testing.T) {
 
 	mockService.On("GetByID", mock.Anything, 1).Return(expectedCourier, nil)
 
-	req := httptest.NewRequest("GET", "/courier/1", nil)
+	req := httptest.NewRequest("GET", "/api/couriers/1", nil)
 	rr := httptest.NewRecorder()
 
 	handler.GetByID(rr, req)
@@ -109,26 +150,26 @@ func TestCourierHandler_GetByID_NotFound(t *testing.T) {
 
 	mockService.On("GetByID", mock.Anything, 999).Return(model.Courier{}, usecase.ErrNotFound)
 
-	req := httptest.NewRequest("GET", "/courier/999", nil)
+	req := httptest.NewRequest("GET", "/api/couriers/999", nil)
 	rr := httptest.NewRecorder()
 
 	handler.GetByID(rr, req)
 
 	assert.Equal(t, http.StatusNotFound, rr.Code)
-	assert.Contains(t, rr.Body.String(), "курьер не найден")
+	assert.Contains(t, rr.Body.String(), "Courier not found")
 }
 
 func TestCourierHandler_GetByID_InvalidID(t *testing.T) {
 	mockService := new(MockCourierService)
 	handler := NewCourierHandler(mockService)
 
-	req := httptest.NewRequest("GET", "/courier/invalid", nil)
+	req := httptest.NewRequest("GET", "/api/couriers/invalid", nil)
 	rr := httptest.NewRecorder()
 
 	handler.GetByID(rr, req)
 
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
-	assert.Contains(t, rr.Body.String(), "id невалидный")
+	assert.Contains(t, rr.Body.String(), "Invalid ID")
 }
 
 func TestCourierHandler_Create_Success(t *testing.T) {
@@ -145,7 +186,7 @@ func TestCourierHandler_Create_Success(t *testing.T) {
 	mockService.On("Create", mock.Anything, mock.AnythingOfType("*model.Courier")).Return(nil)
 
 	body, _ := json.Marshal(courierDTO)
-	req := httptest.NewRequest("POST", "/couriers", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/couriers", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
 
@@ -169,21 +210,21 @@ func TestCourierHandler_Create_Conflict(t *testing.T) {
 	mockService.On("Create", mock.Anything, mock.AnythingOfType("*model.Courier")).Return(usecase.ErrConflict)
 
 	body, _ := json.Marshal(courierDTO)
-	req := httptest.NewRequest("POST", "/couriers", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/couriers", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
 
 	handler.Create(rr, req)
 
 	assert.Equal(t, http.StatusConflict, rr.Code)
-	assert.Contains(t, rr.Body.String(), "курьер с таким телефоном уже существует")
+	assert.Contains(t, rr.Body.String(), "Courier already exists")
 }
 
 func TestCourierHandler_Create_InvalidJSON(t *testing.T) {
 	mockService := new(MockCourierService)
 	handler := NewCourierHandler(mockService)
 
-	req := httptest.NewRequest("POST", "/couriers", bytes.NewReader([]byte("{invalid json")))
+	req := httptest.NewRequest("POST", "/api/couriers", bytes.NewReader([]byte("{invalid json")))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
 
@@ -203,7 +244,7 @@ func TestCourierHandler_Update_Success(t *testing.T) {
 	mockService.On("Update", mock.Anything, mock.AnythingOfType("*model.Courier")).Return(nil)
 
 	body, _ := json.Marshal(courierDTO)
-	req := httptest.NewRequest("PUT", "/couriers", bytes.NewReader(body))
+	req := httptest.NewRequest("PUT", "/api/couriers/1", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
 
@@ -212,3 +253,204 @@ func TestCourierHandler_Update_Success(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rr.Code)
 	mockService.AssertCalled(t, "Update", mock.Anything, mock.AnythingOfType("*model.Courier"))
 }
+
+func TestCourierHandler_Patch_Success(t *testing.T) {
+	mockService := new(MockCourierService)
+	handler := NewCourierHandler(mockService)
+
+	patch := []byte(`{"name":"Patched"}`)
+	mockService.On("Patch", mock.Anything, 1, patch, int64(3)).
+		Return(model.Courier{ID: 1, Name: "Patched", Version: 4}, nil)
+
+	req := httptest.NewRequest("PATCH", "/api/couriers/1", bytes.NewReader(patch))
+	req.SetPathValue("id", "1")
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"3"`)
+	rr := httptest.NewRecorder()
+
+	handler.Patch(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"4"`, rr.Header().Get("ETag"))
+	var response model.CourierDTO
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "Patched", response.Name)
+}
+
+func TestCourierHandler_Patch_VersionMismatch(t *testing.T) {
+	mockService := new(MockCourierService)
+	handler := NewCourierHandler(mockService)
+
+	mockService.On("Patch", mock.Anything, 1, mock.Anything, int64(3)).
+		Return(model.Courier{}, usecase.ErrVersionMismatch)
+
+	req := httptest.NewRequest("PATCH", "/api/couriers/1", strings.NewReader(`{"name":"Patched"}`))
+	req.SetPathValue("id", "1")
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"3"`)
+	rr := httptest.NewRecorder()
+
+	handler.Patch(rr, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+}
+
+func TestCourierHandler_Patch_BadRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          string
+		contentType string
+		ifMatch     string
+		want        int
+	}{
+		{name: "invalid id", id: "abc", contentType: "application/merge-patch+json", want: http.StatusBadRequest},
+		{name: "wrong content type", id: "1", contentType: "text/plain", want: http.StatusUnsupportedMediaType},
+		{name: "invalid if-match", id: "1", contentType: "application/merge-patch+json", ifMatch: "3", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockCourierService)
+			handler := NewCourierHandler(mockService)
+
+			req := httptest.NewRequest("PATCH", "/api/couriers/"+tt.id, strings.NewReader(`{}`))
+			req.SetPathValue("id", tt.id)
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.Patch(rr, req)
+
+			assert.Equal(t, tt.want, rr.Code)
+			mockService.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCourierHandler_Import_CSV(t *testing.T) {
+	mockService := new(MockCourierService)
+	handler := NewCourierHandler(mockService)
+
+	body := "name,phone,status\nJohn,+79123456789,available\nJane,+79123456780,busy\n"
+	mockService.On("Import", mock.Anything, mock.MatchedBy(func(rows []usecase.CourierImportRow) bool {
+		return len(rows) == 2 && rows[0].Courier.Name == "John" && rows[1].Row == 2
+	}), true).Return(usecase.CourierImportResult{Total: 2, Created: 2}, nil)
+
+	req := httptest.NewRequest("POST", "/api/couriers/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	rr := httptest.NewRecorder()
+
+	handler.Import(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCourierHandler_Import_AtomicFailure(t *testing.T) {
+	mockService := new(MockCourierService)
+	handler := NewCourierHandler(mockService)
+
+	body := `{"name":"John","phone":"+79123456789","status":"available"}` + "\n" + `{not json}` + "\n"
+	mockService.On("Import", mock.Anything, mock.MatchedBy(func(rows []usecase.CourierImportRow) bool {
+		return len(rows) == 2 && rows[1].ParseError != ""
+	}), true).Return(usecase.CourierImportResult{Total: 2, Failed: 1}, nil)
+
+	req := httptest.NewRequest("POST", "/api/couriers/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rr := httptest.NewRecorder()
+
+	handler.Import(rr, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+}
+
+func TestCourierHandler_Import_BadRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		body        string
+		want        int
+	}{
+		{name: "unknown mode", url: "/api/couriers/import?mode=all", contentType: "text/csv", body: "name,phone,status\n", want: http.StatusBadRequest},
+		{name: "unsupported type", url: "/api/couriers/import", contentType: "application/json", body: "[]", want: http.StatusUnsupportedMediaType},
+		{name: "unknown column", url: "/api/couriers/import", contentType: "text/csv", body: "name,phone,status,age\n", want: http.StatusBadRequest},
+		{name: "no rows", url: "/api/couriers/import", contentType: "text/csv", body: "name,phone,status\n", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockCourierService)
+			handler := NewCourierHandler(mockService)
+
+			req := httptest.NewRequest("POST", tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rr := httptest.NewRecorder()
+
+			handler.Import(rr, req)
+
+			assert.Equal(t, tt.want, rr.Code)
+			mockService.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCourierHandler_Export_CSV(t *testing.T) {
+	mockService := new(MockCourierService)
+	handler := NewCourierHandler(mockService)
+
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	couriers := []model.Courier{
+		{ID: 1, Name: "John", Phone: "+79123456789", Status: "available", Version: 2, CreatedAt: created, UpdatedAt: created},
+	}
+	mockService.On("Export", mock.Anything, model.CourierQuery{Statuses: []string{"available"}}, mock.Anything).Return(couriers, nil)
+
+	req := httptest.NewRequest("GET", "/api/couriers/export?status=available", nil)
+	rr := httptest.NewRecorder()
+
+	handler.Export(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "id,name,phone,status,transport_type,email,vehicle_plate,rating,external_employee_id,version,created_at,updated_at", lines[0])
+	assert.Equal(t, "1,John,+79123456789,available,,,,,,2,2025-01-02T03:04:05Z,2025-01-02T03:04:05Z", lines[1])
+}
+
+func TestCourierHandler_Export_NDJSON(t *testing.T) {
+	mockService := new(MockCourierService)
+	handler := NewCourierHandler(mockService)
+
+	couriers := []model.Courier{{ID: 1, Name: "John"}, {ID: 2, Name: "Jane"}}
+	mockService.On("Export", mock.Anything, model.CourierQuery{}, mock.Anything).Return(couriers, nil)
+
+	req := httptest.NewRequest("GET", "/api/couriers/export?format=ndjson", nil)
+	rr := httptest.NewRecorder()
+
+	handler.Export(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	assert.Len(t, lines, 2)
+	var second model.CourierDTO
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, "Jane", second.Name)
+}
+
+func TestCourierHandler_Export_Failure(t *testing.T) {
+	mockService := new(MockCourierService)
+	handler := NewCourierHandler(mockService)
+
+	mockService.On("Export", mock.Anything, model.CourierQuery{}, mock.Anything).Return(nil, usecase.ErrBadInput)
+
+	req := httptest.NewRequest("GET", "/api/couriers/export", nil)
+	rr := httptest.NewRecorder()
+
+	handler.Export(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
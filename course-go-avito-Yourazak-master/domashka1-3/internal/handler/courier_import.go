@@ -0,0 +1,310 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/usecase"
+)
+
+const maxImportBodyBytes = 10 << 20
+
+var courierImportColumns = []string{
+	"name", "phone", "status", "transport_type", "email", "vehicle_plate", "rating", "external_employee_id",
+}
+
+var courierExportColumns = append([]string{"id"}, append(courierImportColumns, "version", "created_at", "updated_at")...)
+
+// Import creates couriers from a CSV (text/csv, header row required) or NDJSON
+// (application/x-ndjson, one CourierDTO per line) body. mode=atomic (default)
+// creates all rows or none; mode=best_effort creates every valid row. Errors
+// are reported per row.
+func (h *CourierHandler) Import(w http.ResponseWriter, r *http.Request) {
+	atomic := true
+	switch r.URL.Query().Get("mode") {
+	case "", "atomic":
+	case "best_effort":
+		atomic = false
+	default:
+		http.Error(w, "mode must be atomic or best_effort", http.StatusBadRequest)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBodyBytes)
+
+	var rows []usecase.CourierImportRow
+	var err error
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		rows, err = readCourierCSV(body)
+	case "application/x-ndjson", "application/jsonl":
+		rows, err = readCourierNDJSON(body)
+	default:
+		http.Error(w, "Content-Type must be text/csv or application/x-ndjson", http.StatusUnsupportedMediaType)
+		return
+	}
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(rows) == 0 {
+		http.Error(w, "No rows to import", http.StatusBadRequest)
+		return
+	}
+	if len(rows) > usecase.MaxImportRows {
+		http.Error(w, fmt.Sprintf("At most %d rows per import", usecase.MaxImportRows), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	result, err := h.courierUC.Import(r.Context(), rows, atomic)
+	if err != nil {
+		if err == usecase.ErrBadInput {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	switch {
+	case atomic && result.Failed > 0:
+		status = http.StatusUnprocessableEntity
+	case result.Failed == 0:
+		status = http.StatusCreated
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+// Export streams the couriers as CSV (format=csv, default) or NDJSON
+// (format=ndjson). The status, transport_type, q, created_from and created_to
+// filters of GET /api/couriers apply.
+func (h *CourierHandler) Export(w http.ResponseWriter, r *http.Request) {
+	q, err := parseCourierQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Nothing is written before the first courier so that a failing query
+	// can still be reported with a proper status code.
+	var write func(model.Courier) error
+	var finish func() error
+	switch r.URL.Query().Get("format") {
+	case "", "csv":
+		cw := csv.NewWriter(w)
+		started := false
+		start := func() error {
+			if started {
+				return nil
+			}
+			started = true
+			return cw.Write(courierExportColumns)
+		}
+		write = func(c model.Courier) error {
+			if err := start(); err != nil {
+				return err
+			}
+			return cw.Write(courierCSVRecord(c))
+		}
+		finish = func() error {
+			if err := start(); err != nil {
+				return err
+			}
+			cw.Flush()
+			return cw.Error()
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="couriers.csv"`)
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		write = func(c model.Courier) error { return enc.Encode(model.ToDTO(c)) }
+		finish = func() error { return nil }
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	default:
+		http.Error(w, "format must be csv or ndjson", http.StatusBadRequest)
+		return
+	}
+
+	flusher, _ := w.(http.Flusher)
+	written := 0
+	err = h.courierUC.Export(r.Context(), q, func(c model.Courier) error {
+		if err := write(c); err != nil {
+			return err
+		}
+		written++
+		if written%100 == 0 && flusher != nil {
+			if err := finish(); err != nil {
+				return err
+			}
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if written > 0 {
+			// Headers are already sent; the truncated body is all we can signal.
+			log.Printf("Courier export aborted after %d rows: %v", written, err)
+			return
+		}
+		if err == usecase.ErrBadInput {
+			http.Error(w, "Invalid query parameters", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := finish(); err != nil {
+		log.Printf("Courier export: failed to flush: %v", err)
+	}
+}
+
+func readCourierCSV(body io.Reader) ([]usecase.CourierImportRow, error) {
+	cr := csv.NewReader(body)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !contains(courierImportColumns, name) {
+			return nil, fmt.Errorf("unknown CSV column %q", name)
+		}
+		index[name] = i
+	}
+	for _, required := range []string{"name", "phone", "status"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("CSV column %q is required", required)
+		}
+	}
+
+	var rows []usecase.CourierImportRow
+	for n := 1; ; n++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		row := usecase.CourierImportRow{Row: n}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			row.ParseError = parseErr.Err.Error()
+			rows = append(rows, row)
+			continue
+		}
+		if len(record) != len(header) {
+			row.ParseError = fmt.Sprintf("expected %d fields, got %d", len(header), len(record))
+			rows = append(rows, row)
+			continue
+		}
+
+		field := func(name string) string {
+			if i, ok := index[name]; ok {
+				return record[i]
+			}
+			return ""
+		}
+		row.Courier = model.Courier{
+			Name:               field("name"),
+			Phone:              field("phone"),
+			Status:             field("status"),
+			TransportType:      field("transport_type"),
+			Email:              field("email"),
+			VehiclePlate:       field("vehicle_plate"),
+			ExternalEmployeeID: field("external_employee_id"),
+		}
+		if v := strings.TrimSpace(field("rating")); v != "" {
+			rating, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				row.ParseError = "invalid rating"
+			}
+			row.Courier.Rating = &rating
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func readCourierNDJSON(body io.Reader) ([]usecase.CourierImportRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+
+	var rows []usecase.CourierImportRow
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		row := usecase.CourierImportRow{Row: n}
+		var dto model.CourierDTO
+		if err := json.Unmarshal([]byte(line), &dto); err != nil {
+			row.ParseError = "invalid JSON"
+		} else {
+			row.Courier = model.FromDTO(dto)
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
+
+func courierCSVRecord(c model.Courier) []string {
+	rating := ""
+	if c.Rating != nil {
+		rating = strconv.FormatFloat(*c.Rating, 'f', -1, 64)
+	}
+	return []string{
+		strconv.Itoa(c.ID),
+		c.Name,
+		c.Phone,
+		c.Status,
+		c.TransportType,
+		c.Email,
+		c.VehiclePlate,
+		rating,
+		c.ExternalEmployeeID,
+		strconv.FormatInt(c.Version, 10),
+		c.CreatedAt.UTC().Format(time.RFC3339),
+		c.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
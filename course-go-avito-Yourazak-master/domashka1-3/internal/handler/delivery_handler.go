@@ -2,7 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"avito-courier/internal/model"
 	"avito-courier/internal/usecase"
@@ -16,6 +22,9 @@ func NewDeliveryHandler(deliveryUC usecase.IDeliveryUsecase) *DeliveryHandler {
 	return &DeliveryHandler{deliveryUC: deliveryUC}
 }
 
+// Assign picks a courier for the order, or gives it to courier_id when a
+// dispatcher chose one. An order nobody can take yet is queued and answered
+// with 202.
 func (h *DeliveryHandler) Assign(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -23,7 +32,19 @@ func (h *DeliveryHandler) Assign(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		OrderID string `json:"order_id"`
+		OrderID   string          `json:"order_id"`
+		CourierID int             `json:"courier_id,omitempty"`
+		Weight    float64         `json:"weight,omitempty"`
+		Volume    float64         `json:"volume,omitempty"`
+		Pickup    *model.GeoPoint `json:"pickup,omitempty"`
+		Dropoff   *model.GeoPoint `json:"dropoff,omitempty"`
+		Priority  string          `json:"priority,omitempty"`
+		// WindowStart and WindowEnd book a delivery slot; an order whose
+		// slot starts later than the lead time is scheduled.
+		WindowStart *time.Time `json:"window_start,omitempty"`
+		WindowEnd   *time.Time `json:"window_end,omitempty"`
+		// PIN is the code the customer tells the courier at handover.
+		PIN string `json:"pin,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -35,17 +56,72 @@ func (h *DeliveryHandler) Assign(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "order_id is required", http.StatusBadRequest)
 		return
 	}
+	if req.Pickup != nil && !req.Pickup.Valid() {
+		http.Error(w, "pickup coordinates are out of range", http.StatusBadRequest)
+		return
+	}
+	if req.Dropoff != nil && !req.Dropoff.Valid() {
+		http.Error(w, "dropoff coordinates are out of range", http.StatusBadRequest)
+		return
+	}
+	if req.CourierID < 0 {
+		http.Error(w, "courier_id must be positive", http.StatusBadRequest)
+		return
+	}
+	if req.Priority != "" && model.NormalizePriority(req.Priority) != req.Priority {
+		http.Error(w, "priority must be express, standard or scheduled", http.StatusBadRequest)
+		return
+	}
+	if req.Weight < 0 || req.Volume < 0 {
+		http.Error(w, "weight and volume must not be negative", http.StatusBadRequest)
+		return
+	}
 
-	delivery, courier, err := h.deliveryUC.Assign(r.Context(), req.OrderID)
+	order := model.ExternalOrder{
+		ID:          req.OrderID,
+		Weight:      req.Weight,
+		Volume:      req.Volume,
+		Pickup:      req.Pickup,
+		Dropoff:     req.Dropoff,
+		Priority:    req.Priority,
+		WindowStart: req.WindowStart,
+		WindowEnd:   req.WindowEnd,
+		PINHash:     model.HashPIN(req.OrderID, req.PIN),
+	}
+	if !order.ValidWindow() {
+		http.Error(w, "window_start and window_end must both be set, start before end", http.StatusBadRequest)
+		return
+	}
+	var (
+		delivery model.Delivery
+		courier  model.Courier
+		err      error
+	)
+	if req.CourierID > 0 {
+		delivery, courier, err = h.deliveryUC.AssignTo(r.Context(), order, req.CourierID)
+	} else {
+		delivery, courier, err = h.deliveryUC.Assign(r.Context(), order)
+	}
+	if err == usecase.ErrAssignmentQueued {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{"status": "queued", "order_id": req.OrderID})
+		return
+	}
+	if err == usecase.ErrOfferPending {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{"status": "offered", "order_id": req.OrderID})
+		return
+	}
+	if err == usecase.ErrDeliveryScheduled {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{"status": "scheduled", "order_id": req.OrderID})
+		return
+	}
 	if err != nil {
-		switch err {
-		case usecase.ErrNoAvailableCourier:
-			http.Error(w, "No available couriers", http.StatusConflict)
-		case usecase.ErrOrderAlreadyAssigned:
-			http.Error(w, "Order already assigned", http.StatusConflict)
-		default:
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+		writeAssignError(w, err)
 		return
 	}
 
@@ -62,6 +138,291 @@ func (h *DeliveryHandler) Assign(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// Reassign moves the delivery with the given ID to courier_id, or to the best
+// other courier when courier_id is omitted.
+func (h *DeliveryHandler) Reassign(w http.ResponseWriter, r *http.Request) {
+	deliveryID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || deliveryID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		CourierID int    `json:"courier_id,omitempty"`
+		Reason    string `json:"reason,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.CourierID < 0 {
+		http.Error(w, "courier_id must be positive", http.StatusBadRequest)
+		return
+	}
+
+	delivery, courier, err := h.deliveryUC.Reassign(r.Context(), deliveryID, req.CourierID, req.Reason)
+	if err != nil {
+		writeAssignError(w, err)
+		return
+	}
+
+	response := struct {
+		Delivery model.Delivery `json:"delivery"`
+		Courier  model.Courier  `json:"courier"`
+	}{
+		Delivery: delivery,
+		Courier:  courier,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// maxProofUpload limits the whole completion request, uploads included.
+const maxProofUpload = 10 << 20
+
+// Complete finishes the delivery from the courier app. It takes either JSON
+// with pin and location, or a multipart form with pin, lat, lon and the
+// signature and photo images.
+func (h *DeliveryHandler) Complete(w http.ResponseWriter, r *http.Request) {
+	completeDelivery(w, r, h.deliveryUC, 0)
+}
+
+// completeDelivery serves Complete, limited to the courier's own deliveries
+// when courierID is set.
+func completeDelivery(w http.ResponseWriter, r *http.Request, deliveryUC usecase.IDeliveryUsecase, courierID int) {
+	deliveryID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || deliveryID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxProofUpload)
+
+	req := usecase.CompletionRequest{CourierID: courierID}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(maxProofUpload); err != nil {
+			http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		req.PIN = r.FormValue("pin")
+		if lat, lon := r.FormValue("lat"), r.FormValue("lon"); lat != "" || lon != "" {
+			p, ok := parsePoint(lat, lon)
+			if !ok {
+				http.Error(w, "lat and lon must be valid coordinates", http.StatusBadRequest)
+				return
+			}
+			req.Location = &p
+		}
+		if req.Signature, err = formUpload(r, "signature"); err != nil {
+			http.Error(w, "signature must be a PNG, JPEG or WebP image", http.StatusBadRequest)
+			return
+		}
+		if req.Photo, err = formUpload(r, "photo"); err != nil {
+			http.Error(w, "photo must be a PNG, JPEG or WebP image", http.StatusBadRequest)
+			return
+		}
+	} else {
+		var body struct {
+			PIN      string          `json:"pin,omitempty"`
+			Location *model.GeoPoint `json:"location,omitempty"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if body.Location != nil && !body.Location.Valid() {
+			http.Error(w, "location coordinates are out of range", http.StatusBadRequest)
+			return
+		}
+		req.PIN, req.Location = body.PIN, body.Location
+	}
+
+	delivery, err := deliveryUC.Complete(r.Context(), deliveryID, req)
+	if err != nil {
+		writeAssignError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// Fail records that the order could not be handed over. The order is
+// either queued for another attempt or returned to sender, as reported in
+// the attempt's outcome.
+func (h *DeliveryHandler) Fail(w http.ResponseWriter, r *http.Request) {
+	failDelivery(w, r, h.deliveryUC, 0)
+}
+
+// failDelivery serves Fail, limited to the courier's own deliveries when
+// courierID is set.
+func failDelivery(w http.ResponseWriter, r *http.Request, deliveryUC usecase.IDeliveryUsecase, courierID int) {
+	deliveryID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || deliveryID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Reason   string          `json:"reason"`
+		Comment  string          `json:"comment,omitempty"`
+		Location *model.GeoPoint `json:"location,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !model.ValidFailureReason(req.Reason) {
+		http.Error(w, "reason must be customer_absent, wrong_address, no_access, refused, damaged or other", http.StatusBadRequest)
+		return
+	}
+	if req.Location != nil && !req.Location.Valid() {
+		http.Error(w, "location coordinates are out of range", http.StatusBadRequest)
+		return
+	}
+
+	attempt, ret, err := deliveryUC.Fail(r.Context(), deliveryID, usecase.FailureRequest{
+		CourierID: courierID,
+		Reason:    req.Reason,
+		Comment:   req.Comment,
+		Location:  req.Location,
+	})
+	if err != nil {
+		writeAssignError(w, err)
+		return
+	}
+
+	response := struct {
+		Attempt model.DeliveryAttempt `json:"attempt"`
+		Return  *model.Delivery       `json:"return_delivery,omitempty"`
+	}{
+		Attempt: attempt,
+		Return:  ret,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func parsePoint(lat, lon string) (model.GeoPoint, bool) {
+	la, err1 := strconv.ParseFloat(lat, 64)
+	lo, err2 := strconv.ParseFloat(lon, 64)
+	p := model.GeoPoint{Lat: la, Lon: lo}
+	return p, err1 == nil && err2 == nil && p.Valid()
+}
+
+// formUpload returns the named file of the form, or nil when it was not
+// sent. The content type is sniffed from the data rather than trusted from
+// the client.
+func formUpload(r *http.Request, name string) (*usecase.ProofUpload, error) {
+	file, _, err := r.FormFile(name)
+	if err == http.ErrMissingFile {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	contentType := http.DetectContentType(head[:n])
+	switch contentType {
+	case "image/png", "image/jpeg", "image/webp":
+		return &usecase.ProofUpload{Body: file, ContentType: contentType}, nil
+	}
+	return nil, errors.New("unsupported image type")
+}
+
+// ListScheduled returns the courier's upcoming scheduled deliveries.
+func (h *DeliveryHandler) ListScheduled(w http.ResponseWriter, r *http.Request) {
+	courierID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || courierID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	items, err := h.deliveryUC.ListScheduled(r.Context(), courierID)
+	if err != nil {
+		switch err {
+		case usecase.ErrBadInput:
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+		case usecase.ErrNotFound:
+			http.Error(w, "Courier not found", http.StatusNotFound)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(items)
+}
+
+// OfferStats returns how the courier answered delivery offers.
+func (h *DeliveryHandler) OfferStats(w http.ResponseWriter, r *http.Request) {
+	courierID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || courierID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.deliveryUC.OfferStats(r.Context(), courierID)
+	if err != nil {
+		writeAssignError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(stats)
+}
+
+func writeAssignError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrBadInput):
+		http.Error(w, "Invalid input data", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrNotFound):
+		http.Error(w, "Courier not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrDeliveryNotFound):
+		http.Error(w, "Delivery not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrNoAvailableCourier):
+		http.Error(w, "No available couriers", http.StatusConflict)
+	case errors.Is(err, usecase.ErrNoCapableCourier):
+		http.Error(w, "No capable courier for this order", http.StatusConflict)
+	case errors.Is(err, usecase.ErrOrderAlreadyAssigned):
+		http.Error(w, "Order already assigned", http.StatusConflict)
+	case errors.Is(err, usecase.ErrCourierUnavailable):
+		http.Error(w, "Courier is not available", http.StatusConflict)
+	case errors.Is(err, usecase.ErrDeliveryNotActive):
+		http.Error(w, "Delivery is not active", http.StatusConflict)
+	case errors.Is(err, usecase.ErrDeliveryInRoute):
+		http.Error(w, "Delivery is part of a route", http.StatusConflict)
+	case errors.Is(err, usecase.ErrInvalidPIN):
+		http.Error(w, "Invalid PIN", http.StatusForbidden)
+	case errors.Is(err, usecase.ErrOfferNotPending):
+		http.Error(w, "Offer is no longer pending", http.StatusConflict)
+	case errors.Is(err, usecase.ErrReturnNotFailable):
+		http.Error(w, "Return delivery cannot fail", http.StatusConflict)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
 func (h *DeliveryHandler) Unassign(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -83,7 +444,7 @@ func (h *DeliveryHandler) Unassign(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.deliveryUC.Unassign(r.Context(), req.OrderID); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeAssignError(w, err)
 		return
 	}
 
@@ -92,12 +453,55 @@ func (h *DeliveryHandler) Unassign(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "unassigned"})
 }
 
+// GetDelivery returns the delivery with its ETA and, once completed, its
+// proof of delivery.
 func (h *DeliveryHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusNotImplemented)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Not implemented"})
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	delivery, err := h.deliveryUC.GetByID(r.Context(), id)
+	if err != nil {
+		writeAssignError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(delivery)
 }
 
+// ListDeliveries returns deliveries newest first, filtered by courier_id,
+// order_id and a comma separated status list, and paged with limit and
+// offset.
 func (h *DeliveryHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusNotImplemented)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Not implemented"})
+	values := r.URL.Query()
+	q := model.DeliveryQuery{
+		OrderID:  strings.TrimSpace(values.Get("order_id")),
+		Statuses: splitParam(values.Get("status")),
+	}
+	for name, dest := range map[string]*int{"courier_id": &q.CourierID, "limit": &q.Limit, "offset": &q.Offset} {
+		v := values.Get(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid "+name, http.StatusBadRequest)
+			return
+		}
+		*dest = n
+	}
+
+	items, err := h.deliveryUC.List(r.Context(), q)
+	if err != nil {
+		writeAssignError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(items)
 }
@@ -1,16 +1,19 @@
 package handler
 
 import (
-	"avito-courier/internal/repository"
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
 	"avito-courier/internal/usecase"
 
 	"github.com/stretchr/testify/assert"
@@ -21,18 +24,119 @@ type MockDeliveryUsecase struct {
 	mock.Mock
 }
 
-func (m *MockDeliveryUsecase) Assign(ctx context.Context, orderID string) (model.Delivery, model.Courier, error) {
-	args := m.Called(ctx, orderID)
+func (m *MockDeliveryUsecase) Assign(ctx context.Context, order model.ExternalOrder) (model.Delivery, model.Courier, error) {
+	args := m.Called(ctx, order)
+	return args.Get(0).(model.Delivery), args.Get(1).(model.Courier), args.Error(2)
+}
+
+func (m *MockDeliveryUsecase) AssignTo(ctx context.Context, order model.ExternalOrder, courierID int) (model.Delivery, model.Courier, error) {
+	args := m.Called(ctx, order, courierID)
+	return args.Get(0).(model.Delivery), args.Get(1).(model.Courier), args.Error(2)
+}
+
+func (m *MockDeliveryUsecase) Reassign(ctx context.Context, deliveryID, courierID int, reason string) (model.Delivery, model.Courier, error) {
+	args := m.Called(ctx, deliveryID, courierID, reason)
 	return args.Get(0).(model.Delivery), args.Get(1).(model.Courier), args.Error(2)
 }
 
-func (m *MockDeliveryUsecase) Unassign(ctx context.Context, orderID string) (int, error) {
+func (m *MockDeliveryUsecase) Unassign(ctx context.Context, orderID string) error {
+	args := m.Called(ctx, orderID)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryUsecase) AssignForEvent(ctx context.Context, order model.ExternalOrder) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryUsecase) UnassignForEvent(ctx context.Context, orderID string) error {
 	args := m.Called(ctx, orderID)
-	return args.Int(0), args.Error(1)
+	return args.Error(0)
 }
 
-func (m *MockDeliveryUsecase) AutoRelease(ctx context.Context, interval time.Duration) {
-	m.Called(ctx, interval)
+func (m *MockDeliveryUsecase) CompleteForEvent(ctx context.Context, orderID string) error {
+	args := m.Called(ctx, orderID)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryUsecase) Complete(ctx context.Context, deliveryID int, req usecase.CompletionRequest) (model.Delivery, error) {
+	args := m.Called(ctx, deliveryID, req)
+	return args.Get(0).(model.Delivery), args.Error(1)
+}
+
+func (m *MockDeliveryUsecase) Fail(ctx context.Context, deliveryID int, req usecase.FailureRequest) (model.DeliveryAttempt, *model.Delivery, error) {
+	args := m.Called(ctx, deliveryID, req)
+	ret, _ := args.Get(1).(*model.Delivery)
+	return args.Get(0).(model.DeliveryAttempt), ret, args.Error(2)
+}
+
+func (m *MockDeliveryUsecase) PickUp(ctx context.Context, courierID, deliveryID int) (model.Delivery, error) {
+	args := m.Called(ctx, courierID, deliveryID)
+	return args.Get(0).(model.Delivery), args.Error(1)
+}
+
+func (m *MockDeliveryUsecase) ListActive(ctx context.Context, courierID int) ([]model.Delivery, error) {
+	args := m.Called(ctx, courierID)
+	return args.Get(0).([]model.Delivery), args.Error(1)
+}
+
+func (m *MockDeliveryUsecase) AcceptOffer(ctx context.Context, courierID int, offerID int64) (model.Delivery, error) {
+	args := m.Called(ctx, courierID, offerID)
+	return args.Get(0).(model.Delivery), args.Error(1)
+}
+
+func (m *MockDeliveryUsecase) DeclineOffer(ctx context.Context, courierID int, offerID int64) error {
+	args := m.Called(ctx, courierID, offerID)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryUsecase) ListOffers(ctx context.Context, courierID int) ([]model.DeliveryOffer, error) {
+	args := m.Called(ctx, courierID)
+	return args.Get(0).([]model.DeliveryOffer), args.Error(1)
+}
+
+func (m *MockDeliveryUsecase) OfferStats(ctx context.Context, courierID int) (model.OfferStats, error) {
+	args := m.Called(ctx, courierID)
+	return args.Get(0).(model.OfferStats), args.Error(1)
+}
+
+func (m *MockDeliveryUsecase) GetByID(ctx context.Context, id int) (model.Delivery, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(model.Delivery), args.Error(1)
+}
+
+func (m *MockDeliveryUsecase) List(ctx context.Context, q model.DeliveryQuery) ([]model.Delivery, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).([]model.Delivery), args.Error(1)
+}
+
+func (m *MockDeliveryUsecase) GetByOrderID(ctx context.Context, orderID string) (model.Delivery, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(model.Delivery), args.Error(1)
+}
+
+func (m *MockDeliveryUsecase) Create(ctx context.Context, d *model.Delivery) error {
+	args := m.Called(ctx, d)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryUsecase) DeleteByOrderID(ctx context.Context, orderID string) error {
+	args := m.Called(ctx, orderID)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryUsecase) ListScheduled(ctx context.Context, courierID int) ([]model.ScheduledDelivery, error) {
+	args := m.Called(ctx, courierID)
+	return args.Get(0).([]model.ScheduledDelivery), args.Error(1)
+}
+
+type assignResp struct {
+	Delivery model.Delivery `json:"delivery"`
+	Courier  model.Courier  `json:"courier"`
+}
+
+func orderWithID(orderID string) interface{} {
+	return mock.MatchedBy(func(o model.ExternalOrder) bool { return o.ID == orderID })
 }
 
 func TestDeliveryHandler_Assign_Success(t *testing.T) {
@@ -55,12 +159,11 @@ func TestDeliveryHandler_Assign_Success(t *testing.T) {
 		TransportType: "car",
 	}
 
-	mockUsecase.On("Assign", mock.Anything, "order-123").Return(expectedDelivery, expectedCourier, nil)
+	mockUsecase.On("Assign", mock.Anything, orderWithID("order-123")).Return(expectedDelivery, expectedCourier, nil)
 
-	reqBody := assignReq{OrderID: "order-123"}
-	body, _ := json.Marshal(reqBody)
+	body := []byte(`{"order_id":"order-123"}`)
 
-	req := httptest.NewRequest("POST", "/deliveries/assign", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/delivery/assign", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
 
@@ -71,56 +174,115 @@ func TestDeliveryHandler_Assign_Success(t *testing.T) {
 	var response assignResp
 	err := json.Unmarshal(rr.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, 1, response.CourierID)
-	assert.Equal(t, "order-123", response.OrderID)
-	assert.Equal(t, "car", response.TransportType)
+	assert.Equal(t, 1, response.Courier.ID)
+	assert.Equal(t, "order-123", response.Delivery.OrderID)
+	assert.Equal(t, "car", response.Courier.TransportType)
 }
 
-func TestDeliveryHandler_Assign_NoAvailableCourier(t *testing.T) {
+func TestDeliveryHandler_Assign_ToCourier(t *testing.T) {
 	mockUsecase := new(MockDeliveryUsecase)
 	handler := NewDeliveryHandler(mockUsecase)
 
-	mockUsecase.On("Assign", mock.Anything, "order-123").Return(model.Delivery{}, model.Courier{}, usecase.ErrNoAvailableCourier)
-
-	reqBody := assignReq{OrderID: "order-123"}
-	body, _ := json.Marshal(reqBody)
+	mockUsecase.On("AssignTo", mock.Anything, orderWithID("order-123"), 7).
+		Return(model.Delivery{OrderID: "order-123", CourierID: 7}, model.Courier{ID: 7}, nil)
 
-	req := httptest.NewRequest("POST", "/deliveries/assign", bytes.NewReader(body))
-	req.Header.Set("Content-Type", "application/json")
+	body := []byte(`{"order_id":"order-123","courier_id":7}`)
+	req := httptest.NewRequest("POST", "/api/delivery/assign", bytes.NewReader(body))
 	rr := httptest.NewRecorder()
 
 	handler.Assign(rr, req)
 
-	assert.Equal(t, http.StatusConflict, rr.Code)
-	assert.Contains(t, rr.Body.String(), "no available couriers")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockUsecase.AssertNotCalled(t, "Assign", mock.Anything, mock.Anything)
 }
 
-func TestDeliveryHandler_Assign_BadRequest(t *testing.T) {
+func TestDeliveryHandler_Assign_Accepted(t *testing.T) {
+	tests := []struct {
+		err    error
+		status string
+	}{
+		{err: usecase.ErrAssignmentQueued, status: "queued"},
+		{err: usecase.ErrOfferPending, status: "offered"},
+		{err: usecase.ErrDeliveryScheduled, status: "scheduled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			mockUsecase := new(MockDeliveryUsecase)
+			handler := NewDeliveryHandler(mockUsecase)
+
+			mockUsecase.On("Assign", mock.Anything, orderWithID("order-123")).Return(model.Delivery{}, model.Courier{}, tt.err)
+
+			req := httptest.NewRequest("POST", "/api/delivery/assign", strings.NewReader(`{"order_id":"order-123"}`))
+			rr := httptest.NewRecorder()
+
+			handler.Assign(rr, req)
+
+			assert.Equal(t, http.StatusAccepted, rr.Code)
+			var response map[string]string
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, tt.status, response["status"])
+		})
+	}
+}
+
+func TestDeliveryHandler_Assign_NoAvailableCourier(t *testing.T) {
 	mockUsecase := new(MockDeliveryUsecase)
 	handler := NewDeliveryHandler(mockUsecase)
 
-	reqBody := assignReq{OrderID: ""}
-	body, _ := json.Marshal(reqBody)
+	mockUsecase.On("Assign", mock.Anything, orderWithID("order-123")).Return(model.Delivery{}, model.Courier{}, usecase.ErrNoAvailableCourier)
+
+	body := []byte(`{"order_id":"order-123"}`)
 
-	req := httptest.NewRequest("POST", "/deliveries/assign", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/delivery/assign", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
 
 	handler.Assign(rr, req)
 
-	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "No available couriers")
+}
+
+func TestDeliveryHandler_Assign_BadRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "missing order", body: `{"order_id":""}`},
+		{name: "pickup out of range", body: `{"order_id":"o","pickup":{"lat":91,"lon":0}}`},
+		{name: "negative courier", body: `{"order_id":"o","courier_id":-1}`},
+		{name: "unknown priority", body: `{"order_id":"o","priority":"urgent"}`},
+		{name: "negative weight", body: `{"order_id":"o","weight":-1}`},
+		{name: "half window", body: `{"order_id":"o","window_start":"2025-01-01T10:00:00Z"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(MockDeliveryUsecase)
+			handler := NewDeliveryHandler(mockUsecase)
+
+			req := httptest.NewRequest("POST", "/api/delivery/assign", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			handler.Assign(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			mockUsecase.AssertNotCalled(t, "Assign", mock.Anything, mock.Anything)
+		})
+	}
 }
 
 func TestDeliveryHandler_Unassign_Success(t *testing.T) {
 	mockUsecase := new(MockDeliveryUsecase)
 	handler := NewDeliveryHandler(mockUsecase)
 
-	mockUsecase.On("Unassign", mock.Anything, "order-123").Return(1, nil)
+	mockUsecase.On("Unassign", mock.Anything, "order-123").Return(nil)
 
-	reqBody := unassignReq{OrderID: "order-123"}
-	body, _ := json.Marshal(reqBody)
+	body := []byte(`{"order_id":"order-123"}`)
 
-	req := httptest.NewRequest("POST", "/deliveries/unassign", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/delivery/unassign", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
 
@@ -128,24 +290,21 @@ func TestDeliveryHandler_Unassign_Success(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rr.Code)
 
-	var response unassignResp
+	var response map[string]string
 	err := json.Unmarshal(rr.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "order-123", response.OrderID)
-	assert.Equal(t, "unassigned", response.Status)
-	assert.Equal(t, 1, response.CourierID)
+	assert.Equal(t, "unassigned", response["status"])
 }
 
 func TestDeliveryHandler_Unassign_NotFound(t *testing.T) {
 	mockUsecase := new(MockDeliveryUsecase)
 	handler := NewDeliveryHandler(mockUsecase)
 
-	mockUsecase.On("Unassign", mock.Anything, "order-999").Return(0, repository.ErrDeliveryNotFound)
+	mockUsecase.On("Unassign", mock.Anything, "order-999").Return(repository.ErrDeliveryNotFound)
 
-	reqBody := unassignReq{OrderID: "order-999"}
-	body, _ := json.Marshal(reqBody)
+	body := []byte(`{"order_id":"order-999"}`)
 
-	req := httptest.NewRequest("POST", "/deliveries/unassign", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/delivery/unassign", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
 
@@ -153,3 +312,300 @@ func TestDeliveryHandler_Unassign_NotFound(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, rr.Code)
 }
+
+func TestDeliveryHandler_Unassign_WrappedNotFound(t *testing.T) {
+	mockUsecase := new(MockDeliveryUsecase)
+	handler := NewDeliveryHandler(mockUsecase)
+
+	mockUsecase.On("Unassign", mock.Anything, "order-999").
+		Return(fmt.Errorf("unassign order-999: %w", usecase.ErrDeliveryNotFound))
+
+	req := httptest.NewRequest("POST", "/api/delivery/unassign", strings.NewReader(`{"order_id":"order-999"}`))
+	rr := httptest.NewRecorder()
+
+	handler.Unassign(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestDeliveryHandler_Unassign_NotActive(t *testing.T) {
+	mockUsecase := new(MockDeliveryUsecase)
+	handler := NewDeliveryHandler(mockUsecase)
+
+	mockUsecase.On("Unassign", mock.Anything, "order-123").Return(usecase.ErrDeliveryNotActive)
+
+	req := httptest.NewRequest("POST", "/api/delivery/unassign", strings.NewReader(`{"order_id":"order-123"}`))
+	rr := httptest.NewRecorder()
+
+	handler.Unassign(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+}
+
+func TestDeliveryHandler_Reassign_Success(t *testing.T) {
+	mockUsecase := new(MockDeliveryUsecase)
+	handler := NewDeliveryHandler(mockUsecase)
+
+	mockUsecase.On("Reassign", mock.Anything, 5, 8, "courier sick").
+		Return(model.Delivery{ID: 5, CourierID: 8}, model.Courier{ID: 8}, nil)
+
+	req := httptest.NewRequest("POST", "/api/delivery/5/reassign", strings.NewReader(`{"courier_id":8,"reason":"courier sick"}`))
+	req.SetPathValue("id", "5")
+	rr := httptest.NewRecorder()
+
+	handler.Reassign(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response assignResp
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, 8, response.Delivery.CourierID)
+}
+
+func TestDeliveryHandler_Reassign_Errors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{err: usecase.ErrDeliveryNotFound, want: http.StatusNotFound},
+		{err: usecase.ErrDeliveryNotActive, want: http.StatusConflict},
+		{err: usecase.ErrCourierUnavailable, want: http.StatusConflict},
+		{err: usecase.ErrDeliveryInRoute, want: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			mockUsecase := new(MockDeliveryUsecase)
+			handler := NewDeliveryHandler(mockUsecase)
+
+			mockUsecase.On("Reassign", mock.Anything, 5, 0, "").Return(model.Delivery{}, model.Courier{}, tt.err)
+
+			req := httptest.NewRequest("POST", "/api/delivery/5/reassign", strings.NewReader(`{}`))
+			req.SetPathValue("id", "5")
+			rr := httptest.NewRecorder()
+
+			handler.Reassign(rr, req)
+
+			assert.Equal(t, tt.want, rr.Code)
+		})
+	}
+}
+
+func TestDeliveryHandler_Complete_JSON(t *testing.T) {
+	mockUsecase := new(MockDeliveryUsecase)
+	handler := NewDeliveryHandler(mockUsecase)
+
+	mockUsecase.On("Complete", mock.Anything, 5, mock.MatchedBy(func(req usecase.CompletionRequest) bool {
+		return req.CourierID == 0 && req.PIN == "1234" && req.Location != nil && req.Location.Lat == 55.7
+	})).Return(model.Delivery{ID: 5, Status: "completed"}, nil)
+
+	body := `{"pin":"1234","location":{"lat":55.7,"lon":37.6}}`
+	req := httptest.NewRequest("POST", "/api/delivery/5/complete", strings.NewReader(body))
+	req.SetPathValue("id", "5")
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	handler.Complete(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response model.Delivery
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "completed", response.Status)
+}
+
+func TestDeliveryHandler_Complete_Multipart(t *testing.T) {
+	mockUsecase := new(MockDeliveryUsecase)
+	handler := NewDeliveryHandler(mockUsecase)
+
+	mockUsecase.On("Complete", mock.Anything, 5, mock.MatchedBy(func(req usecase.CompletionRequest) bool {
+		return req.PIN == "1234" && req.Signature != nil && req.Signature.ContentType == "image/png" && req.Photo == nil
+	})).Return(model.Delivery{ID: 5, Status: "completed"}, nil)
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mw.WriteField("pin", "1234")
+	part, _ := mw.CreateFormFile("signature", "signature.png")
+	part.Write([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/api/delivery/5/complete", &buf)
+	req.SetPathValue("id", "5")
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rr := httptest.NewRecorder()
+
+	handler.Complete(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestDeliveryHandler_Complete_RejectsNonImage(t *testing.T) {
+	mockUsecase := new(MockDeliveryUsecase)
+	handler := NewDeliveryHandler(mockUsecase)
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	part, _ := mw.CreateFormFile("photo", "photo.png")
+	part.Write([]byte("not an image"))
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/api/delivery/5/complete", &buf)
+	req.SetPathValue("id", "5")
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rr := httptest.NewRecorder()
+
+	handler.Complete(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockUsecase.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeliveryHandler_Complete_InvalidPIN(t *testing.T) {
+	mockUsecase := new(MockDeliveryUsecase)
+	handler := NewDeliveryHandler(mockUsecase)
+
+	mockUsecase.On("Complete", mock.Anything, 5, mock.Anything).Return(model.Delivery{}, usecase.ErrInvalidPIN)
+
+	req := httptest.NewRequest("POST", "/api/delivery/5/complete", strings.NewReader(`{"pin":"0000"}`))
+	req.SetPathValue("id", "5")
+	rr := httptest.NewRecorder()
+
+	handler.Complete(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestDeliveryHandler_Fail_Success(t *testing.T) {
+	mockUsecase := new(MockDeliveryUsecase)
+	handler := NewDeliveryHandler(mockUsecase)
+
+	ret := &model.Delivery{ID: 9, Kind: model.DeliveryKindReturn}
+	mockUsecase.On("Fail", mock.Anything, 5, usecase.FailureRequest{Reason: model.FailureRefused, Comment: "no"}).
+		Return(model.DeliveryAttempt{DeliveryID: 5, Outcome: model.AttemptReturn}, ret, nil)
+
+	req := httptest.NewRequest("POST", "/api/delivery/5/fail", strings.NewReader(`{"reason":"refused","comment":"no"}`))
+	req.SetPathValue("id", "5")
+	rr := httptest.NewRecorder()
+
+	handler.Fail(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response struct {
+		Attempt model.DeliveryAttempt `json:"attempt"`
+		Return  *model.Delivery       `json:"return_delivery"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, model.AttemptReturn, response.Attempt.Outcome)
+	if assert.NotNil(t, response.Return) {
+		assert.Equal(t, 9, response.Return.ID)
+	}
+}
+
+func TestDeliveryHandler_Fail_BadRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "unknown reason", body: `{"reason":"lazy"}`},
+		{name: "location out of range", body: `{"reason":"other","location":{"lat":0,"lon":181}}`},
+		{name: "invalid json", body: `{`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(MockDeliveryUsecase)
+			handler := NewDeliveryHandler(mockUsecase)
+
+			req := httptest.NewRequest("POST", "/api/delivery/5/fail", strings.NewReader(tt.body))
+			req.SetPathValue("id", "5")
+			rr := httptest.NewRecorder()
+
+			handler.Fail(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			mockUsecase.AssertNotCalled(t, "Fail", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestDeliveryHandler_Fail_ReturnNotFailable(t *testing.T) {
+	mockUsecase := new(MockDeliveryUsecase)
+	handler := NewDeliveryHandler(mockUsecase)
+
+	mockUsecase.On("Fail", mock.Anything, 5, mock.Anything).Return(model.DeliveryAttempt{}, nil, usecase.ErrReturnNotFailable)
+
+	req := httptest.NewRequest("POST", "/api/delivery/5/fail", strings.NewReader(`{"reason":"other"}`))
+	req.SetPathValue("id", "5")
+	rr := httptest.NewRecorder()
+
+	handler.Fail(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+}
+
+func TestDeliveryHandler_GetDelivery(t *testing.T) {
+	mockUsecase := new(MockDeliveryUsecase)
+	handler := NewDeliveryHandler(mockUsecase)
+
+	eta := time.Date(2025, 12, 1, 12, 30, 0, 0, time.UTC)
+	mockUsecase.On("GetByID", mock.Anything, 10).Return(model.Delivery{
+		ID: 10, OrderID: "order-1", Status: "completed", ETA: &eta,
+		Proof: &model.DeliveryProof{CompletedAt: eta, PINVerified: true, PhotoKey: "proofs/10.jpg"},
+	}, nil)
+	mockUsecase.On("GetByID", mock.Anything, 11).Return(model.Delivery{}, usecase.ErrDeliveryNotFound)
+
+	req := httptest.NewRequest("GET", "/api/delivery/10", nil)
+	req.SetPathValue("id", "10")
+	rr := httptest.NewRecorder()
+	handler.GetDelivery(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response model.Delivery
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	if assert.NotNil(t, response.ETA) && assert.NotNil(t, response.Proof) {
+		assert.True(t, eta.Equal(*response.ETA))
+		assert.True(t, response.Proof.PINVerified)
+		assert.Equal(t, "proofs/10.jpg", response.Proof.PhotoKey)
+	}
+
+	req = httptest.NewRequest("GET", "/api/delivery/11", nil)
+	req.SetPathValue("id", "11")
+	rr = httptest.NewRecorder()
+	handler.GetDelivery(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	req = httptest.NewRequest("GET", "/api/delivery/abc", nil)
+	req.SetPathValue("id", "abc")
+	rr = httptest.NewRecorder()
+	handler.GetDelivery(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestDeliveryHandler_ListDeliveries(t *testing.T) {
+	mockUsecase := new(MockDeliveryUsecase)
+	handler := NewDeliveryHandler(mockUsecase)
+
+	mockUsecase.On("List", mock.Anything, model.DeliveryQuery{CourierID: 7, Statuses: []string{"assigned", "picked_up"}, Limit: 10, Offset: 20}).
+		Return([]model.Delivery{{ID: 3, CourierID: 7, Status: "assigned"}}, nil)
+	mockUsecase.On("List", mock.Anything, model.DeliveryQuery{Statuses: []string{"cancelled"}}).
+		Return([]model.Delivery(nil), usecase.ErrBadInput)
+
+	req := httptest.NewRequest("GET", "/api/deliveries?courier_id=7&status=assigned,picked_up&limit=10&offset=20", nil)
+	rr := httptest.NewRecorder()
+	handler.ListDeliveries(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response []model.Delivery
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Len(t, response, 1)
+
+	req = httptest.NewRequest("GET", "/api/deliveries?status=cancelled", nil)
+	rr = httptest.NewRecorder()
+	handler.ListDeliveries(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	req = httptest.NewRequest("GET", "/api/deliveries?limit=ten", nil)
+	rr = httptest.NewRecorder()
+	handler.ListDeliveries(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockUsecase.AssertNumberOfCalls(t, "List", 2)
+}
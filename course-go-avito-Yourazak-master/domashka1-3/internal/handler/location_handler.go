@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/usecase"
+)
+
+type LocationHandler struct {
+	locationUC usecase.LocationUsecase
+}
+
+func NewLocationHandler(locationUC usecase.LocationUsecase) *LocationHandler {
+	return &LocationHandler{locationUC: locationUC}
+}
+
+func (h *LocationHandler) Report(w http.ResponseWriter, r *http.Request) {
+	courierID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || courierID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Lat        *float64  `json:"lat"`
+		Lon        *float64  `json:"lon"`
+		AccuracyM  float64   `json:"accuracy_m"`
+		RecordedAt time.Time `json:"recorded_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.Lat == nil || req.Lon == nil {
+		http.Error(w, "lat and lon are required", http.StatusBadRequest)
+		return
+	}
+
+	loc := model.CourierLocation{
+		CourierID:  courierID,
+		Lat:        *req.Lat,
+		Lon:        *req.Lon,
+		AccuracyM:  req.AccuracyM,
+		RecordedAt: req.RecordedAt.UTC(),
+	}
+	if err := h.locationUC.Report(r.Context(), &loc); err != nil {
+		switch err {
+		case usecase.ErrBadInput:
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+		case usecase.ErrNotFound:
+			http.Error(w, "Courier not found", http.StatusNotFound)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(loc)
+}
+
+func (h *LocationHandler) GetLatest(w http.ResponseWriter, r *http.Request) {
+	courierID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || courierID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	loc, err := h.locationUC.GetLatest(r.Context(), courierID)
+	if err != nil {
+		if err == usecase.ErrNotFound {
+			http.Error(w, "Location not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(loc)
+}
@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"avito-courier/internal/middleware"
+	"avito-courier/internal/model"
+	"avito-courier/internal/usecase"
+)
+
+// MeHandler serves the courier app. Every route except IssueToken runs
+// behind CourierTokens.Middleware and acts on the authenticated courier
+// only; IssueToken runs behind the dispatcher key.
+type MeHandler struct {
+	deliveryUC    usecase.IDeliveryUsecase
+	pauseUC       usecase.PauseUsecase
+	courierUC     usecase.CourierUsecase
+	tokens        *middleware.CourierTokens
+	dispatcherKey string
+}
+
+func NewMeHandler(deliveryUC usecase.IDeliveryUsecase, pauseUC usecase.PauseUsecase, courierUC usecase.CourierUsecase, tokens *middleware.CourierTokens, dispatcherKey string) *MeHandler {
+	return &MeHandler{
+		deliveryUC:    deliveryUC,
+		pauseUC:       pauseUC,
+		courierUC:     courierUC,
+		tokens:        tokens,
+		dispatcherKey: dispatcherKey,
+	}
+}
+
+// Auth wraps a courier route with the token check.
+func (h *MeHandler) Auth(next http.HandlerFunc) http.Handler {
+	return h.tokens.Middleware()(next)
+}
+
+// Dispatcher wraps a route only dispatchers may call, such as IssueToken.
+func (h *MeHandler) Dispatcher(next http.HandlerFunc) http.Handler {
+	return middleware.DispatcherAuth(h.dispatcherKey)(next)
+}
+
+func courierID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, ok := middleware.CourierIDFrom(r.Context())
+	if !ok {
+		http.Error(w, "Missing courier token", http.StatusUnauthorized)
+	}
+	return id, ok
+}
+
+// IssueToken gives a dispatcher a token to hand to the courier's app.
+func (h *MeHandler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.courierUC.GetByID(r.Context(), id); err != nil {
+		if err == usecase.ErrNotFound {
+			http.Error(w, "Courier not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	token, expires, err := h.tokens.Issue(id)
+	if err == middleware.ErrTokensDisabled {
+		http.Error(w, "Courier API is disabled", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]any{"token": token, "expires_at": expires})
+}
+
+// Deliveries lists the courier's assigned and picked up deliveries.
+func (h *MeHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := courierID(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.deliveryUC.ListActive(r.Context(), id)
+	if err != nil {
+		writeAssignError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(items)
+}
+
+func (h *MeHandler) PickUp(w http.ResponseWriter, r *http.Request) {
+	id, ok := courierID(w, r)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || deliveryID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	delivery, err := h.deliveryUC.PickUp(r.Context(), id, deliveryID)
+	if err != nil {
+		writeAssignError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// Offers lists the offers waiting for the courier's answer.
+func (h *MeHandler) Offers(w http.ResponseWriter, r *http.Request) {
+	id, ok := courierID(w, r)
+	if !ok {
+		return
+	}
+
+	offers, err := h.deliveryUC.ListOffers(r.Context(), id)
+	if err != nil {
+		writeAssignError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(offers)
+}
+
+func offerID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// AcceptOffer answers with the delivery created for the offer.
+func (h *MeHandler) AcceptOffer(w http.ResponseWriter, r *http.Request) {
+	id, ok := courierID(w, r)
+	if !ok {
+		return
+	}
+	offer, ok := offerID(w, r)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.deliveryUC.AcceptOffer(r.Context(), id, offer)
+	if err != nil {
+		writeOfferError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(delivery)
+}
+
+func (h *MeHandler) DeclineOffer(w http.ResponseWriter, r *http.Request) {
+	id, ok := courierID(w, r)
+	if !ok {
+		return
+	}
+	offer, ok := offerID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.deliveryUC.DeclineOffer(r.Context(), id, offer); err != nil {
+		writeOfferError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": "declined"})
+}
+
+func writeOfferError(w http.ResponseWriter, err error) {
+	if err == usecase.ErrNotFound {
+		http.Error(w, "Offer not found", http.StatusNotFound)
+		return
+	}
+	writeAssignError(w, err)
+}
+
+// Deliver takes the same body as DeliveryHandler.Complete.
+func (h *MeHandler) Deliver(w http.ResponseWriter, r *http.Request) {
+	if id, ok := courierID(w, r); ok {
+		completeDelivery(w, r, h.deliveryUC, id)
+	}
+}
+
+// Fail takes the same body as DeliveryHandler.Fail.
+func (h *MeHandler) Fail(w http.ResponseWriter, r *http.Request) {
+	if id, ok := courierID(w, r); ok {
+		failDelivery(w, r, h.deliveryUC, id)
+	}
+}
+
+// Status lets the courier go on a pause or come back. "busy" is not
+// accepted; it follows from the courier's deliveries.
+func (h *MeHandler) Status(w http.ResponseWriter, r *http.Request) {
+	id, ok := courierID(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Status   string     `json:"status"`
+		Reason   string     `json:"reason,omitempty"`
+		ResumeAt *time.Time `json:"resume_at,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	switch req.Status {
+	case "paused":
+		pause, err := h.pauseUC.Pause(r.Context(), id, req.Reason, req.ResumeAt)
+		if err != nil {
+			writePauseError(w, err)
+			return
+		}
+		status := http.StatusOK
+		if pause.State == model.PausePending {
+			status = http.StatusAccepted
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(pause)
+	case "available":
+		courier, err := h.pauseUC.Resume(r.Context(), id)
+		if err != nil {
+			writePauseError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(model.ToDTO(courier))
+	default:
+		http.Error(w, "status must be paused or available", http.StatusBadRequest)
+	}
+}
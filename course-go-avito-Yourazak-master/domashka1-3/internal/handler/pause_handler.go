@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/usecase"
+)
+
+type PauseHandler struct {
+	pauseUC usecase.PauseUsecase
+}
+
+func NewPauseHandler(pauseUC usecase.PauseUsecase) *PauseHandler {
+	return &PauseHandler{pauseUC: pauseUC}
+}
+
+type pauseRequest struct {
+	Reason   string     `json:"reason"`
+	ResumeAt *time.Time `json:"resume_at"`
+}
+
+// Pause answers 200 when the courier is paused right away and 202 when the
+// pause waits for the active delivery to finish.
+func (h *PauseHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	courierID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || courierID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req pauseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	pause, err := h.pauseUC.Pause(r.Context(), courierID, req.Reason, req.ResumeAt)
+	if err != nil {
+		writePauseError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if pause.State == model.PausePending {
+		status = http.StatusAccepted
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(pause)
+}
+
+func (h *PauseHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	courierID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || courierID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	courier, err := h.pauseUC.Resume(r.Context(), courierID)
+	if err != nil {
+		writePauseError(w, err)
+		return
+	}
+
+	setCourierETag(w, courier)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(model.ToDTO(courier))
+}
+
+func writePauseError(w http.ResponseWriter, err error) {
+	switch err {
+	case usecase.ErrBadInput:
+		http.Error(w, "Invalid input data", http.StatusBadRequest)
+	case usecase.ErrNotFound:
+		http.Error(w, "Courier not found", http.StatusNotFound)
+	case usecase.ErrConflict:
+		http.Error(w, "Courier is not paused", http.StatusConflict)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPauseUsecase struct {
+	mock.Mock
+}
+
+func (m *MockPauseUsecase) Pause(ctx context.Context, courierID int, reason string, resumeAt *time.Time) (model.CourierPause, error) {
+	args := m.Called(ctx, courierID, reason, resumeAt)
+	return args.Get(0).(model.CourierPause), args.Error(1)
+}
+
+func (m *MockPauseUsecase) Resume(ctx context.Context, courierID int) (model.Courier, error) {
+	args := m.Called(ctx, courierID)
+	return args.Get(0).(model.Courier), args.Error(1)
+}
+
+func TestPauseHandler_Pause(t *testing.T) {
+	tests := []struct {
+		name  string
+		state string
+		want  int
+	}{
+		{name: "paused right away", state: model.PauseActive, want: http.StatusOK},
+		{name: "after active delivery", state: model.PausePending, want: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(MockPauseUsecase)
+			handler := NewPauseHandler(mockUsecase)
+
+			mockUsecase.On("Pause", mock.Anything, 3, model.PauseBreak, (*time.Time)(nil)).
+				Return(model.CourierPause{CourierID: 3, Reason: model.PauseBreak, State: tt.state}, nil)
+
+			req := httptest.NewRequest("POST", "/api/couriers/3/pause", strings.NewReader(`{"reason":"break"}`))
+			req.SetPathValue("id", "3")
+			rr := httptest.NewRecorder()
+
+			handler.Pause(rr, req)
+
+			assert.Equal(t, tt.want, rr.Code)
+			var response model.CourierPause
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, tt.state, response.State)
+		})
+	}
+}
+
+func TestPauseHandler_Pause_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		body string
+		err  error
+		want int
+	}{
+		{name: "invalid id", id: "x", body: `{}`, want: http.StatusBadRequest},
+		{name: "invalid json", id: "3", body: `{`, want: http.StatusBadRequest},
+		{name: "bad reason", id: "3", body: `{"reason":"nap"}`, err: usecase.ErrBadInput, want: http.StatusBadRequest},
+		{name: "unknown courier", id: "3", body: `{"reason":"break"}`, err: usecase.ErrNotFound, want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(MockPauseUsecase)
+			handler := NewPauseHandler(mockUsecase)
+
+			mockUsecase.On("Pause", mock.Anything, 3, mock.Anything, mock.Anything).Return(model.CourierPause{}, tt.err)
+
+			req := httptest.NewRequest("POST", "/api/couriers/"+tt.id+"/pause", strings.NewReader(tt.body))
+			req.SetPathValue("id", tt.id)
+			rr := httptest.NewRecorder()
+
+			handler.Pause(rr, req)
+
+			assert.Equal(t, tt.want, rr.Code)
+		})
+	}
+}
+
+func TestPauseHandler_Resume(t *testing.T) {
+	mockUsecase := new(MockPauseUsecase)
+	handler := NewPauseHandler(mockUsecase)
+
+	mockUsecase.On("Resume", mock.Anything, 3).Return(model.Courier{ID: 3, Status: "available", Version: 5}, nil)
+
+	req := httptest.NewRequest("POST", "/api/couriers/3/resume", nil)
+	req.SetPathValue("id", "3")
+	rr := httptest.NewRecorder()
+
+	handler.Resume(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"5"`, rr.Header().Get("ETag"))
+}
+
+func TestPauseHandler_Resume_NotPaused(t *testing.T) {
+	mockUsecase := new(MockPauseUsecase)
+	handler := NewPauseHandler(mockUsecase)
+
+	mockUsecase.On("Resume", mock.Anything, 3).Return(model.Courier{}, usecase.ErrConflict)
+
+	req := httptest.NewRequest("POST", "/api/couriers/3/resume", nil)
+	req.SetPathValue("id", "3")
+	rr := httptest.NewRecorder()
+
+	handler.Resume(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+}
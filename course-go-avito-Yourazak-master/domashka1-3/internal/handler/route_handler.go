@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/usecase"
+)
+
+type RouteHandler struct {
+	routeUC usecase.RouteUsecase
+}
+
+func NewRouteHandler(routeUC usecase.RouteUsecase) *RouteHandler {
+	return &RouteHandler{routeUC: routeUC}
+}
+
+func (h *RouteHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	route, err := h.routeUC.Get(r.Context(), id)
+	if err != nil {
+		switch err {
+		case usecase.ErrBadInput:
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+		case usecase.ErrNotFound:
+			http.Error(w, "Route not found", http.StatusNotFound)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(route)
+}
+
+// Plan returns the best order of the posted stops for the courier together
+// with the estimated arrival at each stop.
+func (h *RouteHandler) Plan(w http.ResponseWriter, r *http.Request) {
+	var req model.RoutePlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	plan, err := h.routeUC.Plan(r.Context(), req)
+	if err != nil {
+		switch err {
+		case usecase.ErrBadInput:
+			http.Error(w, "courier_id and 1-100 distinct stops with order_id, kind (pickup or dropoff) and valid location are required", http.StatusBadRequest)
+		case usecase.ErrNotFound:
+			http.Error(w, "Courier not found", http.StatusNotFound)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(plan)
+}
+
+// CompleteStop marks a route stop done and returns the route with the rest
+// of it planned again.
+func (h *RouteHandler) CompleteStop(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	seq, err := strconv.Atoi(r.PathValue("seq"))
+	if err != nil || seq <= 0 {
+		http.Error(w, "Invalid stop", http.StatusBadRequest)
+		return
+	}
+
+	route, err := h.routeUC.CompleteStop(r.Context(), id, seq)
+	if err != nil {
+		switch err {
+		case usecase.ErrBadInput:
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+		case usecase.ErrNotFound:
+			http.Error(w, "Route or stop not found", http.StatusNotFound)
+		case usecase.ErrConflict:
+			http.Error(w, "Stop already completed or route finished", http.StatusConflict)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(route)
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/usecase"
+)
+
+type ShiftHandler struct {
+	shiftUC usecase.ShiftUsecase
+}
+
+func NewShiftHandler(shiftUC usecase.ShiftUsecase) *ShiftHandler {
+	return &ShiftHandler{shiftUC: shiftUC}
+}
+
+type shiftRequest struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+func (h *ShiftHandler) Create(w http.ResponseWriter, r *http.Request) {
+	courierID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || courierID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req shiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	shift := model.CourierShift{
+		CourierID: courierID,
+		StartsAt:  req.StartsAt.UTC(),
+		EndsAt:    req.EndsAt.UTC(),
+	}
+	if err := h.shiftUC.Create(r.Context(), &shift); err != nil {
+		writeShiftError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(shift)
+}
+
+func (h *ShiftHandler) ListByCourier(w http.ResponseWriter, r *http.Request) {
+	courierID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || courierID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var from, to time.Time
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, "Invalid from", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, "Invalid to", http.StatusBadRequest)
+			return
+		}
+	}
+
+	shifts, err := h.shiftUC.ListByCourier(r.Context(), courierID, from, to)
+	if err != nil {
+		writeShiftError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(shifts)
+}
+
+func (h *ShiftHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	shift, err := h.shiftUC.GetByID(r.Context(), id)
+	if err != nil {
+		writeShiftError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(shift)
+}
+
+func (h *ShiftHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req shiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	shift := model.CourierShift{
+		ID:       id,
+		StartsAt: req.StartsAt.UTC(),
+		EndsAt:   req.EndsAt.UTC(),
+	}
+	if err := h.shiftUC.Update(r.Context(), &shift); err != nil {
+		writeShiftError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(shift)
+}
+
+func (h *ShiftHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.shiftUC.Delete(r.Context(), id); err != nil {
+		writeShiftError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeShiftError(w http.ResponseWriter, err error) {
+	switch err {
+	case usecase.ErrBadInput:
+		http.Error(w, "Invalid input data", http.StatusBadRequest)
+	case usecase.ErrNotFound:
+		http.Error(w, "Shift or courier not found", http.StatusNotFound)
+	case usecase.ErrConflict:
+		http.Error(w, "Shift overlaps another shift or is already finished", http.StatusConflict)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"avito-courier/internal/usecase"
+)
+
+type StatusHistoryHandler struct {
+	historyUC usecase.StatusHistoryUsecase
+}
+
+func NewStatusHistoryHandler(historyUC usecase.StatusHistoryUsecase) *StatusHistoryHandler {
+	return &StatusHistoryHandler{historyUC: historyUC}
+}
+
+func (h *StatusHistoryHandler) List(w http.ResponseWriter, r *http.Request) {
+	courierID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || courierID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	entries, err := h.historyUC.List(r.Context(), courierID, limit)
+	if err != nil {
+		switch err {
+		case usecase.ErrBadInput:
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+		case usecase.ErrNotFound:
+			http.Error(w, "Courier not found", http.StatusNotFound)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entries)
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"golang.org/x/net/websocket"
+)
+
+// streamHeartbeat keeps idle SSE connections open through proxies.
+const streamHeartbeat = 15 * time.Second
+
+type deliveryStream interface {
+	Subscribe(f model.DeliveryEventFilter) (<-chan model.DeliveryEvent, func())
+}
+
+// StreamHandler pushes delivery events to dispatch dashboards over
+// Server-Sent Events or WebSocket. Both accept the optional courier_id and
+// order_id query parameters to narrow the stream.
+type StreamHandler struct {
+	stream deliveryStream
+}
+
+func NewStreamHandler(stream deliveryStream) *StreamHandler {
+	return &StreamHandler{stream: stream}
+}
+
+func streamFilter(r *http.Request) (model.DeliveryEventFilter, bool) {
+	f := model.DeliveryEventFilter{OrderID: r.URL.Query().Get("order_id")}
+	if v := r.URL.Query().Get("courier_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			return f, false
+		}
+		f.CourierID = id
+	}
+	return f, true
+}
+
+// Deliveries streams events as text/event-stream, one SSE event per
+// delivery change named after its type.
+func (h *StreamHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	filter, ok := streamFilter(r)
+	if !ok {
+		http.Error(w, "Invalid courier_id", http.StatusBadRequest)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// The server write timeout is meant for ordinary requests.
+	rc.SetWriteDeadline(time.Time{})
+
+	events, cancel := h.stream.Subscribe(filter)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// DeliveriesWS streams the same events as Deliveries, one JSON text
+// message per change. Messages from the client are ignored.
+func (h *StreamHandler) DeliveriesWS(w http.ResponseWriter, r *http.Request) {
+	filter, ok := streamFilter(r)
+	if !ok {
+		http.Error(w, "Invalid courier_id", http.StatusBadRequest)
+		return
+	}
+
+	server := websocket.Server{
+		// Dashboards may connect from any origin, like the REST API.
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			h.serveWS(r.Context(), ws, filter)
+		},
+	}
+	server.ServeHTTP(hijacker{w}, r)
+}
+
+func (h *StreamHandler) serveWS(ctx context.Context, ws *websocket.Conn, filter model.DeliveryEventFilter) {
+	defer ws.Close()
+	ws.SetDeadline(time.Time{})
+
+	events, cancel := h.stream.Subscribe(filter)
+	defer cancel()
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		var msg string
+		for websocket.Message.Receive(ws, &msg) == nil {
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-closed:
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := websocket.JSON.Send(ws, e); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// hijacker exposes the Hijack of a wrapped ResponseWriter, which the
+// websocket package asserts on directly.
+type hijacker struct {
+	http.ResponseWriter
+}
+
+func (h hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(h.ResponseWriter).Hijack()
+}
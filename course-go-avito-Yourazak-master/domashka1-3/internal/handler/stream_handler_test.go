@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	otherCourierEvent = `{"type":"assigned","delivery_id":1,"order_id":"order-1","courier_id":2,"status":"assigned"}`
+	ownCourierEvent   = `{"type":"assigned","delivery_id":2,"order_id":"order-2","courier_id":7,"status":"assigned"}`
+)
+
+func newStreamServer() (*usecase.DeliveryStream, *httptest.Server) {
+	stream := usecase.NewDeliveryStream()
+	h := NewStreamHandler(stream)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/stream/deliveries", h.Deliveries)
+	mux.HandleFunc("GET /api/stream/deliveries/ws", h.DeliveriesWS)
+	return stream, httptest.NewServer(mux)
+}
+
+// publishUntil publishes payloads until done is closed, since the client may
+// not have subscribed yet when the first ones go out.
+func publishUntil(stream *usecase.DeliveryStream, done <-chan struct{}, payloads ...string) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		for _, p := range payloads {
+			stream.Publish(p)
+		}
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func TestStreamHandler_Deliveries_SSE(t *testing.T) {
+	stream, srv := newStreamServer()
+	defer srv.Close()
+	defer stream.Close()
+
+	resp, err := http.Get(srv.URL + "/api/stream/deliveries?courier_id=7")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	done := make(chan struct{})
+	defer close(done)
+	go publishUntil(stream, done, otherCourierEvent, ownCourierEvent)
+
+	reader := bufio.NewReader(resp.Body)
+	var event, data string
+	for data == "" {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event: "))
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimSpace(strings.TrimPrefix(line, "data: "))
+		}
+	}
+
+	assert.Equal(t, model.DeliveryEventAssigned, event)
+	assert.Contains(t, data, `"order_id":"order-2"`)
+}
+
+func TestStreamHandler_Deliveries_InvalidFilter(t *testing.T) {
+	h := NewStreamHandler(usecase.NewDeliveryStream())
+
+	req := httptest.NewRequest("GET", "/api/stream/deliveries?courier_id=abc", nil)
+	rr := httptest.NewRecorder()
+
+	h.Deliveries(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestStreamHandler_DeliveriesWS(t *testing.T) {
+	stream, srv := newStreamServer()
+	defer srv.Close()
+	defer stream.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/stream/deliveries/ws?courier_id=7"
+	ws, err := websocket.Dial(url, "", srv.URL)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer ws.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go publishUntil(stream, done, otherCourierEvent, ownCourierEvent)
+
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var e model.DeliveryEvent
+	if err := websocket.JSON.Receive(ws, &e); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+
+	assert.Equal(t, "order-2", e.OrderID)
+	assert.Equal(t, 7, e.CourierID)
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"avito-courier/internal/middleware"
+	"avito-courier/internal/model"
+	"avito-courier/internal/usecase"
+)
+
+type WebhookHandler struct {
+	processor usecase.OrderEventProcessor
+	secrets   map[string]string
+	tolerance time.Duration
+}
+
+func NewWebhookHandler(processor usecase.OrderEventProcessor, partnerSecrets map[string]string, signatureTolerance time.Duration) *WebhookHandler {
+	return &WebhookHandler{
+		processor: processor,
+		secrets:   partnerSecrets,
+		tolerance: signatureTolerance,
+	}
+}
+
+func (h *WebhookHandler) Middleware() func(http.Handler) http.Handler {
+	return middleware.WebhookSignature(h.secrets, h.tolerance)
+}
+
+type webhookEventResult struct {
+	OrderID string `json:"order_id"`
+	Status  string `json:"status"`
+	Result  string `json:"result"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Orders accepts either a single model.OrderEvent or a JSON array of them.
+// The signature is checked by Middleware before this runs.
+func (h *WebhookHandler) Orders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	batch := len(trimmed) > 0 && trimmed[0] == '['
+
+	var events []model.OrderEvent
+	if batch {
+		err = json.Unmarshal(trimmed, &events)
+	} else {
+		var event model.OrderEvent
+		err = json.Unmarshal(trimmed, &event)
+		events = []model.OrderEvent{event}
+	}
+	if err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if len(events) == 0 {
+		http.Error(w, "No events", http.StatusBadRequest)
+		return
+	}
+
+	partnerID := r.Header.Get(middleware.PartnerIDHeader)
+	results := make([]webhookEventResult, 0, len(events))
+	processed, failed := 0, 0
+	for _, event := range events {
+		res := webhookEventResult{OrderID: event.OrderID, Status: event.Status, Result: "processed"}
+		// The signed partner is the merchant, whatever the body claims.
+		event.MerchantID = partnerID
+
+		if err := h.processor.Process(r.Context(), event); err != nil {
+			switch {
+			case errors.Is(err, usecase.ErrInvalidEvent):
+				res.Result = "invalid"
+			case errors.Is(err, usecase.ErrStatusMismatch):
+				res.Result = "skipped"
+			case errors.Is(err, usecase.ErrNoEventHandler):
+				// Retrying would not help: nothing handles this status.
+				res.Result = "unsupported"
+			default:
+				res.Result = "failed"
+				failed++
+				log.Printf("Webhook event %s from %s failed: %v", event.OrderID, partnerID, err)
+			}
+			res.Error = err.Error()
+		} else {
+			processed++
+		}
+		results = append(results, res)
+	}
+
+	status := http.StatusOK
+	if !batch {
+		switch results[0].Result {
+		case "invalid":
+			http.Error(w, "Invalid order event", http.StatusBadRequest)
+			return
+		case "unsupported":
+			http.Error(w, "Unsupported order status", http.StatusUnprocessableEntity)
+			return
+		case "failed":
+			status = http.StatusInternalServerError
+		}
+	}
+
+	response := struct {
+		Processed int                  `json:"processed"`
+		Failed    int                  `json:"failed"`
+		Results   []webhookEventResult `json:"results"`
+	}{
+		Processed: processed,
+		Failed:    failed,
+		Results:   results,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
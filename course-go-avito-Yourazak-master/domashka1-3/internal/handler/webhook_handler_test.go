@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"avito-courier/internal/middleware"
+	"avito-courier/internal/model"
+	"avito-courier/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOrderEventProcessor struct {
+	mock.Mock
+}
+
+func (m *MockOrderEventProcessor) Process(ctx context.Context, event model.OrderEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func eventFor(orderID string) interface{} {
+	return mock.MatchedBy(func(e model.OrderEvent) bool { return e.OrderID == orderID })
+}
+
+func signedOrdersRequest(partnerID, secret, body string) *http.Request {
+	req := httptest.NewRequest("POST", "/api/webhooks/orders", strings.NewReader(body))
+	req.Header.Set(middleware.PartnerIDHeader, partnerID)
+	timestamp := time.Now().Unix()
+	req.Header.Set(middleware.TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(middleware.SignatureHeader, middleware.SignPayload(secret, timestamp, []byte(body)))
+	return req
+}
+
+func serveOrders(h *WebhookHandler, req *http.Request) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	h.Middleware()(http.HandlerFunc(h.Orders)).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestWebhookHandler_Orders_Single(t *testing.T) {
+	processor := new(MockOrderEventProcessor)
+	handler := NewWebhookHandler(processor, map[string]string{"shop": "shop-secret"}, 0)
+
+	processor.On("Process", mock.Anything, eventFor("order-1")).Return(nil)
+
+	rr := serveOrders(handler, signedOrdersRequest("shop", "shop-secret", `{"order_id":"order-1","status":"created"}`))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	processor.AssertExpectations(t)
+}
+
+func TestWebhookHandler_Orders_MerchantFromPartner(t *testing.T) {
+	processor := new(MockOrderEventProcessor)
+	handler := NewWebhookHandler(processor, map[string]string{"shop": "shop-secret"}, 0)
+
+	processor.On("Process", mock.Anything, mock.MatchedBy(func(e model.OrderEvent) bool {
+		return e.OrderID == "order-1" && e.MerchantID == "shop"
+	})).Return(nil)
+
+	body := `{"order_id":"order-1","status":"created","merchant_id":"other-shop"}`
+	rr := serveOrders(handler, signedOrdersRequest("shop", "shop-secret", body))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	processor.AssertExpectations(t)
+}
+
+func TestWebhookHandler_Orders_RejectsBadSignature(t *testing.T) {
+	processor := new(MockOrderEventProcessor)
+	handler := NewWebhookHandler(processor, map[string]string{"shop": "shop-secret"}, 0)
+
+	rr := serveOrders(handler, signedOrdersRequest("shop", "wrong-secret", `{"order_id":"order-1","status":"created"}`))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = serveOrders(handler, signedOrdersRequest("other", "shop-secret", `{"order_id":"order-1","status":"created"}`))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	processor.AssertNotCalled(t, "Process", mock.Anything, mock.Anything)
+}
+
+func TestWebhookHandler_Orders_SingleErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "invalid", err: usecase.ErrInvalidEvent, want: http.StatusBadRequest},
+		{name: "status mismatch", err: usecase.ErrStatusMismatch, want: http.StatusOK},
+		{name: "no handler", err: usecase.ErrNoEventHandler, want: http.StatusUnprocessableEntity},
+		{name: "failed", err: errors.New("db down"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := new(MockOrderEventProcessor)
+			handler := NewWebhookHandler(processor, map[string]string{"shop": "shop-secret"}, 0)
+
+			processor.On("Process", mock.Anything, eventFor("order-1")).Return(tt.err)
+
+			rr := serveOrders(handler, signedOrdersRequest("shop", "shop-secret", `{"order_id":"order-1","status":"created"}`))
+
+			assert.Equal(t, tt.want, rr.Code)
+		})
+	}
+}
+
+func TestWebhookHandler_Orders_Batch(t *testing.T) {
+	processor := new(MockOrderEventProcessor)
+	handler := NewWebhookHandler(processor, map[string]string{"shop": "shop-secret"}, 0)
+
+	processor.On("Process", mock.Anything, eventFor("order-1")).Return(nil)
+	processor.On("Process", mock.Anything, eventFor("order-2")).Return(usecase.ErrInvalidEvent)
+	processor.On("Process", mock.Anything, eventFor("order-3")).Return(errors.New("db down"))
+
+	body := `[{"order_id":"order-1","status":"created"},{"order_id":"order-2"},{"order_id":"order-3","status":"created"}]`
+	rr := serveOrders(handler, signedOrdersRequest("shop", "shop-secret", body))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response struct {
+		Processed int                  `json:"processed"`
+		Failed    int                  `json:"failed"`
+		Results   []webhookEventResult `json:"results"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Processed)
+	assert.Equal(t, 1, response.Failed)
+	if assert.Len(t, response.Results, 3) {
+		assert.Equal(t, "processed", response.Results[0].Result)
+		assert.Equal(t, "invalid", response.Results[1].Result)
+		assert.Equal(t, "failed", response.Results[2].Result)
+	}
+}
+
+func TestWebhookHandler_Orders_InvalidJSON(t *testing.T) {
+	processor := new(MockOrderEventProcessor)
+	handler := NewWebhookHandler(processor, map[string]string{"shop": "shop-secret"}, 0)
+
+	rr := serveOrders(handler, signedOrdersRequest("shop", "shop-secret", `{"order_id":`))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = serveOrders(handler, signedOrdersRequest("shop", "shop-secret", `[]`))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
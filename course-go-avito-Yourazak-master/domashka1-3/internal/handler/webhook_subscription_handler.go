@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/usecase"
+)
+
+// WebhookSubscriptionHandler manages the outgoing webhooks merchants receive
+// for the delivery lifecycle of their orders.
+type WebhookSubscriptionHandler struct {
+	webhookUC usecase.WebhookUsecase
+}
+
+func NewWebhookSubscriptionHandler(webhookUC usecase.WebhookUsecase) *WebhookSubscriptionHandler {
+	return &WebhookSubscriptionHandler{webhookUC: webhookUC}
+}
+
+type webhookSubscriptionRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	MerchantID string   `json:"merchant_id"`
+	Secret     string   `json:"secret"`
+	// Active defaults to true; set it to re-enable a disabled subscription.
+	Active *bool `json:"active"`
+}
+
+func (req webhookSubscriptionRequest) toSubscription(id int64) model.WebhookSubscription {
+	return model.WebhookSubscription{
+		ID:         id,
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		MerchantID: req.MerchantID,
+		Secret:     req.Secret,
+		Active:     req.Active == nil || *req.Active,
+	}
+}
+
+func subscriptionID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *WebhookSubscriptionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req webhookSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	sub := req.toSubscription(0)
+	if err := h.webhookUC.Create(r.Context(), &sub); err != nil {
+		writeWebhookSubscriptionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sub)
+}
+
+func (h *WebhookSubscriptionHandler) List(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.webhookUC.List(r.Context())
+	if err != nil {
+		writeWebhookSubscriptionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(subs)
+}
+
+func (h *WebhookSubscriptionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := subscriptionID(w, r)
+	if !ok {
+		return
+	}
+
+	sub, err := h.webhookUC.GetByID(r.Context(), id)
+	if err != nil {
+		writeWebhookSubscriptionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sub)
+}
+
+// Update replaces the subscription. An empty secret keeps the current one.
+func (h *WebhookSubscriptionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := subscriptionID(w, r)
+	if !ok {
+		return
+	}
+
+	var req webhookSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	sub := req.toSubscription(id)
+	if err := h.webhookUC.Update(r.Context(), &sub); err != nil {
+		writeWebhookSubscriptionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sub)
+}
+
+func (h *WebhookSubscriptionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := subscriptionID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookUC.Delete(r.Context(), id); err != nil {
+		writeWebhookSubscriptionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries is the delivery log of a subscription, newest first. It accepts
+// the optional state and limit query parameters.
+func (h *WebhookSubscriptionHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := subscriptionID(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.webhookUC.ListDeliveries(r.Context(), id, r.URL.Query().Get("state"), limit)
+	if err != nil {
+		writeWebhookSubscriptionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+func writeWebhookSubscriptionError(w http.ResponseWriter, err error) {
+	switch err {
+	case usecase.ErrBadInput:
+		http.Error(w, "Invalid input data", http.StatusBadRequest)
+	case usecase.ErrNotFound:
+		http.Error(w, "Webhook subscription not found", http.StatusNotFound)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookUsecase struct {
+	mock.Mock
+}
+
+func (m *MockWebhookUsecase) Create(ctx context.Context, s *model.WebhookSubscription) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
+
+func (m *MockWebhookUsecase) GetByID(ctx context.Context, id int64) (model.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) List(ctx context.Context) ([]model.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) Update(ctx context.Context, s *model.WebhookSubscription) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
+
+func (m *MockWebhookUsecase) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookUsecase) ListDeliveries(ctx context.Context, subscriptionID int64, state string, limit int) ([]model.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionID, state, limit)
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
+}
+
+func TestWebhookSubscriptionHandler_Create(t *testing.T) {
+	mockUsecase := new(MockWebhookUsecase)
+	handler := NewWebhookSubscriptionHandler(mockUsecase)
+
+	mockUsecase.On("Create", mock.Anything, mock.MatchedBy(func(s *model.WebhookSubscription) bool {
+		return s.URL == "https://shop.example/hooks" && s.Active && len(s.EventTypes) == 1
+	})).Run(func(args mock.Arguments) {
+		s := args.Get(1).(*model.WebhookSubscription)
+		s.ID = 1
+		s.Secret = "generated-secret-0123456789"
+	}).Return(nil)
+
+	body := `{"url":"https://shop.example/hooks","event_types":["assigned"]}`
+	req := httptest.NewRequest("POST", "/api/webhooks/subscriptions", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	handler.Create(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var response map[string]any
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, float64(1), response["id"])
+}
+
+func TestWebhookSubscriptionHandler_Create_BadInput(t *testing.T) {
+	mockUsecase := new(MockWebhookUsecase)
+	handler := NewWebhookSubscriptionHandler(mockUsecase)
+
+	mockUsecase.On("Create", mock.Anything, mock.Anything).Return(usecase.ErrBadInput)
+
+	req := httptest.NewRequest("POST", "/api/webhooks/subscriptions", strings.NewReader(`{"url":"ftp://x"}`))
+	rr := httptest.NewRecorder()
+
+	handler.Create(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestWebhookSubscriptionHandler_Update_Deactivates(t *testing.T) {
+	mockUsecase := new(MockWebhookUsecase)
+	handler := NewWebhookSubscriptionHandler(mockUsecase)
+
+	mockUsecase.On("Update", mock.Anything, mock.MatchedBy(func(s *model.WebhookSubscription) bool {
+		return s.ID == 2 && !s.Active
+	})).Return(nil)
+
+	body := `{"url":"https://shop.example/hooks","event_types":["assigned"],"active":false}`
+	req := httptest.NewRequest("PUT", "/api/webhooks/subscriptions/2", strings.NewReader(body))
+	req.SetPathValue("id", "2")
+	rr := httptest.NewRecorder()
+
+	handler.Update(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestWebhookSubscriptionHandler_GetByID_NotFound(t *testing.T) {
+	mockUsecase := new(MockWebhookUsecase)
+	handler := NewWebhookSubscriptionHandler(mockUsecase)
+
+	mockUsecase.On("GetByID", mock.Anything, int64(9)).Return(model.WebhookSubscription{}, usecase.ErrNotFound)
+
+	req := httptest.NewRequest("GET", "/api/webhooks/subscriptions/9", nil)
+	req.SetPathValue("id", "9")
+	rr := httptest.NewRecorder()
+
+	handler.GetByID(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestWebhookSubscriptionHandler_Delete(t *testing.T) {
+	mockUsecase := new(MockWebhookUsecase)
+	handler := NewWebhookSubscriptionHandler(mockUsecase)
+
+	mockUsecase.On("Delete", mock.Anything, int64(2)).Return(nil)
+
+	req := httptest.NewRequest("DELETE", "/api/webhooks/subscriptions/2", nil)
+	req.SetPathValue("id", "2")
+	rr := httptest.NewRecorder()
+
+	handler.Delete(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestWebhookSubscriptionHandler_Deliveries(t *testing.T) {
+	mockUsecase := new(MockWebhookUsecase)
+	handler := NewWebhookSubscriptionHandler(mockUsecase)
+
+	mockUsecase.On("ListDeliveries", mock.Anything, int64(2), model.WebhookDeliveryFailed, 10).
+		Return([]model.WebhookDelivery{{ID: 7, SubscriptionID: 2, State: model.WebhookDeliveryFailed}}, nil)
+
+	req := httptest.NewRequest("GET", "/api/webhooks/subscriptions/2/deliveries?state=failed&limit=10", nil)
+	req.SetPathValue("id", "2")
+	rr := httptest.NewRecorder()
+
+	handler.Deliveries(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response []model.WebhookDelivery
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Len(t, response, 1)
+
+	req = httptest.NewRequest("GET", "/api/webhooks/subscriptions/2/deliveries?limit=ten", nil)
+	req.SetPathValue("id", "2")
+	rr = httptest.NewRecorder()
+
+	handler.Deliveries(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/usecase"
+)
+
+type ZoneHandler struct {
+	zoneUC usecase.ZoneUsecase
+}
+
+func NewZoneHandler(zoneUC usecase.ZoneUsecase) *ZoneHandler {
+	return &ZoneHandler{zoneUC: zoneUC}
+}
+
+type zoneRequest struct {
+	Name         string           `json:"name"`
+	RegionID     *int             `json:"region_id"`
+	Polygon      []model.GeoPoint `json:"polygon"`
+	NeighbourIDs []int            `json:"neighbour_ids"`
+}
+
+func (req zoneRequest) toZone(id int) model.DeliveryZone {
+	return model.DeliveryZone{
+		ID:           id,
+		Name:         req.Name,
+		RegionID:     req.RegionID,
+		Polygon:      req.Polygon,
+		NeighbourIDs: req.NeighbourIDs,
+	}
+}
+
+type courierZonesRequest struct {
+	ZoneIDs []int `json:"zone_ids"`
+}
+
+func (h *ZoneHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req zoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	zone := req.toZone(0)
+	if err := h.zoneUC.Create(r.Context(), &zone); err != nil {
+		writeZoneError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(zone)
+}
+
+func (h *ZoneHandler) List(w http.ResponseWriter, r *http.Request) {
+	zones, err := h.zoneUC.List(r.Context())
+	if err != nil {
+		writeZoneError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(zones)
+}
+
+func (h *ZoneHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	zone, err := h.zoneUC.GetByID(r.Context(), id)
+	if err != nil {
+		writeZoneError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(zone)
+}
+
+func (h *ZoneHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req zoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	zone := req.toZone(id)
+	if err := h.zoneUC.Update(r.Context(), &zone); err != nil {
+		writeZoneError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(zone)
+}
+
+func (h *ZoneHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.zoneUC.Delete(r.Context(), id); err != nil {
+		writeZoneError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ZoneHandler) ListByCourier(w http.ResponseWriter, r *http.Request) {
+	courierID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || courierID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	zones, err := h.zoneUC.ListByCourier(r.Context(), courierID)
+	if err != nil {
+		writeZoneError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(zones)
+}
+
+// SetCourierZones replaces the full set of zones the courier serves.
+func (h *ZoneHandler) SetCourierZones(w http.ResponseWriter, r *http.Request) {
+	courierID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || courierID <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req courierZonesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.zoneUC.SetCourierZones(r.Context(), courierID, req.ZoneIDs); err != nil {
+		writeZoneError(w, err)
+		return
+	}
+
+	zones, err := h.zoneUC.ListByCourier(r.Context(), courierID)
+	if err != nil {
+		writeZoneError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(zones)
+}
+
+func writeZoneError(w http.ResponseWriter, err error) {
+	switch err {
+	case usecase.ErrBadInput:
+		http.Error(w, "Invalid input data", http.StatusBadRequest)
+	case usecase.ErrNotFound:
+		http.Error(w, "Zone or courier not found", http.StatusNotFound)
+	case usecase.ErrConflict:
+		http.Error(w, "Zone with this region already exists", http.StatusConflict)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
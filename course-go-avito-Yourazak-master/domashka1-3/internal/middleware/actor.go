@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"avito-courier/internal/model"
+)
+
+const maxActorLength = 64
+
+// ActorMiddleware marks courier status changes made by a request as coming
+// from the API. An X-Actor header names the caller, e.g. "api:dispatcher-7".
+func ActorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := model.ActorAPI
+		if name := strings.TrimSpace(r.Header.Get("X-Actor")); name != "" {
+			if len(name) > maxActorLength {
+				name = name[:maxActorLength]
+			}
+			actor += ":" + name
+		}
+
+		ctx := model.WithStatusChange(r.Context(), model.StatusChange{Actor: actor})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
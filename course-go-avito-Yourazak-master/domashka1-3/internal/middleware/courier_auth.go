@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"avito-courier/internal/model"
+)
+
+var ErrTokensDisabled = errors.New("courier tokens are disabled")
+
+type courierIDKey struct{}
+
+// CourierIDFrom returns the courier authenticated by CourierTokens.Middleware.
+func CourierIDFrom(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(courierIDKey{}).(int)
+	return id, ok
+}
+
+// CourierTokens issues and checks the bearer tokens of the courier app. A
+// token is "<courier id>.<expiry unix>.<hex HMAC-SHA256 of both>", so no
+// server-side session is needed.
+type CourierTokens struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewCourierTokens(secret string, ttl time.Duration) *CourierTokens {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &CourierTokens{secret: []byte(secret), ttl: ttl, now: time.Now}
+}
+
+func (t *CourierTokens) sign(payload string) string {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Issue returns a token for the courier and when it expires.
+func (t *CourierTokens) Issue(courierID int) (string, time.Time, error) {
+	if len(t.secret) == 0 {
+		return "", time.Time{}, ErrTokensDisabled
+	}
+	expires := t.now().Add(t.ttl).UTC().Truncate(time.Second)
+	payload := strconv.Itoa(courierID) + "." + strconv.FormatInt(expires.Unix(), 10)
+	return payload + "." + t.sign(payload), expires, nil
+}
+
+// Verify returns the courier ID of a valid, unexpired token.
+func (t *CourierTokens) Verify(token string) (int, bool) {
+	if len(t.secret) == 0 {
+		return 0, false
+	}
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return 0, false
+	}
+	payload, sig := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(t.sign(payload)), []byte(strings.ToLower(sig))) {
+		return 0, false
+	}
+
+	idPart, expPart, ok := strings.Cut(payload, ".")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.Atoi(idPart)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	exp, err := strconv.ParseInt(expPart, 10, 64)
+	if err != nil || !t.now().Before(time.Unix(exp, 0)) {
+		return 0, false
+	}
+	return id, true
+}
+
+// Middleware rejects requests without a valid courier token in the
+// Authorization header. The courier's ID is put in the request context and
+// status changes made by the request are attributed to the courier.
+func (t *CourierTokens) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				http.Error(w, "Missing courier token", http.StatusUnauthorized)
+				return
+			}
+			courierID, ok := t.Verify(strings.TrimSpace(token))
+			if !ok {
+				http.Error(w, "Invalid courier token", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), courierIDKey{}, courierID)
+			ctx = model.WithStatusChange(ctx, model.StatusChange{Actor: model.ActorAPI + ":courier-" + strconv.Itoa(courierID)})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
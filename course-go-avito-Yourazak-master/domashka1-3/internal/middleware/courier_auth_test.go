@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"avito-courier/internal/model"
+)
+
+func TestCourierTokens(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tokens := NewCourierTokens("secret", time.Hour)
+	tokens.now = func() time.Time { return now }
+
+	token, expires, err := tokens.Issue(7)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	if !expires.Equal(now.Add(time.Hour)) {
+		t.Errorf("Expected expiry %v, got %v", now.Add(time.Hour), expires)
+	}
+
+	if id, ok := tokens.Verify(token); !ok || id != 7 {
+		t.Errorf("Expected courier 7, got %d, %v", id, ok)
+	}
+
+	forged := strings.Replace(token, "7.", "8.", 1)
+	if _, ok := tokens.Verify(forged); ok {
+		t.Error("Token with a changed courier ID was accepted")
+	}
+	if _, ok := NewCourierTokens("other", time.Hour).Verify(token); ok {
+		t.Error("Token signed with another secret was accepted")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, ok := tokens.Verify(token); ok {
+		t.Error("Expired token was accepted")
+	}
+
+	if _, _, err := NewCourierTokens("", time.Hour).Issue(7); err != ErrTokensDisabled {
+		t.Errorf("Expected ErrTokensDisabled, got %v", err)
+	}
+}
+
+func TestCourierTokensMiddleware(t *testing.T) {
+	tokens := NewCourierTokens("secret", time.Hour)
+	token, _, _ := tokens.Issue(7)
+
+	handler := tokens.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := CourierIDFrom(r.Context())
+		if !ok || id != 7 {
+			t.Errorf("Expected courier 7 in context, got %d, %v", id, ok)
+		}
+		if actor := model.StatusChangeFrom(r.Context()).Actor; actor != "api:courier-7" {
+			t.Errorf("Expected actor api:courier-7, got %q", actor)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	testCases := []struct {
+		name     string
+		header   string
+		expected int
+	}{
+		{"Valid", "Bearer " + token, http.StatusOK},
+		{"Missing", "", http.StatusUnauthorized},
+		{"Not bearer", token, http.StatusUnauthorized},
+		{"Garbage", "Bearer abc", http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/me/deliveries", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expected {
+				t.Errorf("Expected %d, got %d", tc.expected, rr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// DispatcherAuth rejects requests that don't carry the dispatcher API key as
+// a bearer token. Without a configured key the wrapped routes are disabled,
+// so they are never left open by accident.
+func DispatcherAuth(key string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key == "" {
+				http.Error(w, "Dispatcher API is disabled", http.StatusServiceUnavailable)
+				return
+			}
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				http.Error(w, "Missing dispatcher key", http.StatusUnauthorized)
+				return
+			}
+			if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(key)) != 1 {
+				http.Error(w, "Invalid dispatcher key", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
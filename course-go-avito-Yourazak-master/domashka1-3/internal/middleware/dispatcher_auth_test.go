@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDispatcherAuth(t *testing.T) {
+	testCases := []struct {
+		name   string
+		key    string
+		header string
+		status int
+	}{
+		{"valid key", "dispatch-key", "Bearer dispatch-key", http.StatusOK},
+		{"wrong key", "dispatch-key", "Bearer other-key", http.StatusUnauthorized},
+		{"missing header", "dispatch-key", "", http.StatusUnauthorized},
+		{"courier token", "dispatch-key", "Bearer 7.1735732800.abcdef", http.StatusUnauthorized},
+		{"disabled", "", "Bearer ", http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := DispatcherAuth(tc.key)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/couriers/7/token", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.status {
+				t.Errorf("Expected status %d, got %d", tc.status, rec.Code)
+			}
+		})
+	}
+}
@@ -41,6 +41,74 @@ var (
 		},
 		[]string{"method", "status", "retry_count"},
 	)
+
+	AssignmentQueueDepth = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "assignment_queue_depth",
+			Help: "Number of orders waiting for a courier",
+		},
+	)
+
+	AssignmentQueueWaitSeconds = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "assignment_queue_wait_seconds",
+			Help:    "Time orders spent in the assignment queue before getting a courier",
+			Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600},
+		},
+	)
+
+	AssignmentQueueAttemptsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "assignment_queue_attempts_total",
+			Help: "Total number of assignment attempts made by the queue worker",
+		},
+		[]string{"result"},
+	)
+
+	CourierOffersTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "courier_offers_total",
+			Help: "Total number of delivery offers by outcome",
+		},
+		[]string{"outcome"},
+	)
+
+	DeliveryStreamSubscribers = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "delivery_stream_subscribers",
+			Help: "Number of open delivery event streams on this replica",
+		},
+	)
+
+	DeliveryStreamEventsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "delivery_stream_events_total",
+			Help: "Total number of delivery events pushed to stream subscribers, by result",
+		},
+		[]string{"result"},
+	)
+
+	WebhookDeliveriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "webhook_deliveries_total",
+			Help: "Total number of outgoing webhook attempts, by result",
+		},
+		[]string{"result"},
+	)
+
+	WebhookSubscriptionsDisabledTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "webhook_subscriptions_disabled_total",
+			Help: "Total number of webhook subscriptions disabled after repeated failures",
+		},
+	)
+
+	OrderPollerEventsDroppedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "order_poller_events_dropped_total",
+			Help: "Total number of polled order events given up after repeated failures",
+		},
+	)
 )
 
 type metricsResponseWriter struct {
@@ -53,6 +121,12 @@ func (rw *metricsResponseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap lets http.ResponseController reach the flusher and hijacker of the
+// underlying writer.
+func (rw *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func MetricsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
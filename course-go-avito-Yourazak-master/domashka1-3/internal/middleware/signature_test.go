@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	secrets := map[string]string{"partner-a": "secret-a"}
+	body := []byte(`{"order_id":"o1","status":"created","created_at":"2025-01-01T00:00:00Z"}`)
+	now := time.Now().Unix()
+	stale := time.Now().Add(-10 * time.Minute).Unix()
+
+	handler := WebhookSignature(secrets, 5*time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ := io.ReadAll(r.Body)
+		if !bytes.Equal(got, body) {
+			t.Errorf("Body was not restored: got %q", got)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	testCases := []struct {
+		name      string
+		partnerID string
+		timestamp string
+		signature string
+		expected  int
+	}{
+		{"Valid", "partner-a", strconv.FormatInt(now, 10), SignPayload("secret-a", now, body), http.StatusOK},
+		{"Valid without prefix", "partner-a", strconv.FormatInt(now, 10), SignPayload("secret-a", now, body)[len("sha256="):], http.StatusOK},
+		{"Wrong secret", "partner-a", strconv.FormatInt(now, 10), SignPayload("secret-b", now, body), http.StatusUnauthorized},
+		{"Unknown partner", "partner-b", strconv.FormatInt(now, 10), SignPayload("secret-a", now, body), http.StatusUnauthorized},
+		{"Missing signature", "partner-a", strconv.FormatInt(now, 10), "", http.StatusUnauthorized},
+		{"Missing timestamp", "partner-a", "", SignPayload("secret-a", now, body), http.StatusUnauthorized},
+		{"Stale timestamp", "partner-a", strconv.FormatInt(stale, 10), SignPayload("secret-a", stale, body), http.StatusUnauthorized},
+		{"Timestamp not signed", "partner-a", strconv.FormatInt(now, 10), SignPayload("secret-a", stale, body), http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/webhooks/orders", bytes.NewReader(body))
+			req.Header.Set(PartnerIDHeader, tc.partnerID)
+			req.Header.Set(TimestampHeader, tc.timestamp)
+			req.Header.Set(SignatureHeader, tc.signature)
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expected {
+				t.Errorf("Expected %d, got %d", tc.expected, rr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+const (
+	DeliveryKindDelivery = "delivery"
+	// DeliveryKindReturn takes an order that could not be handed over back
+	// to its pickup point.
+	DeliveryKindReturn = "return"
+)
+
+// Reasons a courier gives for a failed handover.
+const (
+	FailureCustomerAbsent = "customer_absent"
+	FailureWrongAddress   = "wrong_address"
+	FailureNoAccess       = "no_access"
+	FailureRefused        = "refused"
+	FailureDamaged        = "damaged"
+	FailureOther          = "other"
+)
+
+// Outcomes of a failed attempt.
+const (
+	AttemptRetry  = "retry"
+	AttemptReturn = "return"
+)
+
+var failureReasons = map[string]bool{
+	FailureCustomerAbsent: true,
+	FailureWrongAddress:   true,
+	FailureNoAccess:       true,
+	FailureRefused:        false,
+	FailureDamaged:        false,
+	FailureOther:          true,
+}
+
+func ValidFailureReason(reason string) bool {
+	_, ok := failureReasons[reason]
+	return ok
+}
+
+// FailureRetryable reports whether another attempt makes sense after a
+// failure for this reason; a refused or damaged order goes straight back.
+func FailureRetryable(reason string) bool {
+	return failureReasons[reason]
+}
+
+// DeliveryAttempt is one failed handover of an order.
+type DeliveryAttempt struct {
+	ID         int64     `json:"id"`
+	OrderID    string    `json:"order_id"`
+	DeliveryID int       `json:"delivery_id"`
+	CourierID  int       `json:"courier_id"`
+	Attempt    int       `json:"attempt"`
+	Reason     string    `json:"reason"`
+	Comment    string    `json:"comment,omitempty"`
+	Outcome    string    `json:"outcome"`
+	Location   *GeoPoint `json:"location,omitempty"`
+	Actor      string    `json:"actor"`
+	CreatedAt  time.Time `json:"created_at"`
+}
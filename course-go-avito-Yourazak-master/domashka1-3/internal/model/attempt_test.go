@@ -0,0 +1,20 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureReasons(t *testing.T) {
+	assert.True(t, ValidFailureReason(FailureCustomerAbsent))
+	assert.True(t, ValidFailureReason(FailureRefused))
+	assert.False(t, ValidFailureReason("lost"))
+	assert.False(t, ValidFailureReason(""))
+
+	assert.True(t, FailureRetryable(FailureCustomerAbsent))
+	assert.True(t, FailureRetryable(FailureOther))
+	assert.False(t, FailureRetryable(FailureRefused))
+	assert.False(t, FailureRetryable(FailureDamaged))
+	assert.False(t, FailureRetryable("lost"))
+}
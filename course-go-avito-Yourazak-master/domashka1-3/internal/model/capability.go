@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// TransportLimits caps what a courier can carry. A zero field means the
+// dimension is not limited.
+type TransportLimits struct {
+	MaxWeightKg   float64 `json:"max_weight_kg,omitempty"`
+	MaxVolumeL    float64 `json:"max_volume_l,omitempty"`
+	MaxDistanceKm float64 `json:"max_distance_km,omitempty"`
+}
+
+// Override returns l with every non-zero field of o applied on top.
+func (l TransportLimits) Override(o TransportLimits) TransportLimits {
+	if o.MaxWeightKg > 0 {
+		l.MaxWeightKg = o.MaxWeightKg
+	}
+	if o.MaxVolumeL > 0 {
+		l.MaxVolumeL = o.MaxVolumeL
+	}
+	if o.MaxDistanceKm > 0 {
+		l.MaxDistanceKm = o.MaxDistanceKm
+	}
+	return l
+}
+
+// Allows reports whether an order of the given weight and volume fits, and
+// whether distanceKm is within range. A non-positive distance is not checked.
+func (l TransportLimits) Allows(weightKg, volumeL, distanceKm float64) bool {
+	if l.MaxWeightKg > 0 && weightKg > l.MaxWeightKg {
+		return false
+	}
+	if l.MaxVolumeL > 0 && volumeL > l.MaxVolumeL {
+		return false
+	}
+	if l.MaxDistanceKm > 0 && distanceKm > l.MaxDistanceKm {
+		return false
+	}
+	return true
+}
+
+func (l TransportLimits) Valid() bool {
+	return l.MaxWeightKg >= 0 && l.MaxVolumeL >= 0 && l.MaxDistanceKm >= 0
+}
+
+// CourierCapability is a per-courier override of the transport type limits.
+type CourierCapability struct {
+	CourierID int             `json:"courier_id"`
+	Limits    TransportLimits `json:"limits"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
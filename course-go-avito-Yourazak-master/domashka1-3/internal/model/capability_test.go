@@ -0,0 +1,26 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportLimits_Allows(t *testing.T) {
+	onFoot := TransportLimits{MaxWeightKg: 5, MaxVolumeL: 20, MaxDistanceKm: 3}
+
+	assert.True(t, onFoot.Allows(4, 10, 2))
+	assert.True(t, onFoot.Allows(4, 10, 0))
+	assert.False(t, onFoot.Allows(25, 10, 2))
+	assert.False(t, onFoot.Allows(4, 30, 2))
+	assert.False(t, onFoot.Allows(4, 10, 5))
+	assert.True(t, TransportLimits{}.Allows(1000, 1000, 1000))
+}
+
+func TestTransportLimits_Override(t *testing.T) {
+	base := TransportLimits{MaxWeightKg: 5, MaxVolumeL: 20, MaxDistanceKm: 3}
+
+	got := base.Override(TransportLimits{MaxWeightKg: 8})
+
+	assert.Equal(t, TransportLimits{MaxWeightKg: 8, MaxVolumeL: 20, MaxDistanceKm: 3}, got)
+}
@@ -3,11 +3,16 @@ package model
 import "time"
 
 type Courier struct {
-	ID            int       `json:"id"`
-	Name          string    `json:"name"`
-	Phone         string    `json:"phone"`
-	Status        string    `json:"status"`
-	TransportType string    `json:"transport_type"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID                 int       `json:"id"`
+	Name               string    `json:"name"`
+	Phone              string    `json:"phone"`
+	Status             string    `json:"status"`
+	TransportType      string    `json:"transport_type"`
+	Email              string    `json:"email,omitempty"`
+	VehiclePlate       string    `json:"vehicle_plate,omitempty"`
+	Rating             *float64  `json:"rating,omitempty"`
+	ExternalEmployeeID string    `json:"external_employee_id,omitempty"`
+	Version            int64     `json:"version,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
 }
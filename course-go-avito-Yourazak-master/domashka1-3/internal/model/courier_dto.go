@@ -3,33 +3,55 @@ package model
 import "time"
 
 type CourierDTO struct {
-	ID            int       `json:"id,omitempty"`
-	Name          string    `json:"name"`
-	Phone         string    `json:"phone"`
-	Status        string    `json:"status"`
-	TransportType string    `json:"transport_type"`
-	CreatedAt     time.Time `json:"created_at,omitempty"`
-	UpdatedAt     time.Time `json:"updated_at,omitempty"`
+	ID                 int       `json:"id,omitempty"`
+	Name               string    `json:"name"`
+	Phone              string    `json:"phone"`
+	Status             string    `json:"status"`
+	TransportType      string    `json:"transport_type"`
+	Email              string    `json:"email,omitempty"`
+	VehiclePlate       string    `json:"vehicle_plate,omitempty"`
+	Rating             *float64  `json:"rating,omitempty"`
+	ExternalEmployeeID string    `json:"external_employee_id,omitempty"`
+	Version            int64     `json:"version,omitempty"`
+	CreatedAt          time.Time `json:"created_at,omitempty"`
+	UpdatedAt          time.Time `json:"updated_at,omitempty"`
 }
 
 func ToDTO(c Courier) CourierDTO {
 	return CourierDTO{
-		ID:            c.ID,
-		Name:          c.Name,
-		Phone:         c.Phone,
-		Status:        c.Status,
-		TransportType: c.TransportType,
-		CreatedAt:     c.CreatedAt,
-		UpdatedAt:     c.UpdatedAt,
+		ID:                 c.ID,
+		Name:               c.Name,
+		Phone:              c.Phone,
+		Status:             c.Status,
+		TransportType:      c.TransportType,
+		Email:              c.Email,
+		VehiclePlate:       c.VehiclePlate,
+		Rating:             c.Rating,
+		ExternalEmployeeID: c.ExternalEmployeeID,
+		Version:            c.Version,
+		CreatedAt:          c.CreatedAt,
+		UpdatedAt:          c.UpdatedAt,
 	}
 }
 
 func FromDTO(d CourierDTO) Courier {
 	return Courier{
-		ID:            d.ID,
-		Name:          d.Name,
-		Phone:         d.Phone,
-		Status:        d.Status,
-		TransportType: d.TransportType,
+		ID:                 d.ID,
+		Name:               d.Name,
+		Phone:              d.Phone,
+		Status:             d.Status,
+		TransportType:      d.TransportType,
+		Email:              d.Email,
+		VehiclePlate:       d.VehiclePlate,
+		Rating:             d.Rating,
+		ExternalEmployeeID: d.ExternalEmployeeID,
 	}
 }
+
+func ToDTOs(couriers []Courier) []CourierDTO {
+	out := make([]CourierDTO, 0, len(couriers))
+	for _, c := range couriers {
+		out = append(out, ToDTO(c))
+	}
+	return out
+}
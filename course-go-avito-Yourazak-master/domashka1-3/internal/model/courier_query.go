@@ -0,0 +1,78 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	CourierSortID        = "id"
+	CourierSortName      = "name"
+	CourierSortCreatedAt = "created_at"
+	CourierSortUpdatedAt = "updated_at"
+)
+
+// CourierQuery filters, sorts and pages the courier list. Either Offset or
+// After (keyset pagination) is used, not both.
+type CourierQuery struct {
+	Statuses       []string
+	TransportTypes []string
+	Search         string
+	CreatedFrom    time.Time
+	CreatedTo      time.Time
+	SortBy         string
+	Desc           bool
+	Limit          int
+	Offset         int
+	After          *CourierCursor
+}
+
+// CourierCursor points just past the last courier of a page. It is bound to
+// the sort order it was created for.
+type CourierCursor struct {
+	SortBy string `json:"s"`
+	Desc   bool   `json:"d,omitempty"`
+	Value  string `json:"v,omitempty"`
+	ID     int    `json:"i"`
+}
+
+func NewCourierCursor(c Courier, sortBy string, desc bool) CourierCursor {
+	cursor := CourierCursor{SortBy: sortBy, Desc: desc, ID: c.ID}
+	switch sortBy {
+	case CourierSortName:
+		cursor.Value = c.Name
+	case CourierSortCreatedAt:
+		cursor.Value = c.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case CourierSortUpdatedAt:
+		cursor.Value = c.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	default:
+		cursor.Value = strconv.Itoa(c.ID)
+	}
+	return cursor
+}
+
+func (c CourierCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCourierCursor(s string) (CourierCursor, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return CourierCursor{}, false
+	}
+	var c CourierCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return CourierCursor{}, false
+	}
+	return c, true
+}
+
+type CourierPage struct {
+	Items []Courier
+	Total int
+	// Next is set when there are more couriers after this page.
+	Next *CourierCursor
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCourierCursor_RoundTrip(t *testing.T) {
+	created := time.Date(2025, 3, 1, 10, 0, 0, 123456000, time.UTC)
+	cursor := NewCourierCursor(Courier{ID: 42, Name: "John", CreatedAt: created}, CourierSortCreatedAt, true)
+
+	decoded, ok := DecodeCourierCursor(cursor.Encode())
+
+	assert.True(t, ok)
+	assert.Equal(t, cursor, decoded)
+	assert.Equal(t, "2025-03-01T10:00:00.123456Z", decoded.Value)
+}
+
+func TestDecodeCourierCursor_Invalid(t *testing.T) {
+	for _, s := range []string{"", "!!!", "e30"} {
+		_, ok := DecodeCourierCursor(s)
+		assert.False(t, ok, s)
+	}
+}
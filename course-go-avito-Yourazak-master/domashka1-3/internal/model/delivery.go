@@ -8,7 +8,44 @@ type Delivery struct {
 	OrderID    string    `json:"order_id"`
 	AssignedAt time.Time `json:"assigned_at"`
 	Deadline   time.Time `json:"deadline"`
+	Priority   string    `json:"priority"`
+	// Kind is DeliveryKindDelivery, or DeliveryKindReturn for an order on its
+	// way back to the pickup point.
+	Kind string `json:"kind"`
+	// WindowStart and WindowEnd are set for scheduled deliveries.
+	WindowStart *time.Time `json:"window_start,omitempty"`
+	WindowEnd   *time.Time `json:"window_end,omitempty"`
+	// RouteID is set when the delivery is one of several on a route.
+	RouteID *int `json:"route_id,omitempty"`
+	// ETA is the estimated arrival at the dropoff point.
+	ETA *time.Time `json:"eta,omitempty"`
+	// PickedUpAt is set once the courier has collected the order.
+	PickedUpAt *time.Time `json:"picked_up_at,omitempty"`
+	// Proof is set once the courier completed the delivery.
+	Proof *DeliveryProof `json:"proof,omitempty"`
+	// MerchantID is the partner the order came from, if known.
+	MerchantID string    `json:"merchant_id,omitempty"`
+	PINHash    string    `json:"-"`
 	Status     string    `json:"status"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
+
+// ValidDeliveryStatus reports whether s is a status a delivery can have.
+func ValidDeliveryStatus(s string) bool {
+	switch s {
+	case "assigned", "picked_up", "completed", "expired":
+		return true
+	}
+	return false
+}
+
+// DeliveryQuery filters and pages the delivery list, newest first. Zero
+// fields do not filter.
+type DeliveryQuery struct {
+	CourierID int
+	OrderID   string
+	Statuses  []string
+	Limit     int
+	Offset    int
+}
@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// DeliveryEventsChannel is the Postgres NOTIFY channel the deliveries
+// trigger publishes DeliveryEvent payloads on.
+const DeliveryEventsChannel = "delivery_events"
+
+// Delivery event types.
+const (
+	DeliveryEventAssigned      = "assigned"
+	DeliveryEventReassigned    = "reassigned"
+	DeliveryEventUnassigned    = "unassigned"
+	DeliveryEventStatusChanged = "status_changed"
+	DeliveryEventExpired       = "expired"
+)
+
+// DeliveryEvent is one change of a delivery as pushed to stream subscribers.
+type DeliveryEvent struct {
+	Type       string `json:"type"`
+	DeliveryID int    `json:"delivery_id"`
+	OrderID    string `json:"order_id"`
+	CourierID  int    `json:"courier_id"`
+	// PreviousCourierID is set when the delivery moved to another courier.
+	PreviousCourierID *int   `json:"previous_courier_id,omitempty"`
+	Status            string `json:"status"`
+	// PreviousStatus is set for updates.
+	PreviousStatus *string   `json:"previous_status,omitempty"`
+	Kind           string    `json:"kind"`
+	At             time.Time `json:"at"`
+}
+
+// DeliveryEventFilter narrows a stream down to one courier and/or one
+// order. The zero value matches every event.
+type DeliveryEventFilter struct {
+	CourierID int
+	OrderID   string
+}
+
+// Matches reports whether e passes f. A courier filter also matches the
+// event that took a delivery away from that courier.
+func (f DeliveryEventFilter) Matches(e DeliveryEvent) bool {
+	if f.OrderID != "" && e.OrderID != f.OrderID {
+		return false
+	}
+	if f.CourierID != 0 && e.CourierID != f.CourierID &&
+		(e.PreviousCourierID == nil || *e.PreviousCourierID != f.CourierID) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryEventFilter_Matches(t *testing.T) {
+	prev := 3
+	e := DeliveryEvent{Type: DeliveryEventReassigned, OrderID: "o1", CourierID: 5, PreviousCourierID: &prev}
+
+	assert.True(t, DeliveryEventFilter{}.Matches(e))
+	assert.True(t, DeliveryEventFilter{CourierID: 5}.Matches(e))
+	assert.True(t, DeliveryEventFilter{CourierID: 3}.Matches(e))
+	assert.False(t, DeliveryEventFilter{CourierID: 4}.Matches(e))
+	assert.True(t, DeliveryEventFilter{OrderID: "o1", CourierID: 5}.Matches(e))
+	assert.False(t, DeliveryEventFilter{OrderID: "o2"}.Matches(e))
+	assert.False(t, DeliveryEventFilter{OrderID: "o2", CourierID: 5}.Matches(e))
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+const earthRadiusKm = 6371.0
+
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+func (p GeoPoint) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lon >= -180 && p.Lon <= 180
+}
+
+// DistanceKm returns the great-circle (haversine) distance between two points.
+func (p GeoPoint) DistanceKm(other GeoPoint) float64 {
+	lat1 := p.Lat * math.Pi / 180
+	lat2 := other.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (other.Lon - p.Lon) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// BoundingBox returns a lat/lon rectangle that contains every point within
+// radiusKm of p. It is used as a cheap index-friendly prefilter before the
+// exact haversine check.
+func (p GeoPoint) BoundingBox(radiusKm float64) (minLat, maxLat, minLon, maxLon float64) {
+	dLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	minLat = math.Max(-90, p.Lat-dLat)
+	maxLat = math.Min(90, p.Lat+dLat)
+
+	cosLat := math.Cos(p.Lat * math.Pi / 180)
+	if cosLat < 1e-6 || maxLat >= 90 || minLat <= -90 {
+		return minLat, maxLat, -180, 180
+	}
+	dLon := dLat / cosLat
+	minLon = math.Max(-180, p.Lon-dLon)
+	maxLon = math.Min(180, p.Lon+dLon)
+	return minLat, maxLat, minLon, maxLon
+}
+
+type CourierLocation struct {
+	CourierID  int       `json:"courier_id"`
+	Lat        float64   `json:"lat"`
+	Lon        float64   `json:"lon"`
+	AccuracyM  float64   `json:"accuracy_m,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+func (l CourierLocation) Point() GeoPoint {
+	return GeoPoint{Lat: l.Lat, Lon: l.Lon}
+}
+
+// CandidateFilter narrows down the couriers considered for an assignment.
+type CandidateFilter struct {
+	ZoneIDs []int
+	// ExcludeIDs leaves out couriers that must not get the order, such as
+	// the current courier on reassignment.
+	ExcludeIDs     []int
+	Near           *GeoPoint
+	RadiusKm       float64
+	MaxLocationAge time.Duration
+	// MinWeightKg and MinVolumeL drop couriers whose effective limits (the
+	// per-courier override, else TransportLimits by transport type) are lower.
+	MinWeightKg     float64
+	MinVolumeL      float64
+	TransportLimits map[string]TransportLimits
+	Limit           int
+}
+
+type CourierCandidate struct {
+	Courier    Courier
+	Location   *CourierLocation
+	Overrides  TransportLimits
+	DistanceKm float64
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeoPoint_DistanceKm(t *testing.T) {
+	moscow := GeoPoint{Lat: 55.7558, Lon: 37.6173}
+	spb := GeoPoint{Lat: 59.9343, Lon: 30.3351}
+
+	assert.InDelta(t, 634, moscow.DistanceKm(spb), 5)
+	assert.InDelta(t, 0, moscow.DistanceKm(moscow), 1e-9)
+}
+
+func TestGeoPoint_BoundingBox(t *testing.T) {
+	center := GeoPoint{Lat: 55.7558, Lon: 37.6173}
+
+	minLat, maxLat, minLon, maxLon := center.BoundingBox(10)
+
+	for _, p := range []GeoPoint{
+		{Lat: center.Lat + 0.089, Lon: center.Lon},
+		{Lat: center.Lat, Lon: center.Lon + 0.159},
+		{Lat: center.Lat - 0.06, Lon: center.Lon - 0.1},
+	} {
+		assert.LessOrEqual(t, center.DistanceKm(p), 10.0)
+		assert.True(t, p.Lat >= minLat && p.Lat <= maxLat && p.Lon >= minLon && p.Lon <= maxLon)
+	}
+}
+
+func TestGeoPoint_Valid(t *testing.T) {
+	assert.True(t, GeoPoint{Lat: 0, Lon: 0}.Valid())
+	assert.False(t, GeoPoint{Lat: 91, Lon: 0}.Valid())
+	assert.False(t, GeoPoint{Lat: 0, Lon: -181}.Valid())
+}
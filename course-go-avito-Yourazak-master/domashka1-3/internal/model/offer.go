@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+const (
+	OfferPending   = "pending"
+	OfferAccepted  = "accepted"
+	OfferDeclined  = "declined"
+	OfferExpired   = "expired"
+	OfferCancelled = "cancelled"
+)
+
+// DeliveryOffer proposes an order to a courier, who may accept or decline
+// it until ExpiresAt. The courier sees where to pick up and drop off the
+// order, not the full order.
+type DeliveryOffer struct {
+	ID          int64         `json:"id"`
+	OrderID     string        `json:"order_id"`
+	CourierID   int           `json:"courier_id"`
+	Order       ExternalOrder `json:"-"`
+	Pickup      *GeoPoint     `json:"pickup,omitempty"`
+	Dropoff     *GeoPoint     `json:"dropoff,omitempty"`
+	Priority    string        `json:"priority,omitempty"`
+	State       string        `json:"state"`
+	ExpiresAt   time.Time     `json:"expires_at"`
+	RespondedAt *time.Time    `json:"responded_at,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// OfferStats sums up how a courier answered offers. Cancelled offers are
+// left out; AcceptanceRate is accepted over answered or expired offers.
+type OfferStats struct {
+	CourierID      int     `json:"courier_id"`
+	Offered        int     `json:"offered"`
+	Accepted       int     `json:"accepted"`
+	Declined       int     `json:"declined"`
+	Expired        int     `json:"expired"`
+	Pending        int     `json:"pending"`
+	AcceptanceRate float64 `json:"acceptance_rate"`
+}
+
+// Rate fills in AcceptanceRate from the counts.
+func (s *OfferStats) Rate() {
+	resolved := s.Accepted + s.Declined + s.Expired
+	if resolved == 0 {
+		s.AcceptanceRate = 0
+		return
+	}
+	s.AcceptanceRate = float64(s.Accepted) / float64(resolved)
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOfferStats_Rate(t *testing.T) {
+	s := OfferStats{Accepted: 3, Declined: 1, Expired: 0, Pending: 2}
+	s.Rate()
+	assert.InDelta(t, 0.75, s.AcceptanceRate, 1e-9)
+
+	empty := OfferStats{Pending: 1}
+	empty.Rate()
+	assert.Equal(t, 0.0, empty.AcceptanceRate)
+}
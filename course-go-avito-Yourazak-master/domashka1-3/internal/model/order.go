@@ -3,17 +3,71 @@ package model
 import "time"
 
 type ExternalOrder struct {
-	ID        string    `json:"id"`
-	Weight    float64   `json:"weight"`
-	Region    int       `json:"region"`
-	Cost      int       `json:"cost"`
-	CreatedAt time.Time `json:"created_at"`
+	ID       string    `json:"id"`
+	Weight   float64   `json:"weight"`
+	Volume   float64   `json:"volume,omitempty"`
+	Region   int       `json:"region"`
+	Cost     int       `json:"cost"`
+	Pickup   *GeoPoint `json:"pickup,omitempty"`
+	Dropoff  *GeoPoint `json:"dropoff,omitempty"`
+	Priority string    `json:"priority,omitempty"`
+	// WindowStart and WindowEnd bound the delivery slot the customer
+	// booked; both are nil for orders to be delivered right away.
+	WindowStart *time.Time `json:"window_start,omitempty"`
+	WindowEnd   *time.Time `json:"window_end,omitempty"`
+	// PINHash is HashPIN of the code the customer gives the courier at
+	// handover; the plain code is never kept.
+	PINHash string `json:"pin_hash,omitempty"`
+	// MerchantID is the partner the order came from; webhook subscriptions
+	// scoped to a merchant only see its deliveries.
+	MerchantID string    `json:"merchant_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// HasWindow reports whether the order was booked for a delivery slot.
+func (o ExternalOrder) HasWindow() bool {
+	return o.WindowStart != nil || o.WindowEnd != nil
+}
+
+// ValidWindow reports whether the slot, if any, is complete and not empty.
+func (o ExternalOrder) ValidWindow() bool {
+	if !o.HasWindow() {
+		return true
+	}
+	return o.WindowStart != nil && o.WindowEnd != nil && o.WindowStart.Before(*o.WindowEnd)
 }
 
 type OrderEvent struct {
-	OrderID   string    `json:"order_id"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
+	OrderID     string     `json:"order_id"`
+	Status      string     `json:"status"`
+	Region      int        `json:"region,omitempty"`
+	Weight      float64    `json:"weight,omitempty"`
+	Volume      float64    `json:"volume,omitempty"`
+	Pickup      *GeoPoint  `json:"pickup,omitempty"`
+	Dropoff     *GeoPoint  `json:"dropoff,omitempty"`
+	Priority    string     `json:"priority,omitempty"`
+	WindowStart *time.Time `json:"window_start,omitempty"`
+	WindowEnd   *time.Time `json:"window_end,omitempty"`
+	PIN         string     `json:"pin,omitempty"`
+	MerchantID  string     `json:"merchant_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (e OrderEvent) Order() ExternalOrder {
+	return ExternalOrder{
+		ID:          e.OrderID,
+		Region:      e.Region,
+		Weight:      e.Weight,
+		Volume:      e.Volume,
+		Pickup:      e.Pickup,
+		Dropoff:     e.Dropoff,
+		Priority:    e.Priority,
+		WindowStart: e.WindowStart,
+		WindowEnd:   e.WindowEnd,
+		PINHash:     HashPIN(e.OrderID, e.PIN),
+		MerchantID:  e.MerchantID,
+		CreatedAt:   e.CreatedAt,
+	}
 }
 
 func (e *OrderEvent) Validate() bool {
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+const (
+	PauseBreak        = "break"
+	PauseVehicleIssue = "vehicle_issue"
+	PauseEndOfDay     = "end_of_day"
+)
+
+// A pause requested while the courier has an active delivery stays pending
+// until the delivery is finished.
+const (
+	PausePending = "pending"
+	PauseActive  = "active"
+)
+
+type CourierPause struct {
+	CourierID int        `json:"courier_id"`
+	Reason    string     `json:"reason"`
+	State     string     `json:"state"`
+	ResumeAt  *time.Time `json:"resume_at,omitempty"`
+	PausedAt  *time.Time `json:"paused_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func ValidPauseReason(reason string) bool {
+	switch reason {
+	case PauseBreak, PauseVehicleIssue, PauseEndOfDay:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// States of a queued order.
+const (
+	PendingWaiting = "pending"
+	// PendingFailed orders ran out of attempts and are no longer retried.
+	PendingFailed = "failed"
+)
+
+// PendingAssignment is an order waiting in the assignment queue for a
+// courier to become available.
+type PendingAssignment struct {
+	ID            int64         `json:"id"`
+	OrderID       string        `json:"order_id"`
+	Order         ExternalOrder `json:"order"`
+	Priority      int           `json:"priority"`
+	Attempts      int           `json:"attempts"`
+	LastError     string        `json:"last_error,omitempty"`
+	EnqueuedAt    time.Time     `json:"enqueued_at"`
+	LastAttemptAt *time.Time    `json:"last_attempt_at,omitempty"`
+	State         string        `json:"state"`
+	// NextAttemptAt is when the queue worker retries the order; it moves
+	// further out with every failed attempt.
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+}
+
+// AssignmentQueueStats covers the orders still waiting; given up ones are
+// only counted in Failed.
+type AssignmentQueueStats struct {
+	Depth            int        `json:"depth"`
+	Failed           int        `json:"failed"`
+	OldestEnqueuedAt *time.Time `json:"oldest_enqueued_at,omitempty"`
+	// TopPriority is the highest priority rank in the queue.
+	TopPriority int `json:"top_priority"`
+}
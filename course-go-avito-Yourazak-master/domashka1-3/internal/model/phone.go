@@ -0,0 +1,44 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// NormalizePhone converts a phone number to E.164. Separators are dropped,
+// a leading "00" becomes "+", and Russian numbers written as 8XXXXXXXXXX or
+// 7XXXXXXXXXX get the +7 prefix. It reports false if the result is not E.164.
+func NormalizePhone(s string) (string, bool) {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(s) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+		default:
+			return "", false
+		}
+	}
+
+	phone := b.String()
+	switch {
+	case strings.HasPrefix(phone, "+"):
+	case strings.HasPrefix(phone, "00"):
+		phone = "+" + phone[2:]
+	case len(phone) == 11 && phone[0] == '8':
+		phone = "+7" + phone[1:]
+	case len(phone) == 11 && phone[0] == '7':
+		phone = "+" + phone
+	default:
+		return "", false
+	}
+
+	if !e164.MatchString(phone) {
+		return "", false
+	}
+	return phone, true
+}
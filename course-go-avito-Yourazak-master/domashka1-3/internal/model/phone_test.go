@@ -0,0 +1,28 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	valid := map[string]string{
+		"+79123456789":       "+79123456789",
+		"+7 (912) 345-67-89": "+79123456789",
+		"89123456789":        "+79123456789",
+		"79123456789":        "+79123456789",
+		"0037444111222":      "+37444111222",
+		" +374 44 111 222 ":  "+37444111222",
+	}
+	for in, want := range valid {
+		got, ok := NormalizePhone(in)
+		assert.True(t, ok, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"", "12345", "9123456789", "+0123456789", "+7912abc6789", "+7912+3456789", "+1234567890123456"} {
+		_, ok := NormalizePhone(in)
+		assert.False(t, ok, in)
+	}
+}
@@ -0,0 +1,29 @@
+package model
+
+// Priority classes of an order. They decide how soon the order has to be
+// delivered and which waiting order gets a free courier first.
+const (
+	PriorityExpress   = "express"
+	PriorityStandard  = "standard"
+	PriorityScheduled = "scheduled"
+)
+
+// NormalizePriority maps an empty or unknown class to standard.
+func NormalizePriority(p string) string {
+	switch p {
+	case PriorityExpress, PriorityScheduled:
+		return p
+	}
+	return PriorityStandard
+}
+
+// PriorityRank orders classes for the assignment queue; higher goes first.
+func PriorityRank(p string) int {
+	switch NormalizePriority(p) {
+	case PriorityExpress:
+		return 2
+	case PriorityStandard:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePriority(t *testing.T) {
+	assert.Equal(t, PriorityExpress, NormalizePriority("express"))
+	assert.Equal(t, PriorityScheduled, NormalizePriority("scheduled"))
+	assert.Equal(t, PriorityStandard, NormalizePriority(""))
+	assert.Equal(t, PriorityStandard, NormalizePriority("urgent"))
+}
+
+func TestPriorityRank(t *testing.T) {
+	assert.Greater(t, PriorityRank(PriorityExpress), PriorityRank(PriorityStandard))
+	assert.Greater(t, PriorityRank(PriorityStandard), PriorityRank(PriorityScheduled))
+	assert.Equal(t, PriorityRank(PriorityStandard), PriorityRank(""))
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// DeliveryProof records how an order was handed over.
+type DeliveryProof struct {
+	CompletedAt  time.Time `json:"completed_at"`
+	PINVerified  bool      `json:"pin_verified"`
+	SignatureKey string    `json:"signature_key,omitempty"`
+	PhotoKey     string    `json:"photo_key,omitempty"`
+	Location     *GeoPoint `json:"location,omitempty"`
+}
+
+// HashPIN returns the stored form of the customer's handover PIN. The order
+// ID salts it so equal PINs of different orders don't match. An empty PIN
+// means the order has none.
+func HashPIN(orderID, pin string) string {
+	if pin == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(orderID + ":" + pin))
+	return hex.EncodeToString(sum[:])
+}
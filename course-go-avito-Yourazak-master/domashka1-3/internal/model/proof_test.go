@@ -0,0 +1,15 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashPIN(t *testing.T) {
+	assert.Equal(t, "", HashPIN("o1", ""))
+	assert.Equal(t, HashPIN("o1", "1234"), HashPIN("o1", "1234"))
+	assert.NotEqual(t, HashPIN("o1", "1234"), HashPIN("o2", "1234"))
+	assert.NotEqual(t, HashPIN("o1", "1234"), HashPIN("o1", "1235"))
+	assert.Len(t, HashPIN("o1", "1234"), 64)
+}
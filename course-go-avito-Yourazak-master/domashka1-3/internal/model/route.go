@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+const (
+	RouteActive    = "active"
+	RouteCompleted = "completed"
+)
+
+// Kinds of route stops. Every order on a route has a pickup stop followed,
+// somewhere later, by its dropoff stop.
+const (
+	StopPickup  = "pickup"
+	StopDropoff = "dropoff"
+)
+
+// Route is a trip of one courier serving several orders.
+type Route struct {
+	ID        int         `json:"id"`
+	CourierID int         `json:"courier_id"`
+	Status    string      `json:"status"`
+	Stops     []RouteStop `json:"stops"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type RouteStop struct {
+	Seq         int        `json:"seq"`
+	OrderID     string     `json:"order_id"`
+	Kind        string     `json:"kind"`
+	Location    *GeoPoint  `json:"location,omitempty"`
+	ETA         *time.Time `json:"eta,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// RoutePlanRequest asks for the best order of the given stops. Start
+// overrides the courier's last known position and DepartAt defaults to now.
+type RoutePlanRequest struct {
+	CourierID int         `json:"courier_id"`
+	Start     *GeoPoint   `json:"start,omitempty"`
+	DepartAt  *time.Time  `json:"depart_at,omitempty"`
+	Stops     []RouteStop `json:"stops"`
+}
+
+type RoutePlan struct {
+	CourierID     int         `json:"courier_id"`
+	TransportType string      `json:"transport_type"`
+	Stops         []RouteStop `json:"stops"`
+	FinishAt      *time.Time  `json:"finish_at,omitempty"`
+}
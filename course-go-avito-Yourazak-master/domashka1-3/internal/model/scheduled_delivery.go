@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// States of a scheduled delivery. A scheduled one waits for its window; it
+// becomes assigned once a courier takes it, or queued when nobody was free
+// at trigger time and the assignment queue took over.
+const (
+	ScheduledPending   = "scheduled"
+	ScheduledAssigned  = "assigned"
+	ScheduledQueued    = "queued"
+	ScheduledCancelled = "cancelled"
+)
+
+type ScheduledDelivery struct {
+	ID          int64         `json:"id"`
+	OrderID     string        `json:"order_id"`
+	Order       ExternalOrder `json:"order"`
+	CourierID   *int          `json:"courier_id,omitempty"`
+	DeliveryID  *int          `json:"delivery_id,omitempty"`
+	WindowStart time.Time     `json:"window_start"`
+	WindowEnd   time.Time     `json:"window_end"`
+	State       string        `json:"state"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
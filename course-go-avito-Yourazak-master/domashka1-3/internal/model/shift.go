@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+const (
+	ShiftScheduled = "scheduled"
+	ShiftActive    = "active"
+	ShiftFinished  = "finished"
+)
+
+type CourierShift struct {
+	ID        int       `json:"id"`
+	CourierID int       `json:"courier_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	State     string    `json:"state"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (s CourierShift) Contains(t time.Time) bool {
+	return !t.Before(s.StartsAt) && t.Before(s.EndsAt)
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// Actors recorded in the courier status history.
+const (
+	ActorAPI               = "api"
+	ActorEvent             = "event"
+	ActorAutoReleaseJob    = "job:auto_release"
+	ActorShiftScheduler    = "job:shift_scheduler"
+	ActorPauseScheduler    = "job:pause_scheduler"
+	ActorAssignmentQueue   = "job:assignment_queue"
+	ActorDeliveryScheduler = "job:delivery_scheduler"
+	ActorRouteBatcher      = "job:route_batcher"
+	ActorOfferExpirer      = "job:offer_expirer"
+)
+
+// StatusChange describes why a courier status is being changed. It travels
+// in the context so that the repository can record it next to the change.
+type StatusChange struct {
+	Actor   string
+	Reason  string
+	OrderID string
+}
+
+type statusChangeKey struct{}
+
+// WithStatusChange returns a context carrying c. Empty fields of c keep the
+// values already present in ctx, so the actor can be set once at the edge and
+// the reason added later by the usecase.
+func WithStatusChange(ctx context.Context, c StatusChange) context.Context {
+	prev := StatusChangeFrom(ctx)
+	if c.Actor == "" {
+		c.Actor = prev.Actor
+	}
+	if c.Reason == "" {
+		c.Reason = prev.Reason
+	}
+	if c.OrderID == "" {
+		c.OrderID = prev.OrderID
+	}
+	return context.WithValue(ctx, statusChangeKey{}, c)
+}
+
+func StatusChangeFrom(ctx context.Context) StatusChange {
+	c, _ := ctx.Value(statusChangeKey{}).(StatusChange)
+	return c
+}
+
+type StatusHistoryEntry struct {
+	ID        int64     `json:"id"`
+	CourierID int       `json:"courier_id"`
+	OldStatus *string   `json:"old_status"`
+	NewStatus string    `json:"new_status"`
+	Reason    string    `json:"reason,omitempty"`
+	Actor     string    `json:"actor"`
+	OrderID   string    `json:"order_id,omitempty"`
+	ChangedAt time.Time `json:"changed_at"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithStatusChange_KeepsOuterFields(t *testing.T) {
+	ctx := WithStatusChange(context.Background(), StatusChange{Actor: ActorAPI})
+	ctx = WithStatusChange(ctx, StatusChange{Reason: "order assigned", OrderID: "o-1"})
+
+	assert.Equal(t, StatusChange{Actor: ActorAPI, Reason: "order assigned", OrderID: "o-1"}, StatusChangeFrom(ctx))
+}
+
+func TestWithStatusChange_OverridesNonEmptyFields(t *testing.T) {
+	ctx := WithStatusChange(context.Background(), StatusChange{Actor: ActorAPI, Reason: "courier updated"})
+	ctx = WithStatusChange(ctx, StatusChange{Actor: ActorEvent})
+
+	assert.Equal(t, StatusChange{Actor: ActorEvent, Reason: "courier updated"}, StatusChangeFrom(ctx))
+}
+
+func TestStatusChangeFrom_Empty(t *testing.T) {
+	assert.Equal(t, StatusChange{}, StatusChangeFrom(context.Background()))
+}
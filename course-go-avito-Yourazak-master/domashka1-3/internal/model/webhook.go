@@ -0,0 +1,81 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookPendingChannel is the Postgres NOTIFY channel that wakes the webhook
+// dispatcher when deliveries were queued.
+const WebhookPendingChannel = "webhook_pending"
+
+// Delivery lifecycle events merchants can subscribe to.
+const (
+	WebhookEventAssigned   = "assigned"
+	WebhookEventUnassigned = "unassigned"
+	WebhookEventCompleted  = "completed"
+	WebhookEventExpired    = "expired"
+)
+
+// States of a queued webhook delivery.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	// WebhookDeliveryFailed is final: every attempt failed.
+	WebhookDeliveryFailed = "failed"
+)
+
+func ValidWebhookEvent(e string) bool {
+	switch e {
+	case WebhookEventAssigned, WebhookEventUnassigned, WebhookEventCompleted, WebhookEventExpired:
+		return true
+	}
+	return false
+}
+
+func ValidWebhookDeliveryState(s string) bool {
+	switch s {
+	case WebhookDeliveryPending, WebhookDeliveryDelivered, WebhookDeliveryFailed:
+		return true
+	}
+	return false
+}
+
+// WebhookSubscription is a merchant callback URL and the events sent to it.
+// Active is cleared automatically after too many consecutive failures.
+type WebhookSubscription struct {
+	ID         int64    `json:"id"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	// MerchantID limits the subscription to deliveries of that merchant's
+	// orders; an empty one receives events for every delivery.
+	MerchantID string `json:"merchant_id,omitempty"`
+	// Secret signs every request body; it is only shown when the
+	// subscription is created.
+	Secret              string     `json:"secret,omitempty"`
+	Active              bool       `json:"active"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// WebhookDelivery is one event queued for one subscription, with the outcome
+// of its last attempt.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscription_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	State          string          `json:"state"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatus     *int            `json:"last_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	// URL and Secret come from the subscription when the delivery is claimed
+	// for sending.
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidWebhookEvent(t *testing.T) {
+	for _, e := range []string{WebhookEventAssigned, WebhookEventUnassigned, WebhookEventCompleted, WebhookEventExpired} {
+		assert.True(t, ValidWebhookEvent(e), e)
+	}
+	assert.False(t, ValidWebhookEvent("picked_up"))
+	assert.False(t, ValidWebhookEvent(""))
+}
+
+func TestValidWebhookDeliveryState(t *testing.T) {
+	assert.True(t, ValidWebhookDeliveryState(WebhookDeliveryFailed))
+	assert.False(t, ValidWebhookDeliveryState("retrying"))
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+type DeliveryZone struct {
+	ID           int        `json:"id"`
+	Name         string     `json:"name"`
+	RegionID     *int       `json:"region_id,omitempty"`
+	Polygon      []GeoPoint `json:"polygon,omitempty"`
+	NeighbourIDs []int      `json:"neighbour_ids"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Contains reports whether p lies inside the zone polygon (ray casting).
+// Zones defined only by region never contain a point.
+func (z DeliveryZone) Contains(p GeoPoint) bool {
+	n := len(z.Polygon)
+	if n < 3 {
+		return false
+	}
+
+	inside := false
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := z.Polygon[i], z.Polygon[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lon < (b.Lon-a.Lon)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryZone_Contains(t *testing.T) {
+	zone := DeliveryZone{Polygon: []GeoPoint{
+		{Lat: 55.70, Lon: 37.50},
+		{Lat: 55.70, Lon: 37.70},
+		{Lat: 55.80, Lon: 37.70},
+		{Lat: 55.80, Lon: 37.50},
+	}}
+
+	assert.True(t, zone.Contains(GeoPoint{Lat: 55.75, Lon: 37.60}))
+	assert.False(t, zone.Contains(GeoPoint{Lat: 55.85, Lon: 37.60}))
+	assert.False(t, zone.Contains(GeoPoint{Lat: 55.75, Lon: 37.80}))
+	assert.False(t, DeliveryZone{}.Contains(GeoPoint{Lat: 55.75, Lon: 37.60}))
+}
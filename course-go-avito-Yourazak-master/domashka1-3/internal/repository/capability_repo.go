@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CapabilityRepository interface {
+	Get(ctx context.Context, courierID int) (model.CourierCapability, error)
+	Save(ctx context.Context, c *model.CourierCapability) error
+	Delete(ctx context.Context, courierID int) error
+}
+
+type capabilityRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewCapabilityRepository(pool *pgxpool.Pool) CapabilityRepository {
+	return &capabilityRepo{pool: pool}
+}
+
+func (r *capabilityRepo) Get(ctx context.Context, courierID int) (model.CourierCapability, error) {
+	c := model.CourierCapability{CourierID: courierID}
+	err := r.pool.QueryRow(ctx,
+		`SELECT max_weight_kg, max_volume_l, max_distance_km, updated_at
+		 FROM courier_capabilities WHERE courier_id = $1`, courierID).
+		Scan(&c.Limits.MaxWeightKg, &c.Limits.MaxVolumeL, &c.Limits.MaxDistanceKm, &c.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.CourierCapability{}, ErrNotFound
+		}
+		return model.CourierCapability{}, err
+	}
+	return c, nil
+}
+
+func (r *capabilityRepo) Save(ctx context.Context, c *model.CourierCapability) error {
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO courier_capabilities (courier_id, max_weight_kg, max_volume_l, max_distance_km, updated_at)
+		 VALUES ($1, $2, $3, $4, NOW())
+		 ON CONFLICT (courier_id) DO UPDATE
+		 SET max_weight_kg = EXCLUDED.max_weight_kg, max_volume_l = EXCLUDED.max_volume_l,
+		     max_distance_km = EXCLUDED.max_distance_km, updated_at = NOW()
+		 RETURNING updated_at`,
+		c.CourierID, c.Limits.MaxWeightKg, c.Limits.MaxVolumeL, c.Limits.MaxDistanceKm).Scan(&c.UpdatedAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *capabilityRepo) Delete(ctx context.Context, courierID int) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM courier_capabilities WHERE courier_id = $1`, courierID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -3,10 +3,14 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
 
 	"avito-courier/internal/model"
 
-	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -16,16 +20,23 @@ var (
 	ErrConflict         = errors.New("conflict")
 	ErrBadInput         = errors.New("bad input")
 	ErrDeliveryNotFound = errors.New("delivery not found")
+	ErrVersionMismatch  = errors.New("version mismatch")
 )
 
 type CourierRepository interface {
 	GetByID(ctx context.Context, id int) (model.Courier, error)
 	GetAll(ctx context.Context) ([]model.Courier, error)
+	Search(ctx context.Context, q model.CourierQuery) ([]model.Courier, int, error)
+	ForEach(ctx context.Context, q model.CourierQuery, fn func(model.Courier) error) error
 	Create(ctx context.Context, c *model.Courier) error
+	CreateBatch(ctx context.Context, couriers []*model.Courier, atomic bool) ([]error, error)
 	Update(ctx context.Context, c *model.Courier) error
 	FindAvailableCourier(ctx context.Context) (model.Courier, error)
 	UpdateStatus(ctx context.Context, id int, status string) error
+	GetByIDForUpdateTx(ctx context.Context, tx pgx.Tx, id int) (model.Courier, error)
 	UpdateStatusTx(ctx context.Context, tx pgx.Tx, id int, status string) error
+	ReleaseOverdue(ctx context.Context) ([]int, error)
+	FindCandidatesTx(ctx context.Context, tx pgx.Tx, f model.CandidateFilter) ([]model.CourierCandidate, error)
 }
 
 type courierRepo struct {
@@ -36,11 +47,19 @@ func NewCourierRepository(pool *pgxpool.Pool) CourierRepository {
 	return &courierRepo{pool: pool}
 }
 
+const courierColumns = `c.id, c.name, c.phone, c.status, c.transport_type,
+	COALESCE(c.email, ''), COALESCE(c.vehicle_plate, ''), c.rating, COALESCE(c.external_employee_id, ''),
+	c.version, c.created_at, c.updated_at`
+
+func scanCourier(row pgx.Row, c *model.Courier) error {
+	return row.Scan(&c.ID, &c.Name, &c.Phone, &c.Status, &c.TransportType,
+		&c.Email, &c.VehiclePlate, &c.Rating, &c.ExternalEmployeeID, &c.Version, &c.CreatedAt, &c.UpdatedAt)
+}
+
 func (r *courierRepo) GetByID(ctx context.Context, id int) (model.Courier, error) {
 	var c model.Courier
-	err := r.pool.QueryRow(ctx,
-		`SELECT id, name, phone, status, transport_type, created_at, updated_at FROM couriers WHERE id=$1`, id).
-		Scan(&c.ID, &c.Name, &c.Phone, &c.Status, &c.TransportType, &c.CreatedAt, &c.UpdatedAt)
+	err := scanCourier(r.pool.QueryRow(ctx,
+		`SELECT `+courierColumns+` FROM couriers c WHERE c.id=$1`, id), &c)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return model.Courier{}, ErrNotFound
@@ -52,7 +71,7 @@ func (r *courierRepo) GetByID(ctx context.Context, id int) (model.Courier, error
 
 func (r *courierRepo) GetAll(ctx context.Context) ([]model.Courier, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT id, name, phone, status, transport_type, created_at, updated_at FROM couriers ORDER BY id`)
+		`SELECT `+courierColumns+` FROM couriers c ORDER BY c.id`)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +80,7 @@ func (r *courierRepo) GetAll(ctx context.Context) ([]model.Courier, error) {
 	var out []model.Courier
 	for rows.Next() {
 		var c model.Courier
-		if err := rows.Scan(&c.ID, &c.Name, &c.Phone, &c.Status, &c.TransportType, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		if err := scanCourier(rows, &c); err != nil {
 			return nil, err
 		}
 		out = append(out, c)
@@ -69,40 +88,241 @@ func (r *courierRepo) GetAll(ctx context.Context) ([]model.Courier, error) {
 	return out, nil
 }
 
-func (r *courierRepo) Create(ctx context.Context, c *model.Courier) error {
-	row := r.pool.QueryRow(ctx,
-		`INSERT INTO couriers (name, phone, status, transport_type, created_at, updated_at) 
-		 VALUES ($1, $2, $3, $4, NOW(), NOW()) 
-		 RETURNING id, created_at, updated_at`,
-		c.Name, c.Phone, c.Status, c.TransportType)
-	if err := row.Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt); err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			if pgErr.Code == "23505" {
-				return ErrConflict
+var courierSortColumns = map[string]string{
+	model.CourierSortID:        "c.id",
+	model.CourierSortName:      "c.name",
+	model.CourierSortCreatedAt: "c.created_at",
+	model.CourierSortUpdatedAt: "c.updated_at",
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Search returns one page of couriers matching q together with the number of
+// couriers matching the filters regardless of paging. q.SortBy must be one of
+// the model.CourierSort* values and q.Limit positive.
+func (r *courierRepo) Search(ctx context.Context, q model.CourierQuery) ([]model.Courier, int, error) {
+	sortColumn, ok := courierSortColumns[q.SortBy]
+	if !ok || q.Limit <= 0 {
+		return nil, 0, ErrBadInput
+	}
+
+	args := []any{}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	where := courierFilter(q, arg)
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM couriers c WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	direction, cmp := "ASC", ">"
+	if q.Desc {
+		direction, cmp = "DESC", "<"
+	}
+
+	if q.After != nil {
+		if q.SortBy == model.CourierSortID {
+			where += " AND c.id " + cmp + " " + arg(q.After.ID)
+		} else {
+			var value any = q.After.Value
+			if q.SortBy != model.CourierSortName {
+				t, err := time.Parse(time.RFC3339Nano, q.After.Value)
+				if err != nil {
+					return nil, 0, ErrBadInput
+				}
+				value = t
 			}
+			where += " AND (" + sortColumn + ", c.id) " + cmp + " (" + arg(value) + ", " + arg(q.After.ID) + ")"
 		}
+	}
+
+	query := `SELECT ` + courierColumns + ` FROM couriers c WHERE ` + where +
+		` ORDER BY ` + sortColumn + ` ` + direction
+	if q.SortBy != model.CourierSortID {
+		query += `, c.id ` + direction
+	}
+	query += ` LIMIT ` + arg(q.Limit)
+	if q.Offset > 0 {
+		query += ` OFFSET ` + arg(q.Offset)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	out := []model.Courier{}
+	for rows.Next() {
+		var c model.Courier
+		if err := scanCourier(rows, &c); err != nil {
+			return nil, 0, err
+		}
+		out = append(out, c)
+	}
+	return out, total, rows.Err()
+}
+
+// ForEach streams the couriers matching the filters of q, ordered by id,
+// without loading them all into memory. Iteration stops at the first error
+// returned by fn.
+func (r *courierRepo) ForEach(ctx context.Context, q model.CourierQuery, fn func(model.Courier) error) error {
+	args := []any{}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+courierColumns+` FROM couriers c WHERE `+courierFilter(q, arg)+` ORDER BY c.id`, args...)
+	if err != nil {
 		return err
 	}
-	return nil
+	defer rows.Close()
+
+	for rows.Next() {
+		var c model.Courier
+		if err := scanCourier(rows, &c); err != nil {
+			return err
+		}
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
 }
 
-func (r *courierRepo) Update(ctx context.Context, c *model.Courier) error {
-	result, err := r.pool.Exec(ctx,
-		`UPDATE couriers 
-		 SET name = $1, phone = $2, status = $3, transport_type = $4, updated_at = NOW() 
-		 WHERE id = $5`,
-		c.Name, c.Phone, c.Status, c.TransportType, c.ID)
+func courierFilter(q model.CourierQuery, arg func(any) string) string {
+	where := "TRUE"
+	if len(q.Statuses) > 0 {
+		where += " AND c.status = ANY(" + arg(q.Statuses) + ")"
+	}
+	if len(q.TransportTypes) > 0 {
+		where += " AND c.transport_type = ANY(" + arg(q.TransportTypes) + ")"
+	}
+	if q.Search != "" {
+		pattern := arg("%" + likeEscaper.Replace(q.Search) + "%")
+		where += " AND (c.name ILIKE " + pattern + " OR c.phone LIKE " + pattern + ")"
+	}
+	if !q.CreatedFrom.IsZero() {
+		where += " AND c.created_at >= " + arg(q.CreatedFrom)
+	}
+	if !q.CreatedTo.IsZero() {
+		where += " AND c.created_at < " + arg(q.CreatedTo)
+	}
+	return where
+}
+
+// CreateBatch inserts couriers in a single transaction and returns one error
+// slot per courier. In atomic mode the first failure rolls back everything;
+// otherwise failed rows are skipped via savepoints and the rest is committed.
+func (r *courierRepo) CreateBatch(ctx context.Context, couriers []*model.Courier, atomic bool) ([]error, error) {
+	rowErrs := make([]error, len(couriers))
+
+	tx, err := r.pool.Begin(ctx)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			if pgErr.Code == "23505" {
-				return ErrConflict
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tagStatusChange(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	for i, c := range couriers {
+		sp, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		err = insertCourier(ctx, sp, c)
+		if err != nil {
+			sp.Rollback(ctx)
+			if !isUniqueViolation(err) {
+				return nil, err
+			}
+			rowErrs[i] = ErrConflict
+			if atomic {
+				return rowErrs, nil
 			}
+			continue
+		}
+		if err := sp.Commit(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return rowErrs, nil
+}
+
+func (r *courierRepo) Create(ctx context.Context, c *model.Courier) error {
+	err := withStatusChange(ctx, r.pool, func(tx pgx.Tx) error {
+		return insertCourier(ctx, tx, c)
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrConflict
 		}
 		return err
 	}
-	if result.RowsAffected() == 0 {
+	return nil
+}
+
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func insertCourier(ctx context.Context, db queryRower, c *model.Courier) error {
+	return db.QueryRow(ctx,
+		`INSERT INTO couriers (name, phone, status, transport_type, email, vehicle_plate, rating, external_employee_id, created_at, updated_at) 
+		 VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, NULLIF($8, ''), NOW(), NOW()) 
+		 RETURNING id, version, created_at, updated_at`,
+		c.Name, c.Phone, c.Status, c.TransportType, c.Email, c.VehiclePlate, c.Rating, c.ExternalEmployeeID).
+		Scan(&c.ID, &c.Version, &c.CreatedAt, &c.UpdatedAt)
+}
+
+// Update overwrites the courier and bumps its version. When c.Version is set
+// the row is only updated if it still has that version; otherwise
+// ErrVersionMismatch is returned. On success c carries the new version.
+func (r *courierRepo) Update(ctx context.Context, c *model.Courier) error {
+	err := withStatusChange(ctx, r.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx,
+			`UPDATE couriers 
+			 SET name = $1, phone = $2, status = $3, transport_type = $4,
+			     email = NULLIF($5, ''), vehicle_plate = NULLIF($6, ''), rating = $7, external_employee_id = NULLIF($8, ''),
+			     version = version + 1, updated_at = NOW() 
+			 WHERE id = $9 AND ($10 = 0 OR version = $10)
+			 RETURNING version, created_at, updated_at`,
+			c.Name, c.Phone, c.Status, c.TransportType, c.Email, c.VehiclePlate, c.Rating, c.ExternalEmployeeID, c.ID, c.Version).
+			Scan(&c.Version, &c.CreatedAt, &c.UpdatedAt)
+		if err != nil || c.Status == "paused" {
+			return err
+		}
+		// A courier taken out of pause by hand has nothing left to resume.
+		_, err = tx.Exec(ctx, `DELETE FROM courier_pauses WHERE courier_id = $1`, c.ID)
+		return err
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrConflict
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		var exists bool
+		if err := r.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM couriers WHERE id = $1)`, c.ID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return ErrVersionMismatch
+		}
 		return ErrNotFound
 	}
 	return nil
@@ -110,13 +330,18 @@ func (r *courierRepo) Update(ctx context.Context, c *model.Courier) error {
 
 func (r *courierRepo) FindAvailableCourier(ctx context.Context) (model.Courier, error) {
 	var c model.Courier
-	err := r.pool.QueryRow(ctx, `
-		SELECT id, name, phone, status, transport_type, created_at, updated_at
-		FROM couriers
-		WHERE status = 'available'
-		ORDER BY created_at ASC
+	err := scanCourier(r.pool.QueryRow(ctx, `
+		SELECT `+courierColumns+`
+		FROM couriers c
+		WHERE c.status = 'available'
+		  AND EXISTS (
+			SELECT 1 FROM courier_shifts s
+			WHERE s.courier_id = c.id
+			  AND s.starts_at <= NOW() AND s.ends_at > NOW()
+		  )
+		ORDER BY c.created_at ASC
 		LIMIT 1
-	`).Scan(&c.ID, &c.Name, &c.Phone, &c.Status, &c.TransportType, &c.CreatedAt, &c.UpdatedAt)
+	`), &c)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -127,20 +352,284 @@ func (r *courierRepo) FindAvailableCourier(ctx context.Context) (model.Courier,
 	return c, nil
 }
 
+// GetByIDForUpdateTx reads the courier and locks the row until tx ends.
+func (r *courierRepo) GetByIDForUpdateTx(ctx context.Context, tx pgx.Tx, id int) (model.Courier, error) {
+	var c model.Courier
+	err := scanCourier(tx.QueryRow(ctx,
+		`SELECT `+courierColumns+` FROM couriers c WHERE c.id = $1 FOR UPDATE`, id), &c)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Courier{}, ErrNotFound
+		}
+		return model.Courier{}, err
+	}
+	return c, nil
+}
+
 func (r *courierRepo) UpdateStatus(ctx context.Context, id int, status string) error {
-	_, err := r.pool.Exec(ctx, `
-		UPDATE couriers
-		SET status = $1, updated_at = NOW()
-		WHERE id = $2
-	`, status, id)
-	return err
+	return withStatusChange(ctx, r.pool, func(tx pgx.Tx) error {
+		return updateCourierStatus(ctx, tx, id, status)
+	})
 }
 
+// UpdateStatusTx changes the status inside tx. The change is recorded in the
+// status history with the actor and reason carried by ctx.
 func (r *courierRepo) UpdateStatusTx(ctx context.Context, tx pgx.Tx, id int, status string) error {
+	if err := tagStatusChange(ctx, tx); err != nil {
+		return err
+	}
+	return updateCourierStatus(ctx, tx, id, status)
+}
+
+// updateCourierStatus expects tx to be tagged already. A courier released
+// while a pause is pending is paused instead of becoming available.
+func updateCourierStatus(ctx context.Context, tx pgx.Tx, id int, status string) error {
+	if status == "available" {
+		var reason string
+		err := tx.QueryRow(ctx, `
+			UPDATE courier_pauses
+			SET state = 'active', paused_at = NOW()
+			WHERE courier_id = $1 AND state = 'pending'
+			RETURNING reason
+		`, id).Scan(&reason)
+		switch {
+		case err == nil:
+			status = "paused"
+			ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: "paused after delivery: " + reason})
+			if err := tagStatusChange(ctx, tx); err != nil {
+				return err
+			}
+		case !errors.Is(err, pgx.ErrNoRows):
+			return err
+		}
+	}
+
 	_, err := tx.Exec(ctx, `
 		UPDATE couriers
-		SET status = $1, updated_at = NOW()
+		SET status = $1, version = version + 1, updated_at = NOW()
 		WHERE id = $2
 	`, status, id)
 	return err
 }
+
+// ReleaseOverdue expires the active deliveries that have passed their
+// deadline and makes their couriers available again once they have no other
+// active delivery left; routes left without one are completed. It returns
+// the released couriers' IDs. Each release is tagged with an expired order
+// so that it shows up in the courier's status history.
+func (r *courierRepo) ReleaseOverdue(ctx context.Context) ([]int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		WITH overdue AS (
+			SELECT d.id
+			FROM deliveries d
+			JOIN couriers c ON c.id = d.courier_id
+			WHERE c.status = 'busy' AND d.status IN ('assigned', 'picked_up') AND d.deadline < NOW()
+			FOR UPDATE OF c, d SKIP LOCKED
+		)
+		UPDATE deliveries d
+		SET status = 'expired', updated_at = NOW()
+		FROM overdue
+		WHERE d.id = overdue.id
+		RETURNING d.courier_id, d.order_id, d.route_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	var couriers, routes []int
+	orders := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var orderID string
+		var routeID *int
+		if err := rows.Scan(&id, &orderID, &routeID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if _, ok := orders[id]; !ok {
+			orders[id] = orderID
+			couriers = append(couriers, id)
+		}
+		if routeID != nil {
+			routes = append(routes, *routeID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(routes) > 0 {
+		if _, err := tx.Exec(ctx, `
+			UPDATE routes r SET status = 'completed', updated_at = NOW()
+			WHERE r.id = ANY($1) AND r.status = 'active'
+			  AND NOT EXISTS (SELECT 1 FROM deliveries WHERE route_id = r.id AND status IN ('assigned', 'picked_up'))
+		`, routes); err != nil {
+			return nil, err
+		}
+	}
+
+	var released []int
+	for _, id := range couriers {
+		var active bool
+		if err := tx.QueryRow(ctx,
+			`SELECT EXISTS(SELECT 1 FROM deliveries WHERE courier_id = $1 AND status IN ('assigned', 'picked_up'))`,
+			id).Scan(&active); err != nil {
+			return nil, err
+		}
+		if active {
+			continue
+		}
+		if err := r.UpdateStatusTx(model.WithStatusChange(ctx, model.StatusChange{OrderID: orders[id]}), tx, id, "available"); err != nil {
+			return nil, err
+		}
+		released = append(released, id)
+	}
+	return released, tx.Commit(ctx)
+}
+
+// FindCandidatesTx locks and returns available couriers inside an active
+// shift that are not reserved by a pending offer. When f.Near is set,
+// couriers with a fresh position outside the bounding box of f.RadiusKm
+// are left out; exact distance is left to the caller. Couriers without a
+// fresh position come after the located ones, with no Location. Rows
+// locked by concurrent assignments are skipped.
+func (r *courierRepo) FindCandidatesTx(ctx context.Context, tx pgx.Tx, f model.CandidateFilter) ([]model.CourierCandidate, error) {
+	if f.Limit <= 0 {
+		f.Limit = 1
+	}
+
+	args := []any{}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	query := `
+		SELECT ` + courierColumns + `,
+		       l.lat, l.lon, l.accuracy_m, l.recorded_at,
+		       COALESCE(cap.max_weight_kg, 0), COALESCE(cap.max_volume_l, 0), COALESCE(cap.max_distance_km, 0)
+		FROM couriers c
+		LEFT JOIN courier_locations l ON l.courier_id = c.id
+		LEFT JOIN courier_capabilities cap ON cap.courier_id = c.id
+		WHERE c.status = 'available'
+		  AND EXISTS (
+			SELECT 1 FROM courier_shifts s
+			WHERE s.courier_id = c.id
+			  AND s.starts_at <= NOW() AND s.ends_at > NOW()
+		  )
+		  AND NOT EXISTS (
+			SELECT 1 FROM delivery_offers o
+			WHERE o.courier_id = c.id AND o.state = 'pending'
+		  )`
+	orderBy := `c.created_at ASC`
+
+	if len(f.ExcludeIDs) > 0 {
+		query += `
+		  AND c.id <> ALL(` + arg(f.ExcludeIDs) + `)`
+	}
+
+	if len(f.ZoneIDs) > 0 {
+		query += `
+		  AND EXISTS (
+			SELECT 1 FROM courier_zones cz
+			WHERE cz.courier_id = c.id AND cz.zone_id = ANY(` + arg(f.ZoneIDs) + `)
+		  )`
+	}
+
+	if f.MinWeightKg > 0 {
+		query += `
+		  AND ` + capacityCondition("max_weight_kg", f.MinWeightKg, f.TransportLimits,
+			func(l model.TransportLimits) float64 { return l.MaxWeightKg }, arg)
+	}
+	if f.MinVolumeL > 0 {
+		query += `
+		  AND ` + capacityCondition("max_volume_l", f.MinVolumeL, f.TransportLimits,
+			func(l model.TransportLimits) float64 { return l.MaxVolumeL }, arg)
+	}
+
+	var freshSince time.Time
+	if f.Near != nil {
+		freshSince = time.Now().Add(-f.MaxLocationAge)
+		minLat, maxLat, minLon, maxLon := f.Near.BoundingBox(f.RadiusKm)
+		fresh := `(l.recorded_at >= ` + arg(freshSince) + `)`
+		query += `
+		  AND (` + fresh + ` IS NOT TRUE OR (
+			l.lat BETWEEN ` + arg(minLat) + ` AND ` + arg(maxLat) + `
+			AND l.lon BETWEEN ` + arg(minLon) + ` AND ` + arg(maxLon) + `))`
+		// equirectangular approximation, good enough to rank inside the box;
+		// couriers with no fresh position sort last
+		lat, lon, k := arg(f.Near.Lat), arg(f.Near.Lon), arg(math.Cos(f.Near.Lat*math.Pi/180))
+		orderBy = fmt.Sprintf(`CASE WHEN %[4]s THEN (l.lat - %[1]s) * (l.lat - %[1]s) + ((l.lon - %[2]s) * %[3]s) * ((l.lon - %[2]s) * %[3]s) END ASC NULLS LAST, c.created_at ASC`,
+			lat, lon, k, fresh)
+	}
+
+	query += `
+		ORDER BY ` + orderBy + `
+		LIMIT ` + arg(f.Limit) + `
+		FOR UPDATE OF c SKIP LOCKED`
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.CourierCandidate
+	for rows.Next() {
+		var c model.Courier
+		var overrides model.TransportLimits
+		var lat, lon, accuracy *float64
+		var recordedAt *time.Time
+		if err := rows.Scan(&c.ID, &c.Name, &c.Phone, &c.Status, &c.TransportType,
+			&c.Email, &c.VehiclePlate, &c.Rating, &c.ExternalEmployeeID, &c.Version, &c.CreatedAt, &c.UpdatedAt,
+			&lat, &lon, &accuracy, &recordedAt,
+			&overrides.MaxWeightKg, &overrides.MaxVolumeL, &overrides.MaxDistanceKm); err != nil {
+			return nil, err
+		}
+
+		candidate := model.CourierCandidate{Courier: c, Overrides: overrides}
+		if lat != nil && lon != nil && recordedAt != nil && !recordedAt.Before(freshSince) {
+			candidate.Location = &model.CourierLocation{
+				CourierID:  c.ID,
+				Lat:        *lat,
+				Lon:        *lon,
+				AccuracyM:  *accuracy,
+				RecordedAt: *recordedAt,
+			}
+		}
+		out = append(out, candidate)
+	}
+	return out, rows.Err()
+}
+
+// capacityCondition builds a predicate requiring the courier's effective limit
+// on column to be at least min. The per-courier override wins; otherwise the
+// limit of the transport type applies. Zero means unlimited on both levels.
+func capacityCondition(column string, min float64, limits map[string]model.TransportLimits,
+	pick func(model.TransportLimits) float64, arg func(any) string) string {
+	transports := make([]string, 0, len(limits))
+	for transport := range limits {
+		transports = append(transports, transport)
+	}
+	sort.Strings(transports)
+
+	whens := ""
+	for _, transport := range transports {
+		if v := pick(limits[transport]); v > 0 {
+			whens += " WHEN " + arg(transport) + " THEN " + arg(v) + "::double precision"
+		}
+	}
+	byType := "0"
+	if whens != "" {
+		byType = "CASE c.transport_type" + whens + " ELSE 0 END"
+	}
+	effective := "COALESCE(NULLIF(cap." + column + ", 0), " + byType + ")"
+	return "(" + effective + " = 0 OR " + effective + " >= " + arg(min) + ")"
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CursorRepository interface {
+	Get(ctx context.Context, name string) (time.Time, error)
+	Save(ctx context.Context, name string, cursor time.Time) error
+}
+
+type cursorRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewCursorRepository(pool *pgxpool.Pool) CursorRepository {
+	return &cursorRepo{pool: pool}
+}
+
+func (r *cursorRepo) Get(ctx context.Context, name string) (time.Time, error) {
+	var cursor time.Time
+	err := r.pool.QueryRow(ctx,
+		`SELECT cursor_at FROM poller_cursors WHERE name = $1`, name).
+		Scan(&cursor)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, ErrNotFound
+		}
+		return time.Time{}, err
+	}
+	return cursor, nil
+}
+
+func (r *cursorRepo) Save(ctx context.Context, name string, cursor time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO poller_cursors (name, cursor_at, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (name) DO UPDATE
+		SET cursor_at = GREATEST(poller_cursors.cursor_at, EXCLUDED.cursor_at),
+		    updated_at = NOW()
+	`, name, cursor)
+	return err
+}
@@ -16,9 +16,19 @@ type DeliveryRepository interface {
 	CreateTx(ctx context.Context, tx pgx.Tx, d *model.Delivery) error
 	GetByOrderID(ctx context.Context, orderID string) (model.Delivery, error)
 	GetByOrderIDTx(ctx context.Context, tx pgx.Tx, orderID string) (model.Delivery, error)
+	GetByIDForUpdateTx(ctx context.Context, tx pgx.Tx, id int) (model.Delivery, error)
+	GetByID(ctx context.Context, id int) (model.Delivery, error)
+	// List returns the deliveries matching q, newest first.
+	List(ctx context.Context, q model.DeliveryQuery) ([]model.Delivery, error)
+	ReassignTx(ctx context.Context, tx pgx.Tx, d *model.Delivery, fromCourierID int, reason string) error
+	CompleteTx(ctx context.Context, tx pgx.Tx, id int, proof *model.DeliveryProof) error
+	CountAttemptsTx(ctx context.Context, tx pgx.Tx, orderID string) (int, error)
+	RecordAttemptTx(ctx context.Context, tx pgx.Tx, a *model.DeliveryAttempt) error
+	PickUpTx(ctx context.Context, tx pgx.Tx, d *model.Delivery) error
+	ListActiveByCourier(ctx context.Context, courierID int) ([]model.Delivery, error)
 	UpdateStatus(ctx context.Context, orderID, status string) error
+	UpdateStatusTx(ctx context.Context, tx pgx.Tx, orderID, status string) error
 	DeleteByOrderID(ctx context.Context, orderID string) error
-	ReleaseExpired(ctx context.Context, before time.Time) ([]string, error)
 
 	CheckOrderExistsTx(ctx context.Context, tx pgx.Tx, orderID string) (bool, error)
 	DeleteByOrderIDTx(ctx context.Context, tx pgx.Tx, orderID string) (int, error)
@@ -43,6 +53,7 @@ func (r *deliveryRepo) Create(ctx context.Context, d *model.Delivery) error {
 		return err
 	}
 
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: "delivery assigned", OrderID: d.OrderID})
 	if err := r.updateCourierStatusTx(ctx, tx, d.CourierID, "busy"); err != nil {
 		return err
 	}
@@ -52,52 +63,278 @@ func (r *deliveryRepo) Create(ctx context.Context, d *model.Delivery) error {
 
 func (r *deliveryRepo) CreateTx(ctx context.Context, tx pgx.Tx, d *model.Delivery) error {
 	return tx.QueryRow(ctx,
-		`INSERT INTO deliveries (order_id, courier_id, status, created_at, updated_at, assigned_at)
-		 VALUES ($1, $2, $3, NOW(), NOW(), NOW())
-		 RETURNING id, created_at, updated_at, assigned_at`,
-		d.OrderID, d.CourierID, "assigned").
-		Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt, &d.AssignedAt)
+		`INSERT INTO deliveries (order_id, courier_id, status, created_at, updated_at, assigned_at, deadline, priority, window_start, window_end, route_id, eta, pin_hash, kind, merchant_id)
+		 VALUES ($1, $2, $3, NOW(), NOW(), NOW(), $4, $5, $6, $7, $8, $9, NULLIF($10, ''), COALESCE(NULLIF($11, ''), 'delivery'), NULLIF($12, ''))
+		 RETURNING id, created_at, updated_at, assigned_at, kind`,
+		d.OrderID, d.CourierID, "assigned", d.Deadline, model.NormalizePriority(d.Priority), d.WindowStart, d.WindowEnd, d.RouteID, d.ETA, d.PINHash, d.Kind, d.MerchantID).
+		Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt, &d.AssignedAt, &d.Kind)
 }
 
 func (r *deliveryRepo) updateCourierStatusTx(ctx context.Context, tx pgx.Tx, courierID int, status string) error {
-	_, err := tx.Exec(ctx,
-		`UPDATE couriers SET status=$1, updated_at=NOW() WHERE id=$2`,
-		status, courierID)
-	return err
+	if err := tagStatusChange(ctx, tx); err != nil {
+		return err
+	}
+	return updateCourierStatus(ctx, tx, courierID, status)
 }
 
 func (r *deliveryRepo) GetByOrderID(ctx context.Context, orderID string) (model.Delivery, error) {
 	var d model.Delivery
+	var proof proofRow
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, order_id, courier_id, status, created_at, updated_at, assigned_at
+		`SELECT id, order_id, courier_id, status, created_at, updated_at, assigned_at, route_id, eta, kind, `+proofColumns+`
 		 FROM deliveries WHERE order_id=$1`,
 		orderID).
-		Scan(&d.ID, &d.OrderID, &d.CourierID, &d.Status, &d.CreatedAt, &d.UpdatedAt, &d.AssignedAt)
+		Scan(append([]any{&d.ID, &d.OrderID, &d.CourierID, &d.Status, &d.CreatedAt, &d.UpdatedAt, &d.AssignedAt, &d.RouteID, &d.ETA, &d.Kind}, proof.dest()...)...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return model.Delivery{}, errors.New("delivery not found")
+			return model.Delivery{}, ErrDeliveryNotFound
 		}
 		return model.Delivery{}, err
 	}
+	d.Proof = proof.model()
 	return d, nil
 }
 
 func (r *deliveryRepo) GetByOrderIDTx(ctx context.Context, tx pgx.Tx, orderID string) (model.Delivery, error) {
 	var d model.Delivery
+	var proof proofRow
 	err := tx.QueryRow(ctx,
-		`SELECT id, order_id, courier_id, status, created_at, updated_at, assigned_at
+		`SELECT id, order_id, courier_id, status, created_at, updated_at, assigned_at, route_id, eta, kind, `+proofColumns+`
 		 FROM deliveries WHERE order_id=$1`,
 		orderID).
-		Scan(&d.ID, &d.OrderID, &d.CourierID, &d.Status, &d.CreatedAt, &d.UpdatedAt, &d.AssignedAt)
+		Scan(append([]any{&d.ID, &d.OrderID, &d.CourierID, &d.Status, &d.CreatedAt, &d.UpdatedAt, &d.AssignedAt, &d.RouteID, &d.ETA, &d.Kind}, proof.dest()...)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Delivery{}, ErrDeliveryNotFound
+		}
+		return model.Delivery{}, err
+	}
+	d.Proof = proof.model()
+	return d, nil
+}
+
+// GetByIDForUpdateTx reads the delivery and locks it until tx ends.
+func (r *deliveryRepo) GetByIDForUpdateTx(ctx context.Context, tx pgx.Tx, id int) (model.Delivery, error) {
+	var d model.Delivery
+	var proof proofRow
+	err := tx.QueryRow(ctx,
+		`SELECT id, order_id, courier_id, status, created_at, updated_at, assigned_at, deadline, priority, window_start, window_end, route_id, eta, picked_up_at, kind, COALESCE(pin_hash, ''), COALESCE(merchant_id, ''), `+proofColumns+`
+		 FROM deliveries WHERE id=$1 FOR UPDATE`,
+		id).
+		Scan(append([]any{&d.ID, &d.OrderID, &d.CourierID, &d.Status, &d.CreatedAt, &d.UpdatedAt, &d.AssignedAt, &d.Deadline, &d.Priority, &d.WindowStart, &d.WindowEnd, &d.RouteID, &d.ETA, &d.PickedUpAt, &d.Kind, &d.PINHash, &d.MerchantID}, proof.dest()...)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Delivery{}, ErrDeliveryNotFound
+		}
+		return model.Delivery{}, err
+	}
+	d.Proof = proof.model()
+	return d, nil
+}
+
+// deliveryColumns are the columns scanned by scanDelivery.
+const deliveryColumns = `id, order_id, courier_id, status, created_at, updated_at, assigned_at, deadline, priority, window_start, window_end, route_id, eta, picked_up_at, kind, COALESCE(merchant_id, ''), ` + proofColumns
+
+func scanDelivery(row pgx.Row, d *model.Delivery) error {
+	var proof proofRow
+	dest := []any{&d.ID, &d.OrderID, &d.CourierID, &d.Status, &d.CreatedAt, &d.UpdatedAt, &d.AssignedAt, &d.Deadline, &d.Priority, &d.WindowStart, &d.WindowEnd, &d.RouteID, &d.ETA, &d.PickedUpAt, &d.Kind, &d.MerchantID}
+	if err := row.Scan(append(dest, proof.dest()...)...); err != nil {
+		return err
+	}
+	d.Proof = proof.model()
+	return nil
+}
+
+func (r *deliveryRepo) GetByID(ctx context.Context, id int) (model.Delivery, error) {
+	var d model.Delivery
+	err := scanDelivery(r.pool.QueryRow(ctx, `SELECT `+deliveryColumns+` FROM deliveries WHERE id=$1`, id), &d)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return model.Delivery{}, errors.New("delivery not found")
+			return model.Delivery{}, ErrDeliveryNotFound
 		}
 		return model.Delivery{}, err
 	}
 	return d, nil
 }
 
+func (r *deliveryRepo) List(ctx context.Context, q model.DeliveryQuery) ([]model.Delivery, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+deliveryColumns+`
+		 FROM deliveries
+		 WHERE ($1 = 0 OR courier_id = $1)
+		   AND ($2 = '' OR order_id = $2)
+		   AND (COALESCE(cardinality($3::text[]), 0) = 0 OR status = ANY($3))
+		 ORDER BY id DESC
+		 LIMIT $4 OFFSET $5`,
+		q.CourierID, q.OrderID, q.Statuses, q.Limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.Delivery{}
+	for rows.Next() {
+		var d model.Delivery
+		if err := scanDelivery(rows, &d); err != nil {
+			return nil, err
+		}
+		items = append(items, d)
+	}
+	return items, rows.Err()
+}
+
+// proofColumns are the proof-of-delivery columns scanned by proofRow.
+const proofColumns = `completed_at, pin_verified, signature_key, photo_key, completion_lat, completion_lon`
+
+// proofRow holds the nullable proof-of-delivery columns of a delivery.
+type proofRow struct {
+	completedAt  *time.Time
+	pinVerified  bool
+	signatureKey *string
+	photoKey     *string
+	lat, lon     *float64
+}
+
+func (p *proofRow) dest() []any {
+	return []any{&p.completedAt, &p.pinVerified, &p.signatureKey, &p.photoKey, &p.lat, &p.lon}
+}
+
+// model returns nil until the delivery has been completed with proof.
+func (p *proofRow) model() *model.DeliveryProof {
+	if p.completedAt == nil {
+		return nil
+	}
+	proof := &model.DeliveryProof{CompletedAt: *p.completedAt, PINVerified: p.pinVerified}
+	if p.signatureKey != nil {
+		proof.SignatureKey = *p.signatureKey
+	}
+	if p.photoKey != nil {
+		proof.PhotoKey = *p.photoKey
+	}
+	if p.lat != nil && p.lon != nil {
+		proof.Location = &model.GeoPoint{Lat: *p.lat, Lon: *p.lon}
+	}
+	return proof
+}
+
+// CompleteTx marks the delivery completed and stores its proof.
+func (r *deliveryRepo) CompleteTx(ctx context.Context, tx pgx.Tx, id int, proof *model.DeliveryProof) error {
+	var lat, lon *float64
+	if proof.Location != nil {
+		lat, lon = &proof.Location.Lat, &proof.Location.Lon
+	}
+	tag, err := tx.Exec(ctx,
+		`UPDATE deliveries
+		 SET status='completed', completed_at=$2, pin_verified=$3,
+		     signature_key=NULLIF($4, ''), photo_key=NULLIF($5, ''),
+		     completion_lat=$6, completion_lon=$7, updated_at=NOW()
+		 WHERE id=$1`,
+		id, proof.CompletedAt, proof.PINVerified, proof.SignatureKey, proof.PhotoKey, lat, lon)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDeliveryNotFound
+	}
+	return nil
+}
+
+// PickUpTx records that the courier has collected the order.
+func (r *deliveryRepo) PickUpTx(ctx context.Context, tx pgx.Tx, d *model.Delivery) error {
+	err := tx.QueryRow(ctx,
+		`UPDATE deliveries SET status='picked_up', picked_up_at=NOW(), updated_at=NOW()
+		 WHERE id=$1
+		 RETURNING status, picked_up_at, updated_at`,
+		d.ID).
+		Scan(&d.Status, &d.PickedUpAt, &d.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrDeliveryNotFound
+	}
+	return err
+}
+
+// ListActiveByCourier returns the courier's assigned and picked up
+// deliveries, oldest first.
+func (r *deliveryRepo) ListActiveByCourier(ctx context.Context, courierID int) ([]model.Delivery, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, order_id, courier_id, status, created_at, updated_at, assigned_at, deadline, priority, window_start, window_end, route_id, eta, picked_up_at, kind
+		 FROM deliveries
+		 WHERE courier_id=$1 AND status IN ('assigned', 'picked_up')
+		 ORDER BY assigned_at, id`,
+		courierID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.Delivery{}
+	for rows.Next() {
+		var d model.Delivery
+		if err := rows.Scan(&d.ID, &d.OrderID, &d.CourierID, &d.Status, &d.CreatedAt, &d.UpdatedAt, &d.AssignedAt, &d.Deadline, &d.Priority, &d.WindowStart, &d.WindowEnd, &d.RouteID, &d.ETA, &d.PickedUpAt, &d.Kind); err != nil {
+			return nil, err
+		}
+		items = append(items, d)
+	}
+	return items, rows.Err()
+}
+
+// CountAttemptsTx returns how many failed attempts the order has had.
+func (r *deliveryRepo) CountAttemptsTx(ctx context.Context, tx pgx.Tx, orderID string) (int, error) {
+	var n int
+	err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM delivery_attempts WHERE order_id = $1`, orderID).Scan(&n)
+	return n, err
+}
+
+// RecordAttemptTx stores a failed attempt together with the actor from ctx.
+func (r *deliveryRepo) RecordAttemptTx(ctx context.Context, tx pgx.Tx, a *model.DeliveryAttempt) error {
+	a.Actor = model.StatusChangeFrom(ctx).Actor
+	if a.Actor == "" {
+		a.Actor = "system"
+	}
+	var lat, lon *float64
+	if a.Location != nil {
+		lat, lon = &a.Location.Lat, &a.Location.Lon
+	}
+	err := tx.QueryRow(ctx,
+		`INSERT INTO delivery_attempts (order_id, delivery_id, courier_id, attempt, reason, comment, outcome, lat, lon, actor)
+		 VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, $10)
+		 RETURNING id, created_at`,
+		a.OrderID, a.DeliveryID, a.CourierID, a.Attempt, a.Reason, a.Comment, a.Outcome, lat, lon, a.Actor).
+		Scan(&a.ID, &a.CreatedAt)
+	if isUniqueViolation(err) {
+		return ErrConflict
+	}
+	return err
+}
+
+// ReassignTx moves the delivery to d.CourierID with d.AssignedAt and
+// d.Deadline, and records the move together with the actor from ctx.
+// Courier statuses are left to the caller.
+func (r *deliveryRepo) ReassignTx(ctx context.Context, tx pgx.Tx, d *model.Delivery, fromCourierID int, reason string) error {
+	err := tx.QueryRow(ctx,
+		`UPDATE deliveries SET courier_id=$1, assigned_at=$2, deadline=$3, updated_at=NOW()
+		 WHERE id=$4
+		 RETURNING updated_at`,
+		d.CourierID, d.AssignedAt, d.Deadline, d.ID).
+		Scan(&d.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrDeliveryNotFound
+		}
+		return err
+	}
+
+	actor := model.StatusChangeFrom(ctx).Actor
+	if actor == "" {
+		actor = "system"
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO delivery_reassignments (delivery_id, order_id, from_courier_id, to_courier_id, actor, reason)
+		 VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))`,
+		d.ID, d.OrderID, fromCourierID, d.CourierID, actor, reason)
+	return err
+}
+
 func (r *deliveryRepo) UpdateStatus(ctx context.Context, orderID, status string) error {
 	result, err := r.pool.Exec(ctx,
 		`UPDATE deliveries SET status=$1, updated_at=NOW() WHERE order_id=$2`,
@@ -106,7 +343,20 @@ func (r *deliveryRepo) UpdateStatus(ctx context.Context, orderID, status string)
 		return err
 	}
 	if result.RowsAffected() == 0 {
-		return errors.New("delivery not found")
+		return ErrDeliveryNotFound
+	}
+	return nil
+}
+
+func (r *deliveryRepo) UpdateStatusTx(ctx context.Context, tx pgx.Tx, orderID, status string) error {
+	result, err := tx.Exec(ctx,
+		`UPDATE deliveries SET status=$1, updated_at=NOW() WHERE order_id=$2`,
+		status, orderID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrDeliveryNotFound
 	}
 	return nil
 }
@@ -124,7 +374,7 @@ func (r *deliveryRepo) DeleteByOrderID(ctx context.Context, orderID string) erro
 		orderID).Scan(&courierID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return errors.New("delivery not found")
+			return ErrDeliveryNotFound
 		}
 		return err
 	}
@@ -136,9 +386,10 @@ func (r *deliveryRepo) DeleteByOrderID(ctx context.Context, orderID string) erro
 		return err
 	}
 	if result.RowsAffected() == 0 {
-		return errors.New("delivery not found")
+		return ErrDeliveryNotFound
 	}
 
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: "delivery deleted", OrderID: orderID})
 	if err := r.updateCourierStatusTx(ctx, tx, courierID, "available"); err != nil {
 		return err
 	}
@@ -146,55 +397,6 @@ func (r *deliveryRepo) DeleteByOrderID(ctx context.Context, orderID string) erro
 	return tx.Commit(ctx)
 }
 
-func (r *deliveryRepo) ReleaseExpired(ctx context.Context, before time.Time) ([]string, error) {
-	tx, err := r.pool.Begin(ctx)
-	if err != nil {
-		return nil, err
-	}
-	defer tx.Rollback(ctx)
-
-	rows, err := tx.Query(ctx,
-		`SELECT order_id, courier_id FROM deliveries 
-		 WHERE status='assigned' AND assigned_at < $1`,
-		before)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var orderIDs []string
-	var courierIDs []int
-	for rows.Next() {
-		var orderID string
-		var courierID int
-		if err := rows.Scan(&orderID, &courierID); err != nil {
-			return nil, err
-		}
-		orderIDs = append(orderIDs, orderID)
-		courierIDs = append(courierIDs, courierID)
-	}
-
-	if len(orderIDs) == 0 {
-		return nil, tx.Commit(ctx)
-	}
-
-	_, err = tx.Exec(ctx,
-		`UPDATE deliveries SET status='expired', updated_at=NOW() 
-		 WHERE status='assigned' AND assigned_at < $1`,
-		before)
-	if err != nil {
-		return nil, err
-	}
-
-	for _, courierID := range courierIDs {
-		if err := r.updateCourierStatusTx(ctx, tx, courierID, "available"); err != nil {
-			return nil, err
-		}
-	}
-
-	return orderIDs, tx.Commit(ctx)
-}
-
 func (r *deliveryRepo) CheckOrderExistsTx(ctx context.Context, tx pgx.Tx, orderID string) (bool, error) {
 	var exists bool
 	err := tx.QueryRow(ctx,
@@ -211,7 +413,7 @@ func (r *deliveryRepo) DeleteByOrderIDTx(ctx context.Context, tx pgx.Tx, orderID
 		orderID).Scan(&courierID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, errors.New("delivery not found")
+			return 0, ErrDeliveryNotFound
 		}
 		return 0, err
 	}
@@ -224,7 +426,7 @@ func (r *deliveryRepo) DeleteByOrderIDTx(ctx context.Context, tx pgx.Tx, orderID
 	}
 
 	if result.RowsAffected() == 0 {
-		return 0, errors.New("delivery not found")
+		return 0, ErrDeliveryNotFound
 	}
 
 	return courierID, nil
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LocationRepository interface {
+	Save(ctx context.Context, loc *model.CourierLocation) error
+	GetLatest(ctx context.Context, courierID int) (model.CourierLocation, error)
+}
+
+type locationRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewLocationRepository(pool *pgxpool.Pool) LocationRepository {
+	return &locationRepo{pool: pool}
+}
+
+// Save appends the position to the history and replaces the latest position
+// unless a newer one has already been stored (reports can arrive out of order).
+func (r *locationRepo) Save(ctx context.Context, loc *model.CourierLocation) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO courier_location_history (courier_id, lat, lon, accuracy_m, recorded_at, created_at)
+		 VALUES ($1, $2, $3, $4, $5, NOW())`,
+		loc.CourierID, loc.Lat, loc.Lon, loc.AccuracyM, loc.RecordedAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO courier_locations (courier_id, lat, lon, accuracy_m, recorded_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, NOW())
+		 ON CONFLICT (courier_id) DO UPDATE
+		 SET lat = EXCLUDED.lat, lon = EXCLUDED.lon, accuracy_m = EXCLUDED.accuracy_m,
+		     recorded_at = EXCLUDED.recorded_at, updated_at = NOW()
+		 WHERE courier_locations.recorded_at <= EXCLUDED.recorded_at`,
+		loc.CourierID, loc.Lat, loc.Lon, loc.AccuracyM, loc.RecordedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *locationRepo) GetLatest(ctx context.Context, courierID int) (model.CourierLocation, error) {
+	var loc model.CourierLocation
+	err := r.pool.QueryRow(ctx,
+		`SELECT courier_id, lat, lon, accuracy_m, recorded_at FROM courier_locations WHERE courier_id = $1`,
+		courierID).
+		Scan(&loc.CourierID, &loc.Lat, &loc.Lon, &loc.AccuracyM, &loc.RecordedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.CourierLocation{}, ErrNotFound
+		}
+		return model.CourierLocation{}, err
+	}
+	return loc, nil
+}
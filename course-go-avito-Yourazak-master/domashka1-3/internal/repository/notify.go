@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const listenRetryDelay = 5 * time.Second
+
+// Listen calls fn with the payload of every NOTIFY on channel until ctx is
+// done. It holds a dedicated pooled connection and reconnects after errors;
+// notifications sent while reconnecting are lost, so callers should not rely
+// on them alone.
+func Listen(ctx context.Context, pool *pgxpool.Pool, channel string, fn func(payload string)) {
+	for ctx.Err() == nil {
+		if err := listenOnce(ctx, pool, channel, fn); err != nil && ctx.Err() == nil {
+			log.Printf("Listen %s: %v, retrying in %v", channel, err, listenRetryDelay)
+			select {
+			case <-ctx.Done():
+			case <-time.After(listenRetryDelay):
+			}
+		}
+	}
+}
+
+func listenOnce(ctx context.Context, pool *pgxpool.Pool, channel string, fn func(payload string)) error {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	defer conn.Exec(context.Background(), "UNLISTEN *")
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+	for {
+		n, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		fn(n.Payload)
+	}
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OfferRepository interface {
+	CreateTx(ctx context.Context, tx pgx.Tx, o *model.DeliveryOffer) error
+	PendingForOrderTx(ctx context.Context, tx pgx.Tx, orderID string) (bool, error)
+	OfferedCouriersTx(ctx context.Context, tx pgx.Tx, orderID string, since time.Time) ([]int, error)
+	GetForUpdateTx(ctx context.Context, tx pgx.Tx, id int64) (model.DeliveryOffer, error)
+	RespondTx(ctx context.Context, tx pgx.Tx, o *model.DeliveryOffer, state string) error
+	ExpireDue(ctx context.Context, now time.Time, limit int) ([]model.DeliveryOffer, error)
+	ListPendingByCourier(ctx context.Context, courierID int) ([]model.DeliveryOffer, error)
+	Cancel(ctx context.Context, orderID string) (bool, error)
+	Stats(ctx context.Context, courierID int) (model.OfferStats, error)
+}
+
+type offerRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewOfferRepository(pool *pgxpool.Pool) OfferRepository {
+	return &offerRepo{pool: pool}
+}
+
+const offerColumns = `id, order_id, courier_id, payload, state, expires_at, responded_at, created_at`
+
+func scanOffer(row pgx.Row, o *model.DeliveryOffer) error {
+	var payload []byte
+	if err := row.Scan(&o.ID, &o.OrderID, &o.CourierID, &payload, &o.State, &o.ExpiresAt, &o.RespondedAt, &o.CreatedAt); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(payload, &o.Order); err != nil {
+		return err
+	}
+	o.Pickup, o.Dropoff, o.Priority = o.Order.Pickup, o.Order.Dropoff, o.Order.Priority
+	return nil
+}
+
+// CreateTx stores a pending offer. ErrConflict means the order is already
+// offered to someone or the courier already holds an offer.
+func (r *offerRepo) CreateTx(ctx context.Context, tx pgx.Tx, o *model.DeliveryOffer) error {
+	payload, err := json.Marshal(o.Order)
+	if err != nil {
+		return err
+	}
+	err = scanOffer(tx.QueryRow(ctx, `
+		INSERT INTO delivery_offers (order_id, courier_id, payload, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+offerColumns,
+		o.OrderID, o.CourierID, payload, o.ExpiresAt), o)
+	if isUniqueViolation(err) {
+		return ErrConflict
+	}
+	if isForeignKeyViolation(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *offerRepo) PendingForOrderTx(ctx context.Context, tx pgx.Tx, orderID string) (bool, error) {
+	var pending bool
+	err := tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM delivery_offers WHERE order_id = $1 AND state = 'pending')`,
+		orderID).Scan(&pending)
+	return pending, err
+}
+
+// OfferedCouriersTx returns the couriers the order was offered to since the
+// given time, whatever they answered.
+func (r *offerRepo) OfferedCouriersTx(ctx context.Context, tx pgx.Tx, orderID string, since time.Time) ([]int, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT DISTINCT courier_id FROM delivery_offers WHERE order_id = $1 AND created_at >= $2`,
+		orderID, since)
+	if err != nil {
+		return nil, err
+	}
+	return collectIDs(rows)
+}
+
+func (r *offerRepo) GetForUpdateTx(ctx context.Context, tx pgx.Tx, id int64) (model.DeliveryOffer, error) {
+	var o model.DeliveryOffer
+	err := scanOffer(tx.QueryRow(ctx,
+		`SELECT `+offerColumns+` FROM delivery_offers WHERE id = $1 FOR UPDATE`, id), &o)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.DeliveryOffer{}, ErrNotFound
+	}
+	return o, err
+}
+
+// RespondTx moves a pending offer to its final state.
+func (r *offerRepo) RespondTx(ctx context.Context, tx pgx.Tx, o *model.DeliveryOffer, state string) error {
+	err := tx.QueryRow(ctx, `
+		UPDATE delivery_offers SET state = $2, responded_at = NOW()
+		WHERE id = $1 AND state = 'pending'
+		RETURNING state, responded_at`,
+		o.ID, state).Scan(&o.State, &o.RespondedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrConflict
+	}
+	return err
+}
+
+// ExpireDue expires pending offers whose time is up and returns them.
+func (r *offerRepo) ExpireDue(ctx context.Context, now time.Time, limit int) ([]model.DeliveryOffer, error) {
+	return r.list(ctx, `
+		UPDATE delivery_offers SET state = 'expired', responded_at = $1
+		WHERE id IN (
+			SELECT id FROM delivery_offers
+			WHERE state = 'pending' AND expires_at <= $1
+			ORDER BY expires_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+offerColumns, now, limit)
+}
+
+func (r *offerRepo) ListPendingByCourier(ctx context.Context, courierID int) ([]model.DeliveryOffer, error) {
+	return r.list(ctx, `
+		SELECT `+offerColumns+`
+		FROM delivery_offers
+		WHERE courier_id = $1 AND state = 'pending'
+		ORDER BY expires_at`, courierID)
+}
+
+func (r *offerRepo) list(ctx context.Context, query string, args ...any) ([]model.DeliveryOffer, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.DeliveryOffer{}
+	for rows.Next() {
+		var o model.DeliveryOffer
+		if err := scanOffer(rows, &o); err != nil {
+			return nil, err
+		}
+		items = append(items, o)
+	}
+	return items, rows.Err()
+}
+
+// Cancel withdraws the pending offer of a cancelled order.
+func (r *offerRepo) Cancel(ctx context.Context, orderID string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE delivery_offers SET state = 'cancelled', responded_at = NOW()
+		WHERE order_id = $1 AND state = 'pending'`, orderID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *offerRepo) Stats(ctx context.Context, courierID int) (model.OfferStats, error) {
+	s := model.OfferStats{CourierID: courierID}
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FILTER (WHERE state <> 'cancelled'),
+		       COUNT(*) FILTER (WHERE state = 'accepted'),
+		       COUNT(*) FILTER (WHERE state = 'declined'),
+		       COUNT(*) FILTER (WHERE state = 'expired'),
+		       COUNT(*) FILTER (WHERE state = 'pending')
+		FROM delivery_offers
+		WHERE courier_id = $1`, courierID).
+		Scan(&s.Offered, &s.Accepted, &s.Declined, &s.Expired, &s.Pending)
+	if err != nil {
+		return model.OfferStats{}, err
+	}
+	s.Rate()
+	return s, nil
+}
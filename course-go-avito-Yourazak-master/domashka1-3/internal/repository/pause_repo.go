@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PauseRepository interface {
+	Get(ctx context.Context, courierID int) (model.CourierPause, error)
+	Pause(ctx context.Context, p *model.CourierPause) error
+	Resume(ctx context.Context, courierID int) error
+	ResumeDue(ctx context.Context, now time.Time) ([]int, error)
+}
+
+type pauseRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPauseRepository(pool *pgxpool.Pool) PauseRepository {
+	return &pauseRepo{pool: pool}
+}
+
+const pauseColumns = `courier_id, reason, state, resume_at, paused_at, created_at`
+
+func scanPause(row pgx.Row, p *model.CourierPause) error {
+	return row.Scan(&p.CourierID, &p.Reason, &p.State, &p.ResumeAt, &p.PausedAt, &p.CreatedAt)
+}
+
+func (r *pauseRepo) Get(ctx context.Context, courierID int) (model.CourierPause, error) {
+	var p model.CourierPause
+	err := scanPause(r.pool.QueryRow(ctx,
+		`SELECT `+pauseColumns+` FROM courier_pauses WHERE courier_id = $1`, courierID), &p)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.CourierPause{}, ErrNotFound
+		}
+		return model.CourierPause{}, err
+	}
+	return p, nil
+}
+
+// Pause pauses the courier right away, or leaves the pause pending while the
+// courier is busy. Pausing a paused courier replaces the reason and resume
+// time. On success p carries the stored state.
+func (r *pauseRepo) Pause(ctx context.Context, p *model.CourierPause) error {
+	return withStatusChange(ctx, r.pool, func(tx pgx.Tx) error {
+		status, err := lockCourierStatus(ctx, tx, p.CourierID)
+		if err != nil {
+			return err
+		}
+
+		state := model.PauseActive
+		if status == "busy" {
+			state = model.PausePending
+		}
+		if status == "available" {
+			if err := updateCourierStatus(ctx, tx, p.CourierID, "paused"); err != nil {
+				return err
+			}
+		}
+
+		return scanPause(tx.QueryRow(ctx, `
+			INSERT INTO courier_pauses (courier_id, reason, state, resume_at, paused_at)
+			VALUES ($1, $2, $3, $4, CASE WHEN $3 = 'active' THEN NOW() END)
+			ON CONFLICT (courier_id) DO UPDATE
+			SET reason = EXCLUDED.reason,
+			    state = EXCLUDED.state,
+			    resume_at = EXCLUDED.resume_at,
+			    paused_at = COALESCE(courier_pauses.paused_at, EXCLUDED.paused_at)
+			RETURNING `+pauseColumns,
+			p.CourierID, p.Reason, state, p.ResumeAt), p)
+	})
+}
+
+// Resume makes a paused courier available, or cancels a pending pause. It
+// returns ErrConflict when the courier is neither paused nor about to be.
+func (r *pauseRepo) Resume(ctx context.Context, courierID int) error {
+	return withStatusChange(ctx, r.pool, func(tx pgx.Tx) error {
+		status, err := lockCourierStatus(ctx, tx, courierID)
+		if err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, `DELETE FROM courier_pauses WHERE courier_id = $1`, courierID)
+		if err != nil {
+			return err
+		}
+
+		if status == "paused" {
+			return updateCourierStatus(ctx, tx, courierID, "available")
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrConflict
+		}
+		return nil
+	})
+}
+
+// ResumeDue ends pauses whose resume time has come and returns the IDs of
+// couriers that became available. Pending pauses that expire before the
+// delivery is finished are dropped.
+func (r *pauseRepo) ResumeDue(ctx context.Context, now time.Time) ([]int, error) {
+	var ids []int
+	err := withStatusChange(ctx, r.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			WITH due AS (
+				DELETE FROM courier_pauses
+				WHERE resume_at <= $1
+				RETURNING courier_id, state
+			)
+			UPDATE couriers
+			SET status = 'available', version = version + 1, updated_at = NOW()
+			WHERE id IN (SELECT courier_id FROM due WHERE state = 'active') AND status = 'paused'
+			RETURNING id
+		`, now)
+		if err != nil {
+			return err
+		}
+		ids, err = collectIDs(rows)
+		return err
+	})
+	return ids, err
+}
+
+func lockCourierStatus(ctx context.Context, tx pgx.Tx, courierID int) (string, error) {
+	var status string
+	err := tx.QueryRow(ctx, `SELECT status FROM couriers WHERE id = $1 FOR UPDATE`, courierID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return status, err
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PendingAssignmentRepository interface {
+	Enqueue(ctx context.Context, p *model.PendingAssignment) error
+	EnqueueTx(ctx context.Context, tx pgx.Tx, p *model.PendingAssignment) error
+	List(ctx context.Context, limit int) ([]model.PendingAssignment, error)
+	// ListDue is the part of List whose next attempt is due at now.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]model.PendingAssignment, error)
+	Delete(ctx context.Context, orderID string) (bool, error)
+	// RecordFailure records a failed attempt. The order is retried at
+	// nextAttempt, or given up when nextAttempt is nil.
+	RecordFailure(ctx context.Context, id int64, reason string, nextAttempt *time.Time) error
+	Stats(ctx context.Context) (model.AssignmentQueueStats, error)
+}
+
+type pendingAssignmentRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPendingAssignmentRepository(pool *pgxpool.Pool) PendingAssignmentRepository {
+	return &pendingAssignmentRepo{pool: pool}
+}
+
+const pendingAssignmentColumns = `id, order_id, payload, priority, attempts, COALESCE(last_error, ''), enqueued_at, last_attempt_at, state, next_attempt_at`
+
+func scanPendingAssignment(row pgx.Row, p *model.PendingAssignment) error {
+	var payload []byte
+	if err := row.Scan(&p.ID, &p.OrderID, &payload, &p.Priority, &p.Attempts, &p.LastError, &p.EnqueuedAt, &p.LastAttemptAt, &p.State, &p.NextAttemptAt); err != nil {
+		return err
+	}
+	return json.Unmarshal(payload, &p.Order)
+}
+
+// Enqueue adds the order to the queue. An order that is already queued keeps
+// its place; its details are refreshed and its priority can only go up. A
+// given up order starts over with no attempts. EnqueueTx does the same
+// inside tx.
+func (r *pendingAssignmentRepo) Enqueue(ctx context.Context, p *model.PendingAssignment) error {
+	return enqueuePending(ctx, r.pool, p)
+}
+
+func (r *pendingAssignmentRepo) EnqueueTx(ctx context.Context, tx pgx.Tx, p *model.PendingAssignment) error {
+	return enqueuePending(ctx, tx, p)
+}
+
+func enqueuePending(ctx context.Context, db queryRower, p *model.PendingAssignment) error {
+	payload, err := json.Marshal(p.Order)
+	if err != nil {
+		return err
+	}
+	return scanPendingAssignment(db.QueryRow(ctx, `
+		INSERT INTO pending_assignments (order_id, payload, priority)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (order_id) DO UPDATE
+		SET payload = EXCLUDED.payload,
+		    priority = GREATEST(pending_assignments.priority, EXCLUDED.priority),
+		    attempts = CASE WHEN pending_assignments.state = 'failed' THEN 0 ELSE pending_assignments.attempts END,
+		    next_attempt_at = CASE WHEN pending_assignments.state = 'failed' THEN NOW() ELSE pending_assignments.next_attempt_at END,
+		    state = 'pending'
+		RETURNING `+pendingAssignmentColumns,
+		p.OrderID, payload, p.Priority), p)
+}
+
+// List returns the head of the waiting orders: higher priority first, then
+// oldest first.
+func (r *pendingAssignmentRepo) List(ctx context.Context, limit int) ([]model.PendingAssignment, error) {
+	return r.list(ctx, `
+		SELECT `+pendingAssignmentColumns+`
+		FROM pending_assignments
+		WHERE state = 'pending'
+		ORDER BY priority DESC, enqueued_at, id
+		LIMIT $1
+	`, limit)
+}
+
+func (r *pendingAssignmentRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]model.PendingAssignment, error) {
+	return r.list(ctx, `
+		SELECT `+pendingAssignmentColumns+`
+		FROM pending_assignments
+		WHERE state = 'pending' AND next_attempt_at <= $1
+		ORDER BY priority DESC, enqueued_at, id
+		LIMIT $2
+	`, now, limit)
+}
+
+func (r *pendingAssignmentRepo) list(ctx context.Context, query string, args ...any) ([]model.PendingAssignment, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.PendingAssignment{}
+	for rows.Next() {
+		var p model.PendingAssignment
+		if err := scanPendingAssignment(rows, &p); err != nil {
+			return nil, err
+		}
+		items = append(items, p)
+	}
+	return items, rows.Err()
+}
+
+func (r *pendingAssignmentRepo) Delete(ctx context.Context, orderID string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM pending_assignments WHERE order_id = $1`, orderID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *pendingAssignmentRepo) RecordFailure(ctx context.Context, id int64, reason string, nextAttempt *time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE pending_assignments
+		SET attempts = attempts + 1, last_error = $2, last_attempt_at = NOW(),
+		    state = CASE WHEN $3::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+		    next_attempt_at = COALESCE($3, next_attempt_at)
+		WHERE id = $1
+	`, id, reason, nextAttempt)
+	return err
+}
+
+func (r *pendingAssignmentRepo) Stats(ctx context.Context) (model.AssignmentQueueStats, error) {
+	var s model.AssignmentQueueStats
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FILTER (WHERE state = 'pending'),
+		       COUNT(*) FILTER (WHERE state = 'failed'),
+		       MIN(enqueued_at) FILTER (WHERE state = 'pending'),
+		       COALESCE(MAX(priority) FILTER (WHERE state = 'pending'), 0)
+		FROM pending_assignments
+	`).Scan(&s.Depth, &s.Failed, &s.OldestEnqueuedAt, &s.TopPriority)
+	return s, err
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RouteRepository interface {
+	CreateTx(ctx context.Context, tx pgx.Tx, r *model.Route) error
+	GetByID(ctx context.Context, id int) (model.Route, error)
+	UpdateStops(ctx context.Context, id int, fn func(r *model.Route) error) (model.Route, error)
+	CompleteIfDoneTx(ctx context.Context, tx pgx.Tx, id int) (bool, error)
+
+	Collect(ctx context.Context, o model.ExternalOrder) error
+	TakeCollected(ctx context.Context, limit int) ([]model.ExternalOrder, error)
+	Uncollect(ctx context.Context, orderID string) (bool, error)
+}
+
+type routeRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRouteRepository(pool *pgxpool.Pool) RouteRepository {
+	return &routeRepo{pool: pool}
+}
+
+// CreateTx stores the route with its stops; the stops are numbered in the
+// order given.
+func (r *routeRepo) CreateTx(ctx context.Context, tx pgx.Tx, route *model.Route) error {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO routes (courier_id, status)
+		VALUES ($1, $2)
+		RETURNING id, created_at, updated_at
+	`, route.CourierID, route.Status).Scan(&route.ID, &route.CreatedAt, &route.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return insertStops(ctx, tx, route)
+}
+
+func insertStops(ctx context.Context, tx pgx.Tx, route *model.Route) error {
+	for i := range route.Stops {
+		s := &route.Stops[i]
+		s.Seq = i + 1
+		var lat, lon *float64
+		if s.Location != nil {
+			lat, lon = &s.Location.Lat, &s.Location.Lon
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO route_stops (route_id, seq, order_id, kind, lat, lon, eta, completed_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, route.ID, s.Seq, s.OrderID, s.Kind, lat, lon, s.ETA, s.CompletedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *routeRepo) GetByID(ctx context.Context, id int) (model.Route, error) {
+	return getRoute(ctx, r.pool, id, "")
+}
+
+// UpdateStops locks the route, lets fn change its stops and stores them in
+// the new order. The ETAs of the route's dropoff stops are copied to their
+// deliveries.
+func (r *routeRepo) UpdateStops(ctx context.Context, id int, fn func(route *model.Route) error) (model.Route, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return model.Route{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	route, err := getRoute(ctx, tx, id, "FOR UPDATE")
+	if err != nil {
+		return model.Route{}, err
+	}
+	if err := fn(&route); err != nil {
+		return model.Route{}, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM route_stops WHERE route_id = $1`, id); err != nil {
+		return model.Route{}, err
+	}
+	if err := insertStops(ctx, tx, &route); err != nil {
+		return model.Route{}, err
+	}
+	for _, s := range route.Stops {
+		if s.Kind != model.StopDropoff || s.CompletedAt != nil {
+			continue
+		}
+		if _, err := tx.Exec(ctx,
+			`UPDATE deliveries SET eta = $1, updated_at = NOW() WHERE route_id = $2 AND order_id = $3`,
+			s.ETA, id, s.OrderID); err != nil {
+			return model.Route{}, err
+		}
+	}
+	err = tx.QueryRow(ctx, `UPDATE routes SET updated_at = NOW() WHERE id = $1 RETURNING updated_at`, id).
+		Scan(&route.UpdatedAt)
+	if err != nil {
+		return model.Route{}, err
+	}
+	return route, tx.Commit(ctx)
+}
+
+type querier interface {
+	queryRower
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// getRoute loads the route with its stops; lock is appended to the route
+// query.
+func getRoute(ctx context.Context, q querier, id int, lock string) (model.Route, error) {
+	var route model.Route
+	err := q.QueryRow(ctx,
+		`SELECT id, courier_id, status, created_at, updated_at FROM routes WHERE id = $1 `+lock, id).
+		Scan(&route.ID, &route.CourierID, &route.Status, &route.CreatedAt, &route.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Route{}, ErrNotFound
+		}
+		return model.Route{}, err
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT seq, order_id, kind, lat, lon, eta, completed_at
+		FROM route_stops
+		WHERE route_id = $1
+		ORDER BY seq
+	`, id)
+	if err != nil {
+		return model.Route{}, err
+	}
+	defer rows.Close()
+
+	route.Stops = []model.RouteStop{}
+	for rows.Next() {
+		var (
+			s        model.RouteStop
+			lat, lon *float64
+		)
+		if err := rows.Scan(&s.Seq, &s.OrderID, &s.Kind, &lat, &lon, &s.ETA, &s.CompletedAt); err != nil {
+			return model.Route{}, err
+		}
+		if lat != nil && lon != nil {
+			s.Location = &model.GeoPoint{Lat: *lat, Lon: *lon}
+		}
+		route.Stops = append(route.Stops, s)
+	}
+	return route, rows.Err()
+}
+
+// CompleteIfDoneTx marks the route completed once none of its deliveries is
+// still assigned or picked up, and reports whether it did.
+func (r *routeRepo) CompleteIfDoneTx(ctx context.Context, tx pgx.Tx, id int) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		UPDATE routes SET status = 'completed', updated_at = NOW()
+		WHERE id = $1 AND status = 'active'
+		  AND NOT EXISTS (SELECT 1 FROM deliveries WHERE route_id = $1 AND status IN ('assigned', 'picked_up'))
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Collect holds the order until the route batcher picks it up. Collecting an
+// order again replaces its details.
+func (r *routeRepo) Collect(ctx context.Context, o model.ExternalOrder) error {
+	payload, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO route_batch_orders (order_id, payload)
+		VALUES ($1, $2)
+		ON CONFLICT (order_id) DO UPDATE SET payload = EXCLUDED.payload
+	`, o.ID, payload)
+	return err
+}
+
+// TakeCollected removes up to limit collected orders and returns them,
+// oldest first. Rows locked by another replica are skipped.
+func (r *routeRepo) TakeCollected(ctx context.Context, limit int) ([]model.ExternalOrder, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH taken AS (
+			DELETE FROM route_batch_orders
+			WHERE order_id IN (
+				SELECT order_id FROM route_batch_orders
+				ORDER BY collected_at, order_id
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING payload, collected_at, order_id
+		)
+		SELECT payload FROM taken ORDER BY collected_at, order_id
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []model.ExternalOrder{}
+	for rows.Next() {
+		var (
+			payload []byte
+			o       model.ExternalOrder
+		)
+		if err := rows.Scan(&payload); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &o); err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	return orders, rows.Err()
+}
+
+func (r *routeRepo) Uncollect(ctx context.Context, orderID string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM route_batch_orders WHERE order_id = $1`, orderID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ScheduledDeliveryRepository interface {
+	Create(ctx context.Context, s *model.ScheduledDelivery) error
+	ListDue(ctx context.Context, before time.Time, limit int) ([]model.ScheduledDelivery, error)
+	ListByCourier(ctx context.Context, courierID int, after time.Time) ([]model.ScheduledDelivery, error)
+	SetState(ctx context.Context, id int64, state string, courierID, deliveryID *int) error
+	Cancel(ctx context.Context, orderID string) (bool, error)
+}
+
+type scheduledDeliveryRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewScheduledDeliveryRepository(pool *pgxpool.Pool) ScheduledDeliveryRepository {
+	return &scheduledDeliveryRepo{pool: pool}
+}
+
+const scheduledDeliveryColumns = `id, order_id, payload, courier_id, delivery_id, window_start, window_end, state, created_at, updated_at`
+
+func scanScheduledDelivery(row pgx.Row, s *model.ScheduledDelivery) error {
+	var payload []byte
+	if err := row.Scan(&s.ID, &s.OrderID, &payload, &s.CourierID, &s.DeliveryID,
+		&s.WindowStart, &s.WindowEnd, &s.State, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return err
+	}
+	return json.Unmarshal(payload, &s.Order)
+}
+
+// Create stores the order for later assignment. Booking an order that is
+// still waiting again replaces its details and window; one that has already
+// been triggered yields ErrConflict.
+func (r *scheduledDeliveryRepo) Create(ctx context.Context, s *model.ScheduledDelivery) error {
+	payload, err := json.Marshal(s.Order)
+	if err != nil {
+		return err
+	}
+	err = scanScheduledDelivery(r.pool.QueryRow(ctx, `
+		INSERT INTO scheduled_deliveries (order_id, payload, courier_id, window_start, window_end)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (order_id) DO UPDATE
+		SET payload = EXCLUDED.payload,
+		    courier_id = EXCLUDED.courier_id,
+		    window_start = EXCLUDED.window_start,
+		    window_end = EXCLUDED.window_end,
+		    updated_at = NOW()
+		WHERE scheduled_deliveries.state = 'scheduled'
+		RETURNING `+scheduledDeliveryColumns,
+		s.OrderID, payload, s.CourierID, s.WindowStart, s.WindowEnd), s)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrConflict
+		}
+		if isForeignKeyViolation(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ListDue returns waiting deliveries whose window starts before the given
+// time, earliest first.
+func (r *scheduledDeliveryRepo) ListDue(ctx context.Context, before time.Time, limit int) ([]model.ScheduledDelivery, error) {
+	return r.list(ctx, `
+		SELECT `+scheduledDeliveryColumns+`
+		FROM scheduled_deliveries
+		WHERE state = 'scheduled' AND window_start <= $1
+		ORDER BY window_start, id
+		LIMIT $2
+	`, before, limit)
+}
+
+// ListByCourier returns the courier's scheduled and assigned deliveries whose
+// window ends after the given time.
+func (r *scheduledDeliveryRepo) ListByCourier(ctx context.Context, courierID int, after time.Time) ([]model.ScheduledDelivery, error) {
+	return r.list(ctx, `
+		SELECT `+scheduledDeliveryColumns+`
+		FROM scheduled_deliveries
+		WHERE courier_id = $1 AND window_end > $2 AND state IN ('scheduled', 'assigned')
+		ORDER BY window_start, id
+	`, courierID, after)
+}
+
+func (r *scheduledDeliveryRepo) list(ctx context.Context, query string, args ...any) ([]model.ScheduledDelivery, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.ScheduledDelivery{}
+	for rows.Next() {
+		var s model.ScheduledDelivery
+		if err := scanScheduledDelivery(rows, &s); err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	return items, rows.Err()
+}
+
+func (r *scheduledDeliveryRepo) SetState(ctx context.Context, id int64, state string, courierID, deliveryID *int) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE scheduled_deliveries
+		SET state = $2, courier_id = COALESCE($3, courier_id), delivery_id = COALESCE($4, delivery_id), updated_at = NOW()
+		WHERE id = $1
+	`, id, state, courierID, deliveryID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Cancel drops a delivery that has not been triggered yet.
+func (r *scheduledDeliveryRepo) Cancel(ctx context.Context, orderID string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE scheduled_deliveries
+		SET state = 'cancelled', updated_at = NOW()
+		WHERE order_id = $1 AND state = 'scheduled'
+	`, orderID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
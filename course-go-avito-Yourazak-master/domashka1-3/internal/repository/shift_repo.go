@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ShiftRepository interface {
+	Create(ctx context.Context, s *model.CourierShift) error
+	GetByID(ctx context.Context, id int) (model.CourierShift, error)
+	ListByCourier(ctx context.Context, courierID int, from, to time.Time) ([]model.CourierShift, error)
+	Update(ctx context.Context, s *model.CourierShift) error
+	Delete(ctx context.Context, id int) error
+	HasOverlap(ctx context.Context, courierID int, startsAt, endsAt time.Time, excludeID int) (bool, error)
+	ActivateDue(ctx context.Context, now time.Time) ([]int, error)
+	FinishDue(ctx context.Context, now time.Time) ([]int, error)
+}
+
+type shiftRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewShiftRepository(pool *pgxpool.Pool) ShiftRepository {
+	return &shiftRepo{pool: pool}
+}
+
+const shiftColumns = `id, courier_id, starts_at, ends_at, state, created_at, updated_at`
+
+func scanShift(row pgx.Row, s *model.CourierShift) error {
+	return row.Scan(&s.ID, &s.CourierID, &s.StartsAt, &s.EndsAt, &s.State, &s.CreatedAt, &s.UpdatedAt)
+}
+
+func (r *shiftRepo) Create(ctx context.Context, s *model.CourierShift) error {
+	row := r.pool.QueryRow(ctx,
+		`INSERT INTO courier_shifts (courier_id, starts_at, ends_at, state, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, NOW(), NOW())
+		 RETURNING `+shiftColumns,
+		s.CourierID, s.StartsAt, s.EndsAt, model.ShiftScheduled)
+	return scanShift(row, s)
+}
+
+func (r *shiftRepo) GetByID(ctx context.Context, id int) (model.CourierShift, error) {
+	var s model.CourierShift
+	err := scanShift(r.pool.QueryRow(ctx,
+		`SELECT `+shiftColumns+` FROM courier_shifts WHERE id = $1`, id), &s)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.CourierShift{}, ErrNotFound
+		}
+		return model.CourierShift{}, err
+	}
+	return s, nil
+}
+
+func (r *shiftRepo) ListByCourier(ctx context.Context, courierID int, from, to time.Time) ([]model.CourierShift, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+shiftColumns+` FROM courier_shifts
+		 WHERE courier_id = $1 AND ends_at > $2 AND starts_at < $3
+		 ORDER BY starts_at`,
+		courierID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.CourierShift{}
+	for rows.Next() {
+		var s model.CourierShift
+		if err := scanShift(rows, &s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (r *shiftRepo) Update(ctx context.Context, s *model.CourierShift) error {
+	err := scanShift(r.pool.QueryRow(ctx,
+		`UPDATE courier_shifts
+		 SET starts_at = $1, ends_at = $2, updated_at = NOW()
+		 WHERE id = $3 AND state <> 'finished'
+		 RETURNING `+shiftColumns,
+		s.StartsAt, s.EndsAt, s.ID), s)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *shiftRepo) Delete(ctx context.Context, id int) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM courier_shifts WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *shiftRepo) HasOverlap(ctx context.Context, courierID int, startsAt, endsAt time.Time, excludeID int) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS(
+			SELECT 1 FROM courier_shifts
+			WHERE courier_id = $1 AND id <> $4
+			  AND starts_at < $3 AND ends_at > $2
+		)`,
+		courierID, startsAt, endsAt, excludeID).Scan(&exists)
+	return exists, err
+}
+
+// ActivateDue marks shifts that have started as active and makes their
+// paused couriers available, except those paused on request. It returns the
+// IDs of couriers it resumed.
+func (r *shiftRepo) ActivateDue(ctx context.Context, now time.Time) ([]int, error) {
+	var ids []int
+	err := withStatusChange(ctx, r.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			WITH due AS (
+				UPDATE courier_shifts
+				SET state = 'active', updated_at = NOW()
+				WHERE state = 'scheduled' AND starts_at <= $1 AND ends_at > $1
+				RETURNING courier_id
+			)
+			UPDATE couriers
+			SET status = 'available', version = version + 1, updated_at = NOW()
+			WHERE id IN (SELECT courier_id FROM due) AND status = 'paused'
+			  AND NOT EXISTS (SELECT 1 FROM courier_pauses p WHERE p.courier_id = couriers.id AND p.state = 'active')
+			RETURNING id
+		`, now)
+		if err != nil {
+			return err
+		}
+		ids, err = collectIDs(rows)
+		return err
+	})
+	return ids, err
+}
+
+// FinishDue closes shifts that have ended and pauses their couriers. Shifts
+// of couriers that are still busy stay open until the active delivery is
+// finished, so they are picked up again on a later run.
+func (r *shiftRepo) FinishDue(ctx context.Context, now time.Time) ([]int, error) {
+	var ids []int
+	err := withStatusChange(ctx, r.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			WITH ended AS (
+				SELECT s.id, s.courier_id
+				FROM courier_shifts s
+				JOIN couriers c ON c.id = s.courier_id
+				WHERE s.state IN ('scheduled', 'active') AND s.ends_at <= $1 AND c.status <> 'busy'
+				FOR UPDATE OF s
+			), finished AS (
+				UPDATE courier_shifts
+				SET state = 'finished', updated_at = NOW()
+				WHERE id IN (SELECT id FROM ended)
+				RETURNING courier_id
+			)
+			UPDATE couriers
+			SET status = 'paused', version = version + 1, updated_at = NOW()
+			WHERE id IN (SELECT courier_id FROM finished)
+			  AND status = 'available'
+			  AND NOT EXISTS (
+				SELECT 1 FROM courier_shifts s2
+				WHERE s2.courier_id = couriers.id AND s2.starts_at <= $1 AND s2.ends_at > $1
+			  )
+			RETURNING id
+		`, now)
+		if err != nil {
+			return err
+		}
+		ids, err = collectIDs(rows)
+		return err
+	})
+	return ids, err
+}
+
+func collectIDs(rows pgx.Rows) ([]int, error) {
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
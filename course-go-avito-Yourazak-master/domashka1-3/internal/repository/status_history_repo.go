@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type StatusHistoryRepository interface {
+	ListByCourier(ctx context.Context, courierID, limit int) ([]model.StatusHistoryEntry, error)
+}
+
+type statusHistoryRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewStatusHistoryRepository(pool *pgxpool.Pool) StatusHistoryRepository {
+	return &statusHistoryRepo{pool: pool}
+}
+
+// ListByCourier returns the newest entries first.
+func (r *statusHistoryRepo) ListByCourier(ctx context.Context, courierID, limit int) ([]model.StatusHistoryEntry, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, courier_id, old_status, new_status, COALESCE(reason, ''), actor, COALESCE(order_id, ''), changed_at
+		FROM courier_status_history
+		WHERE courier_id = $1
+		ORDER BY changed_at DESC, id DESC
+		LIMIT $2
+	`, courierID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []model.StatusHistoryEntry{}
+	for rows.Next() {
+		var e model.StatusHistoryEntry
+		if err := rows.Scan(&e.ID, &e.CourierID, &e.OldStatus, &e.NewStatus, &e.Reason, &e.Actor, &e.OrderID, &e.ChangedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// tagStatusChange hands the status change described by ctx to the trigger
+// that fills courier_status_history. The settings are local to tx and are
+// overwritten on every call, so each write has to be tagged right before it.
+func tagStatusChange(ctx context.Context, tx pgx.Tx) error {
+	c := model.StatusChangeFrom(ctx)
+	_, err := tx.Exec(ctx, `
+		SELECT set_config('courier_audit.actor', $1, true),
+		       set_config('courier_audit.reason', $2, true),
+		       set_config('courier_audit.order_id', $3, true)
+	`, c.Actor, c.Reason, c.OrderID)
+	return err
+}
+
+// withStatusChange runs fn in a transaction tagged with the status change
+// from ctx.
+func withStatusChange(ctx context.Context, pool *pgxpool.Pool, fn func(tx pgx.Tx) error) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tagStatusChange(ctx, tx); err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, s *model.WebhookSubscription) error
+	GetByID(ctx context.Context, id int64) (model.WebhookSubscription, error)
+	List(ctx context.Context) ([]model.WebhookSubscription, error)
+	// Update replaces URL, event types, merchant and active flag, and the
+	// secret when s.Secret is set. Re-activating a subscription clears its
+	// failures.
+	Update(ctx context.Context, s *model.WebhookSubscription) error
+	Delete(ctx context.Context, id int64) error
+	// ListDeliveries returns the newest deliveries of the subscription,
+	// optionally only those in state.
+	ListDeliveries(ctx context.Context, subscriptionID int64, state string, limit int) ([]model.WebhookDelivery, error)
+	// ClaimDue returns pending deliveries of active subscriptions whose next
+	// attempt is due, oldest first, with the URL and secret to send them with.
+	ClaimDue(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error)
+	// MarkDelivered records a successful attempt and resets the failure count
+	// of the subscription.
+	MarkDelivered(ctx context.Context, id int64, status int, at time.Time) error
+	// RecordFailure records a failed attempt. The delivery is retried at
+	// nextAttempt, or given up when nextAttempt is nil. The subscription is
+	// deactivated once it failed disableAfter times in a row; the returned
+	// flag tells whether it is still active.
+	RecordFailure(ctx context.Context, id int64, status *int, reason string, nextAttempt *time.Time, disableAfter int) (bool, error)
+}
+
+type webhookRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebhookRepository(pool *pgxpool.Pool) WebhookRepository {
+	return &webhookRepo{pool: pool}
+}
+
+// The secret is deliberately not part of the subscription columns: it is
+// only read back when a delivery is claimed.
+const webhookSubscriptionColumns = `id, url, event_types, COALESCE(merchant_id, ''), active, consecutive_failures, disabled_at, created_at, updated_at`
+
+func scanWebhookSubscription(row pgx.Row, s *model.WebhookSubscription) error {
+	return row.Scan(&s.ID, &s.URL, &s.EventTypes, &s.MerchantID, &s.Active, &s.ConsecutiveFailures, &s.DisabledAt, &s.CreatedAt, &s.UpdatedAt)
+}
+
+const webhookDeliveryColumns = `d.id, d.subscription_id, d.event, d.payload, d.state, d.attempts, d.next_attempt_at, d.last_status, COALESCE(d.last_error, ''), d.created_at, d.delivered_at`
+
+func scanWebhookDelivery(row pgx.Row, d *model.WebhookDelivery, extra ...any) error {
+	dest := []any{&d.ID, &d.SubscriptionID, &d.Event, &d.Payload, &d.State, &d.Attempts, &d.NextAttemptAt, &d.LastStatus, &d.LastError, &d.CreatedAt, &d.DeliveredAt}
+	return row.Scan(append(dest, extra...)...)
+}
+
+func (r *webhookRepo) Create(ctx context.Context, s *model.WebhookSubscription) error {
+	return scanWebhookSubscription(r.pool.QueryRow(ctx, `
+		INSERT INTO webhook_subscriptions (url, event_types, secret, active, merchant_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		RETURNING `+webhookSubscriptionColumns,
+		s.URL, s.EventTypes, s.Secret, s.Active, s.MerchantID), s)
+}
+
+func (r *webhookRepo) GetByID(ctx context.Context, id int64) (model.WebhookSubscription, error) {
+	var s model.WebhookSubscription
+	err := scanWebhookSubscription(r.pool.QueryRow(ctx,
+		`SELECT `+webhookSubscriptionColumns+` FROM webhook_subscriptions WHERE id = $1`, id), &s)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.WebhookSubscription{}, ErrNotFound
+		}
+		return model.WebhookSubscription{}, err
+	}
+	return s, nil
+}
+
+func (r *webhookRepo) List(ctx context.Context) ([]model.WebhookSubscription, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+webhookSubscriptionColumns+` FROM webhook_subscriptions ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.WebhookSubscription{}
+	for rows.Next() {
+		var s model.WebhookSubscription
+		if err := scanWebhookSubscription(rows, &s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (r *webhookRepo) Update(ctx context.Context, s *model.WebhookSubscription) error {
+	err := scanWebhookSubscription(r.pool.QueryRow(ctx, `
+		UPDATE webhook_subscriptions
+		SET url = $2, event_types = $3, secret = COALESCE(NULLIF($4, ''), secret),
+		    consecutive_failures = CASE WHEN $5 AND NOT active THEN 0 ELSE consecutive_failures END,
+		    disabled_at = CASE WHEN $5 THEN NULL ELSE COALESCE(disabled_at, NOW()) END,
+		    active = $5, merchant_id = NULLIF($6, ''), updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+webhookSubscriptionColumns,
+		s.ID, s.URL, s.EventTypes, s.Secret, s.Active, s.MerchantID), s)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *webhookRepo) Delete(ctx context.Context, id int64) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepo) ListDeliveries(ctx context.Context, subscriptionID int64, state string, limit int) ([]model.WebhookDelivery, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries d
+		WHERE d.subscription_id = $1 AND ($2 = '' OR d.state = $2)
+		ORDER BY d.id DESC
+		LIMIT $3
+	`, subscriptionID, state, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.WebhookDelivery{}
+	for rows.Next() {
+		var d model.WebhookDelivery
+		if err := scanWebhookDelivery(rows, &d); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *webhookRepo) ClaimDue(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+webhookDeliveryColumns+`, s.url, s.secret
+		FROM webhook_deliveries d
+		JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		WHERE d.state = 'pending' AND d.next_attempt_at <= $1 AND s.active
+		ORDER BY d.next_attempt_at, d.id
+		LIMIT $2
+	`, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.WebhookDelivery{}
+	for rows.Next() {
+		var d model.WebhookDelivery
+		if err := scanWebhookDelivery(rows, &d, &d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *webhookRepo) MarkDelivered(ctx context.Context, id int64, status int, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		WITH d AS (
+			UPDATE webhook_deliveries
+			SET state = 'delivered', attempts = attempts + 1, last_status = $2,
+			    last_error = NULL, delivered_at = $3
+			WHERE id = $1
+			RETURNING subscription_id
+		)
+		UPDATE webhook_subscriptions s
+		SET consecutive_failures = 0
+		FROM d
+		WHERE s.id = d.subscription_id AND s.consecutive_failures <> 0
+	`, id, status, at)
+	return err
+}
+
+func (r *webhookRepo) RecordFailure(ctx context.Context, id int64, status *int, reason string, nextAttempt *time.Time, disableAfter int) (bool, error) {
+	var active bool
+	err := r.pool.QueryRow(ctx, `
+		WITH d AS (
+			UPDATE webhook_deliveries
+			SET attempts = attempts + 1, last_status = $2, last_error = $3,
+			    state = CASE WHEN $4::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+			    next_attempt_at = COALESCE($4, next_attempt_at)
+			WHERE id = $1
+			RETURNING subscription_id
+		)
+		UPDATE webhook_subscriptions s
+		SET consecutive_failures = s.consecutive_failures + 1,
+		    active = s.active AND s.consecutive_failures + 1 < $5,
+		    disabled_at = CASE WHEN s.active AND s.consecutive_failures + 1 >= $5 THEN NOW() ELSE s.disabled_at END,
+		    updated_at = NOW()
+		FROM d
+		WHERE s.id = d.subscription_id
+		RETURNING s.active
+	`, id, status, reason, nextAttempt, disableAfter).Scan(&active)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrNotFound
+		}
+		return false, err
+	}
+	return active, nil
+}
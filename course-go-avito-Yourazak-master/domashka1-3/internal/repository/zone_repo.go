@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ZoneRepository interface {
+	Create(ctx context.Context, z *model.DeliveryZone) error
+	GetByID(ctx context.Context, id int) (model.DeliveryZone, error)
+	List(ctx context.Context) ([]model.DeliveryZone, error)
+	Update(ctx context.Context, z *model.DeliveryZone) error
+	Delete(ctx context.Context, id int) error
+	FindByRegion(ctx context.Context, regionID int) (model.DeliveryZone, error)
+	ListByCourier(ctx context.Context, courierID int) ([]model.DeliveryZone, error)
+	SetCourierZones(ctx context.Context, courierID int, zoneIDs []int) error
+}
+
+type zoneRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewZoneRepository(pool *pgxpool.Pool) ZoneRepository {
+	return &zoneRepo{pool: pool}
+}
+
+// Delete already strips a removed zone from its neighbours, but an update
+// racing with the delete can still store its ID. Neighbours are therefore
+// read back only if the zone still exists, so spillover never searches a
+// zone that is gone.
+const zoneColumns = `z.id, z.name, z.region_id, z.polygon,
+	ARRAY(SELECT n.id FROM unnest(z.neighbour_ids) WITH ORDINALITY AS n(id, pos)
+	      WHERE EXISTS (SELECT 1 FROM delivery_zones nz WHERE nz.id = n.id)
+	      ORDER BY n.pos),
+	z.created_at, z.updated_at`
+
+func scanZone(row pgx.Row, z *model.DeliveryZone) error {
+	return row.Scan(&z.ID, &z.Name, &z.RegionID, &z.Polygon, &z.NeighbourIDs, &z.CreatedAt, &z.UpdatedAt)
+}
+
+func scanZones(rows pgx.Rows) ([]model.DeliveryZone, error) {
+	defer rows.Close()
+
+	out := []model.DeliveryZone{}
+	for rows.Next() {
+		var z model.DeliveryZone
+		if err := scanZone(rows, &z); err != nil {
+			return nil, err
+		}
+		out = append(out, z)
+	}
+	return out, rows.Err()
+}
+
+func neighbourIDs(z *model.DeliveryZone) []int {
+	if z.NeighbourIDs == nil {
+		return []int{}
+	}
+	return z.NeighbourIDs
+}
+
+func (r *zoneRepo) Create(ctx context.Context, z *model.DeliveryZone) error {
+	err := scanZone(r.pool.QueryRow(ctx,
+		`INSERT INTO delivery_zones AS z (name, region_id, polygon, neighbour_ids, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, NOW(), NOW())
+		 RETURNING `+zoneColumns,
+		z.Name, z.RegionID, z.Polygon, neighbourIDs(z)), z)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *zoneRepo) GetByID(ctx context.Context, id int) (model.DeliveryZone, error) {
+	var z model.DeliveryZone
+	err := scanZone(r.pool.QueryRow(ctx,
+		`SELECT `+zoneColumns+` FROM delivery_zones z WHERE z.id = $1`, id), &z)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.DeliveryZone{}, ErrNotFound
+		}
+		return model.DeliveryZone{}, err
+	}
+	return z, nil
+}
+
+func (r *zoneRepo) List(ctx context.Context) ([]model.DeliveryZone, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+zoneColumns+` FROM delivery_zones z ORDER BY z.id`)
+	if err != nil {
+		return nil, err
+	}
+	return scanZones(rows)
+}
+
+func (r *zoneRepo) Update(ctx context.Context, z *model.DeliveryZone) error {
+	err := scanZone(r.pool.QueryRow(ctx,
+		`UPDATE delivery_zones AS z
+		 SET name = $1, region_id = $2, polygon = $3, neighbour_ids = $4, updated_at = NOW()
+		 WHERE z.id = $5
+		 RETURNING `+zoneColumns,
+		z.Name, z.RegionID, z.Polygon, neighbourIDs(z), z.ID), z)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete removes the zone and its courier memberships; references to it in
+// other zones' neighbour lists are dropped as well.
+func (r *zoneRepo) Delete(ctx context.Context, id int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `DELETE FROM delivery_zones WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE delivery_zones SET neighbour_ids = array_remove(neighbour_ids, $1::bigint), updated_at = NOW()
+		 WHERE $1 = ANY(neighbour_ids)`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *zoneRepo) FindByRegion(ctx context.Context, regionID int) (model.DeliveryZone, error) {
+	var z model.DeliveryZone
+	err := scanZone(r.pool.QueryRow(ctx,
+		`SELECT `+zoneColumns+` FROM delivery_zones z WHERE z.region_id = $1`, regionID), &z)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.DeliveryZone{}, ErrNotFound
+		}
+		return model.DeliveryZone{}, err
+	}
+	return z, nil
+}
+
+func (r *zoneRepo) ListByCourier(ctx context.Context, courierID int) ([]model.DeliveryZone, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+zoneColumns+`
+		 FROM delivery_zones z
+		 JOIN courier_zones cz ON cz.zone_id = z.id
+		 WHERE cz.courier_id = $1
+		 ORDER BY z.id`, courierID)
+	if err != nil {
+		return nil, err
+	}
+	return scanZones(rows)
+}
+
+// SetCourierZones replaces the courier's zone memberships with zoneIDs.
+func (r *zoneRepo) SetCourierZones(ctx context.Context, courierID int, zoneIDs []int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM courier_zones WHERE courier_id = $1`, courierID); err != nil {
+		return err
+	}
+
+	if len(zoneIDs) > 0 {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO courier_zones (courier_id, zone_id, created_at)
+			 SELECT $1, unnest($2::bigint[]), NOW()
+			 ON CONFLICT DO NOTHING`,
+			courierID, zoneIDs)
+		if err != nil {
+			if isForeignKeyViolation(err) {
+				return ErrNotFound
+			}
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -10,20 +10,77 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-func NewRouter(courierHandler *handler.CourierHandler, deliveryHandler *handler.DeliveryHandler, rateLimiter *middleware.RateLimiter) http.Handler {
+func NewRouter(courierHandler *handler.CourierHandler, deliveryHandler *handler.DeliveryHandler, webhookHandler *handler.WebhookHandler, shiftHandler *handler.ShiftHandler, locationHandler *handler.LocationHandler, zoneHandler *handler.ZoneHandler, capabilityHandler *handler.CapabilityHandler, historyHandler *handler.StatusHistoryHandler, pauseHandler *handler.PauseHandler, queueHandler *handler.AssignmentQueueHandler, routeHandler *handler.RouteHandler, meHandler *handler.MeHandler, streamHandler *handler.StreamHandler, subscriptionHandler *handler.WebhookSubscriptionHandler, rateLimiter *middleware.RateLimiter) http.Handler {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("POST /api/couriers", courierHandler.Create)
 	mux.HandleFunc("GET /api/couriers/{id}", courierHandler.GetByID)
 	mux.HandleFunc("PUT /api/couriers/{id}", courierHandler.Update)
+	mux.HandleFunc("PATCH /api/couriers/{id}", courierHandler.Patch)
 	mux.HandleFunc("DELETE /api/couriers/{id}", courierHandler.Delete)
 	mux.HandleFunc("GET /api/couriers", courierHandler.GetAll)
-	mux.HandleFunc("POST /api/courier/assign", courierHandler.AssignOrder)
+	mux.HandleFunc("POST /api/couriers/import", courierHandler.Import)
+	mux.HandleFunc("GET /api/couriers/export", courierHandler.Export)
+	mux.HandleFunc("POST /api/courier/assign", deliveryHandler.Assign)
+
+	mux.HandleFunc("POST /api/couriers/{id}/shifts", shiftHandler.Create)
+	mux.HandleFunc("GET /api/couriers/{id}/shifts", shiftHandler.ListByCourier)
+	mux.HandleFunc("GET /api/shifts/{id}", shiftHandler.GetByID)
+	mux.HandleFunc("PUT /api/shifts/{id}", shiftHandler.Update)
+	mux.HandleFunc("DELETE /api/shifts/{id}", shiftHandler.Delete)
+
+	mux.HandleFunc("POST /api/couriers/{id}/location", locationHandler.Report)
+	mux.HandleFunc("GET /api/couriers/{id}/location", locationHandler.GetLatest)
+
+	mux.HandleFunc("PUT /api/couriers/{id}/zones", zoneHandler.SetCourierZones)
+	mux.HandleFunc("GET /api/couriers/{id}/zones", zoneHandler.ListByCourier)
+	mux.HandleFunc("GET /api/couriers/{id}/capabilities", capabilityHandler.Get)
+	mux.HandleFunc("PUT /api/couriers/{id}/capabilities", capabilityHandler.Set)
+	mux.HandleFunc("DELETE /api/couriers/{id}/capabilities", capabilityHandler.Reset)
+	mux.HandleFunc("GET /api/couriers/{id}/history", historyHandler.List)
+	mux.HandleFunc("GET /api/couriers/{id}/scheduled-deliveries", deliveryHandler.ListScheduled)
+	mux.HandleFunc("GET /api/couriers/{id}/offer-stats", deliveryHandler.OfferStats)
+	mux.HandleFunc("POST /api/couriers/{id}/pause", pauseHandler.Pause)
+	mux.HandleFunc("POST /api/couriers/{id}/resume", pauseHandler.Resume)
+
+	mux.HandleFunc("POST /api/zones", zoneHandler.Create)
+	mux.HandleFunc("GET /api/zones", zoneHandler.List)
+	mux.HandleFunc("GET /api/zones/{id}", zoneHandler.GetByID)
+	mux.HandleFunc("PUT /api/zones/{id}", zoneHandler.Update)
+	mux.HandleFunc("DELETE /api/zones/{id}", zoneHandler.Delete)
 
 	mux.HandleFunc("POST /api/delivery/assign", deliveryHandler.Assign)
 	mux.HandleFunc("POST /api/delivery/unassign", deliveryHandler.Unassign)
+	mux.HandleFunc("POST /api/delivery/{id}/reassign", deliveryHandler.Reassign)
+	mux.HandleFunc("POST /api/delivery/{id}/complete", deliveryHandler.Complete)
+	mux.HandleFunc("POST /api/delivery/{id}/fail", deliveryHandler.Fail)
 	mux.HandleFunc("GET /api/delivery/{id}", deliveryHandler.GetDelivery)
 	mux.HandleFunc("GET /api/deliveries", deliveryHandler.ListDeliveries)
+	mux.HandleFunc("GET /api/assignments/pending", queueHandler.List)
+	mux.HandleFunc("POST /api/routes/plan", routeHandler.Plan)
+	mux.HandleFunc("GET /api/routes/{id}", routeHandler.Get)
+	mux.HandleFunc("POST /api/routes/{id}/stops/{seq}/complete", routeHandler.CompleteStop)
+
+	mux.Handle("POST /api/couriers/{id}/token", meHandler.Dispatcher(meHandler.IssueToken))
+	mux.Handle("GET /api/me/deliveries", meHandler.Auth(meHandler.Deliveries))
+	mux.Handle("POST /api/me/deliveries/{id}/pickup", meHandler.Auth(meHandler.PickUp))
+	mux.Handle("POST /api/me/deliveries/{id}/deliver", meHandler.Auth(meHandler.Deliver))
+	mux.Handle("POST /api/me/deliveries/{id}/fail", meHandler.Auth(meHandler.Fail))
+	mux.Handle("POST /api/me/status", meHandler.Auth(meHandler.Status))
+	mux.Handle("GET /api/me/offers", meHandler.Auth(meHandler.Offers))
+	mux.Handle("POST /api/me/offers/{id}/accept", meHandler.Auth(meHandler.AcceptOffer))
+	mux.Handle("POST /api/me/offers/{id}/decline", meHandler.Auth(meHandler.DeclineOffer))
+
+	mux.HandleFunc("GET /api/stream/deliveries", streamHandler.Deliveries)
+	mux.HandleFunc("GET /api/stream/deliveries/ws", streamHandler.DeliveriesWS)
+
+	mux.Handle("POST /api/webhooks/orders", webhookHandler.Middleware()(http.HandlerFunc(webhookHandler.Orders)))
+	mux.HandleFunc("POST /api/webhooks/subscriptions", subscriptionHandler.Create)
+	mux.HandleFunc("GET /api/webhooks/subscriptions", subscriptionHandler.List)
+	mux.HandleFunc("GET /api/webhooks/subscriptions/{id}", subscriptionHandler.GetByID)
+	mux.HandleFunc("PUT /api/webhooks/subscriptions/{id}", subscriptionHandler.Update)
+	mux.HandleFunc("DELETE /api/webhooks/subscriptions/{id}", subscriptionHandler.Delete)
+	mux.HandleFunc("GET /api/webhooks/subscriptions/{id}/deliveries", subscriptionHandler.Deliveries)
 
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -35,6 +92,8 @@ func NewRouter(courierHandler *handler.CourierHandler, deliveryHandler *handler.
 
 	handler := http.Handler(mux)
 
+	handler = middleware.ActorMiddleware(handler)
+
 	handler = middleware.MetricsMiddleware(handler)
 
 	handler = middleware.LoggingMiddleware(handler)
@@ -1,11 +1,97 @@
 package router
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/stretchr/testify/assert"
+	"avito-courier/internal/handler"
+	"avito-courier/internal/middleware"
+	"avito-courier/internal/usecase"
 )
 
-func TestNewRouter(t *testing.T) {
-	assert.True(t, true)
+// newTestRouter wires handlers without usecases, so only requests rejected
+// before reaching a usecase can be served.
+func newTestRouter() http.Handler {
+	tokens := middleware.NewCourierTokens("test-secret", time.Hour)
+	return NewRouter(
+		handler.NewCourierHandler(nil),
+		handler.NewDeliveryHandler(nil),
+		handler.NewWebhookHandler(nil, map[string]string{"shop": "shop-secret"}, 0),
+		handler.NewShiftHandler(nil),
+		handler.NewLocationHandler(nil),
+		handler.NewZoneHandler(nil),
+		handler.NewCapabilityHandler(nil),
+		handler.NewStatusHistoryHandler(nil),
+		handler.NewPauseHandler(nil),
+		handler.NewAssignmentQueueHandler(nil),
+		handler.NewRouteHandler(nil),
+		handler.NewMeHandler(nil, nil, nil, tokens, "dispatcher-key"),
+		handler.NewStreamHandler(usecase.NewDeliveryStream()),
+		handler.NewWebhookSubscriptionHandler(nil),
+		nil,
+	)
+}
+
+func TestRouter(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		header map[string]string
+		body   string
+		want   int
+	}{
+		{name: "health", method: "GET", target: "/health", want: http.StatusOK},
+		{name: "unknown route", method: "GET", target: "/api/unknown", want: http.StatusNotFound},
+		{name: "wrong method", method: "DELETE", target: "/api/couriers", want: http.StatusMethodNotAllowed},
+		{name: "patch invalid id", method: "PATCH", target: "/api/couriers/abc", want: http.StatusBadRequest},
+		{name: "import needs content type", method: "POST", target: "/api/couriers/import", want: http.StatusUnsupportedMediaType},
+		{name: "export unknown format", method: "GET", target: "/api/couriers/export?format=xml", want: http.StatusBadRequest},
+		{name: "pause invalid id", method: "POST", target: "/api/couriers/0/pause", want: http.StatusBadRequest},
+		{name: "reassign invalid id", method: "POST", target: "/api/delivery/abc/reassign", want: http.StatusBadRequest},
+		{name: "complete invalid id", method: "POST", target: "/api/delivery/abc/complete", want: http.StatusBadRequest},
+		{name: "delivery invalid id", method: "GET", target: "/api/delivery/abc", want: http.StatusBadRequest},
+		{name: "deliveries invalid limit", method: "GET", target: "/api/deliveries?limit=ten", want: http.StatusBadRequest},
+		{name: "fail unknown reason", method: "POST", target: "/api/delivery/1/fail", body: `{"reason":"lazy"}`, want: http.StatusBadRequest},
+		{name: "me without token", method: "GET", target: "/api/me/deliveries", want: http.StatusUnauthorized},
+		{name: "me offers without token", method: "POST", target: "/api/me/offers/1/accept", want: http.StatusUnauthorized},
+		{name: "token without dispatcher key", method: "POST", target: "/api/couriers/1/token", want: http.StatusUnauthorized},
+		{
+			name: "token with wrong dispatcher key", method: "POST", target: "/api/couriers/1/token",
+			header: map[string]string{"Authorization": "Bearer wrong"}, want: http.StatusUnauthorized,
+		},
+		{name: "stream invalid courier", method: "GET", target: "/api/stream/deliveries?courier_id=x", want: http.StatusBadRequest},
+		{name: "webhook unknown partner", method: "POST", target: "/api/webhooks/orders", body: `{}`, want: http.StatusUnauthorized},
+		{
+			name: "webhook bad signature", method: "POST", target: "/api/webhooks/orders", body: `{}`,
+			header: map[string]string{
+				middleware.PartnerIDHeader: "shop",
+				middleware.TimestampHeader: strconv.FormatInt(time.Now().Unix(), 10),
+				middleware.SignatureHeader: "sha256=00",
+			},
+			want: http.StatusUnauthorized,
+		},
+		{name: "subscription invalid id", method: "GET", target: "/api/webhooks/subscriptions/abc", want: http.StatusBadRequest},
+	}
+
+	router := newTestRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Errorf("%s %s: got status %d, want %d (%s)", tt.method, tt.target, rr.Code, tt.want, strings.TrimSpace(rr.Body.String()))
+			}
+		})
+	}
 }
@@ -3,10 +3,10 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"sync"
 
-	"avito-courier/internal/gateway/order"
 	"avito-courier/internal/model"
 	"avito-courier/internal/usecase"
 
@@ -14,16 +14,14 @@ import (
 )
 
 type Consumer struct {
-	ready        chan bool
-	factory      *usecase.EventHandlerFactory
-	orderGateway order.OrderGateway
+	ready     chan bool
+	processor usecase.OrderEventProcessor
 }
 
-func NewConsumer(factory *usecase.EventHandlerFactory, gateway order.OrderGateway) *Consumer {
+func NewConsumer(processor usecase.OrderEventProcessor) *Consumer {
 	return &Consumer{
-		ready:        make(chan bool),
-		factory:      factory,
-		orderGateway: gateway,
+		ready:     make(chan bool),
+		processor: processor,
 	}
 }
 
@@ -95,34 +93,22 @@ func (c *Consumer) processMessage(ctx context.Context, msg *sarama.ConsumerMessa
 		return
 	}
 
-	if !event.Validate() {
-		log.Printf("Invalid Kafka event: %+v", event)
-		return
-	}
-
 	log.Printf("Kafka event received: %s - %s (offset: %d)",
 		event.OrderID, event.Status, msg.Offset)
 
-	if c.orderGateway != nil {
-		actualOrder, err := c.orderGateway.GetOrderStatus(ctx, event.OrderID)
-		if err != nil {
-			log.Printf("Failed to verify order status for %s: %v", event.OrderID, err)
-		} else if actualOrder.Status != event.Status {
-			log.Printf("Status mismatch for %s: event=%s, actual=%s - skipping",
-				event.OrderID, event.Status, actualOrder.Status)
-			return
+	if err := c.processor.Process(ctx, event); err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidEvent):
+			log.Printf("Invalid Kafka event: %+v", event)
+		case errors.Is(err, usecase.ErrStatusMismatch):
+			// already logged by the processor
+		case errors.Is(err, usecase.ErrNoEventHandler):
+			log.Printf("Skipping Kafka event %s (%s): %v", event.OrderID, event.Status, err)
+		default:
+			log.Printf("Failed to handle event %s: %v", event.OrderID, err)
 		}
-	}
-
-	handler := c.factory.GetHandler(event.Status)
-	if handler == nil {
-		log.Printf("No handler for status: %s", event.Status)
 		return
 	}
 
-	if err := handler.Handle(ctx, event); err != nil {
-		log.Printf("Failed to handle event %s: %v", event.OrderID, err)
-	} else {
-		log.Printf("Event processed: %s - %s", event.OrderID, event.Status)
-	}
+	log.Printf("Event processed: %s - %s", event.OrderID, event.Status)
 }
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"avito-courier/internal/middleware"
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
+)
+
+// AssignmentQueueLockKey is the pg advisory lock key that elects the single
+// replica allowed to work the assignment queue.
+const AssignmentQueueLockKey int64 = 7_341_002
+
+type AssignmentQueueConfig struct {
+	Interval  time.Duration
+	BatchSize int
+	// MaxAttempts is the number of failed attempts after which an order is
+	// given up.
+	MaxAttempts int
+	// BackoffBase is the wait after the first failed attempt; it doubles
+	// with every further failure up to BackoffMax.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// AssignmentQueueSnapshot is what the inspection endpoint shows: queue depth,
+// the wait of the oldest order and the head of the queue in processing order.
+type AssignmentQueueSnapshot struct {
+	Depth          int                       `json:"depth"`
+	Failed         int                       `json:"failed"`
+	OldestWaitSecs float64                   `json:"oldest_wait_seconds"`
+	Items          []model.PendingAssignment `json:"items"`
+}
+
+type AssignmentQueueUsecase interface {
+	Inspect(ctx context.Context, limit int) (AssignmentQueueSnapshot, error)
+}
+
+type pendingAssigner interface {
+	AssignPending(ctx context.Context, o model.ExternalOrder) error
+}
+
+// AssignmentQueue retries orders that found no courier. It runs on every
+// tick and whenever Wake is called, e.g. when a courier becomes available.
+type AssignmentQueue struct {
+	repo      repository.PendingAssignmentRepository
+	assigner  pendingAssigner
+	locker    repository.Locker
+	interval  time.Duration
+	batchSize int
+	cfg       AssignmentQueueConfig
+	wake      chan struct{}
+}
+
+func NewAssignmentQueue(r repository.PendingAssignmentRepository, assigner pendingAssigner, locker repository.Locker, cfg AssignmentQueueConfig) *AssignmentQueue {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 30
+	}
+	if cfg.BackoffBase <= 0 {
+		cfg.BackoffBase = 10 * time.Second
+	}
+	if cfg.BackoffMax <= 0 {
+		cfg.BackoffMax = 5 * time.Minute
+	}
+	if cfg.BackoffMax < cfg.BackoffBase {
+		cfg.BackoffMax = cfg.BackoffBase
+	}
+	return &AssignmentQueue{
+		repo:      r,
+		assigner:  assigner,
+		locker:    locker,
+		interval:  cfg.Interval,
+		batchSize: cfg.BatchSize,
+		cfg:       cfg,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Wake asks the worker to process the queue now. It never blocks; wake-ups
+// that arrive while one is pending are merged.
+func (q *AssignmentQueue) Wake() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *AssignmentQueue) Start(ctx context.Context) {
+	ticker := time.NewTicker(q.interval)
+	defer ticker.Stop()
+	defer q.locker.Release(context.Background())
+
+	log.Printf("Assignment queue started (ticker: %v, batch: %d)", q.interval, q.batchSize)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Assignment queue stopped")
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+
+		acquired, err := q.locker.TryAcquire(ctx)
+		if err != nil {
+			log.Printf("Assignment queue: failed to acquire lock: %v", err)
+			continue
+		}
+		if acquired {
+			q.processOnce(ctx)
+		}
+	}
+}
+
+func (q *AssignmentQueue) processOnce(ctx context.Context) {
+	now := time.Now().UTC()
+	items, err := q.repo.ListDue(ctx, now, q.batchSize)
+	if err != nil {
+		log.Printf("Assignment queue: failed to list pending orders: %v", err)
+		return
+	}
+
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Actor: model.ActorAssignmentQueue})
+	for _, p := range items {
+		err := q.assigner.AssignPending(ctx, p.Order)
+		switch {
+		case err == nil:
+			middleware.AssignmentQueueAttemptsTotal.WithLabelValues("assigned").Inc()
+			middleware.AssignmentQueueWaitSeconds.Observe(time.Since(p.EnqueuedAt).Seconds())
+			log.Printf("Assignment queue: order %s assigned after %v", p.OrderID, time.Since(p.EnqueuedAt).Round(time.Second))
+			q.remove(ctx, p)
+		case errors.Is(err, ErrOrderAlreadyAssigned):
+			middleware.AssignmentQueueAttemptsTotal.WithLabelValues("already_assigned").Inc()
+			q.remove(ctx, p)
+		case errors.Is(err, ErrOfferPending):
+			middleware.AssignmentQueueAttemptsTotal.WithLabelValues("offered").Inc()
+			log.Printf("Assignment queue: order %s offered after %v", p.OrderID, time.Since(p.EnqueuedAt).Round(time.Second))
+			q.remove(ctx, p)
+		default:
+			result := "error"
+			if errors.Is(err, ErrNoAvailableCourier) || errors.Is(err, ErrNoCapableCourier) {
+				result = "no_courier"
+			}
+			var next *time.Time
+			if p.Attempts+1 < q.cfg.MaxAttempts {
+				t := now.Add(q.backoff(p.Attempts + 1))
+				next = &t
+			} else {
+				result = "given_up"
+				log.Printf("Assignment queue: giving up order %s after %d attempts: %v", p.OrderID, p.Attempts+1, err)
+			}
+			middleware.AssignmentQueueAttemptsTotal.WithLabelValues(result).Inc()
+			if err := q.repo.RecordFailure(ctx, p.ID, err.Error(), next); err != nil {
+				log.Printf("Assignment queue: failed to record attempt for order %s: %v", p.OrderID, err)
+			}
+		}
+	}
+
+	if stats, err := q.repo.Stats(ctx); err == nil {
+		middleware.AssignmentQueueDepth.Set(float64(stats.Depth))
+	}
+}
+
+// backoff is the wait before the next attempt after failed attempts.
+func (q *AssignmentQueue) backoff(failed int) time.Duration {
+	wait := q.cfg.BackoffBase
+	for i := 1; i < failed && wait < q.cfg.BackoffMax; i++ {
+		wait *= 2
+	}
+	return min(wait, q.cfg.BackoffMax)
+}
+
+func (q *AssignmentQueue) remove(ctx context.Context, p model.PendingAssignment) {
+	if _, err := q.repo.Delete(ctx, p.OrderID); err != nil {
+		log.Printf("Assignment queue: failed to remove order %s: %v", p.OrderID, err)
+	}
+}
+
+// Inspect returns the queue depth and its first limit waiting entries,
+// including those backing off.
+func (q *AssignmentQueue) Inspect(ctx context.Context, limit int) (AssignmentQueueSnapshot, error) {
+	if limit < 0 || limit > 1000 {
+		return AssignmentQueueSnapshot{}, ErrBadInput
+	}
+	if limit == 0 {
+		limit = 100
+	}
+
+	stats, err := q.repo.Stats(ctx)
+	if err != nil {
+		return AssignmentQueueSnapshot{}, err
+	}
+	items, err := q.repo.List(ctx, limit)
+	if err != nil {
+		return AssignmentQueueSnapshot{}, err
+	}
+
+	snapshot := AssignmentQueueSnapshot{Depth: stats.Depth, Failed: stats.Failed, Items: items}
+	if stats.OldestEnqueuedAt != nil {
+		snapshot.OldestWaitSecs = time.Since(*stats.OldestEnqueuedAt).Seconds()
+	}
+	middleware.AssignmentQueueDepth.Set(float64(stats.Depth))
+	return snapshot, nil
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPendingAssignmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockPendingAssignmentRepository) Enqueue(ctx context.Context, p *model.PendingAssignment) error {
+	args := m.Called(ctx, p)
+	return args.Error(0)
+}
+
+func (m *MockPendingAssignmentRepository) EnqueueTx(ctx context.Context, tx pgx.Tx, p *model.PendingAssignment) error {
+	args := m.Called(ctx, tx, p)
+	return args.Error(0)
+}
+
+func (m *MockPendingAssignmentRepository) List(ctx context.Context, limit int) ([]model.PendingAssignment, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]model.PendingAssignment), args.Error(1)
+}
+
+func (m *MockPendingAssignmentRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]model.PendingAssignment, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]model.PendingAssignment), args.Error(1)
+}
+
+func (m *MockPendingAssignmentRepository) Delete(ctx context.Context, orderID string) (bool, error) {
+	args := m.Called(ctx, orderID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPendingAssignmentRepository) RecordFailure(ctx context.Context, id int64, reason string, nextAttempt *time.Time) error {
+	args := m.Called(ctx, id, reason, nextAttempt)
+	return args.Error(0)
+}
+
+func (m *MockPendingAssignmentRepository) Stats(ctx context.Context) (model.AssignmentQueueStats, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(model.AssignmentQueueStats), args.Error(1)
+}
+
+type MockPendingAssigner struct {
+	mock.Mock
+}
+
+func (m *MockPendingAssigner) AssignPending(ctx context.Context, o model.ExternalOrder) error {
+	args := m.Called(ctx, o)
+	return args.Error(0)
+}
+
+func TestAssignmentQueue_ProcessOnce(t *testing.T) {
+	mockRepo := new(MockPendingAssignmentRepository)
+	mockAssigner := new(MockPendingAssigner)
+	enqueued := time.Now().Add(-time.Minute)
+	items := []model.PendingAssignment{
+		{ID: 1, OrderID: "o1", Order: model.ExternalOrder{ID: "o1"}, EnqueuedAt: enqueued},
+		{ID: 2, OrderID: "o2", Order: model.ExternalOrder{ID: "o2"}, EnqueuedAt: enqueued},
+		{ID: 3, OrderID: "o3", Order: model.ExternalOrder{ID: "o3"}, EnqueuedAt: enqueued},
+	}
+
+	fromQueue := mock.MatchedBy(func(ctx context.Context) bool {
+		return model.StatusChangeFrom(ctx).Actor == model.ActorAssignmentQueue
+	})
+	mockRepo.On("ListDue", mock.Anything, mock.Anything, 50).Return(items, nil)
+	mockAssigner.On("AssignPending", fromQueue, items[0].Order).Return(nil)
+	mockAssigner.On("AssignPending", fromQueue, items[1].Order).Return(ErrNoAvailableCourier)
+	mockAssigner.On("AssignPending", fromQueue, items[2].Order).Return(ErrOrderAlreadyAssigned)
+	mockRepo.On("Delete", mock.Anything, "o1").Return(true, nil)
+	mockRepo.On("RecordFailure", mock.Anything, int64(2), ErrNoAvailableCourier.Error(), mock.MatchedBy(func(next *time.Time) bool {
+		return next != nil && time.Until(*next) > 5*time.Second && time.Until(*next) <= 10*time.Second
+	})).Return(nil)
+	mockRepo.On("Delete", mock.Anything, "o3").Return(true, nil)
+	mockRepo.On("Stats", mock.Anything).Return(model.AssignmentQueueStats{Depth: 1}, nil)
+
+	q := NewAssignmentQueue(mockRepo, mockAssigner, new(MockLocker), AssignmentQueueConfig{})
+	q.processOnce(context.Background())
+
+	mockRepo.AssertExpectations(t)
+	mockAssigner.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, "o2")
+}
+
+func TestAssignmentQueue_Inspect(t *testing.T) {
+	mockRepo := new(MockPendingAssignmentRepository)
+	oldest := time.Now().Add(-2 * time.Minute)
+	items := []model.PendingAssignment{{ID: 1, OrderID: "o1", EnqueuedAt: oldest}}
+
+	mockRepo.On("Stats", mock.Anything).Return(model.AssignmentQueueStats{Depth: 1, OldestEnqueuedAt: &oldest}, nil)
+	mockRepo.On("List", mock.Anything, 100).Return(items, nil)
+
+	q := NewAssignmentQueue(mockRepo, new(MockPendingAssigner), new(MockLocker), AssignmentQueueConfig{})
+	snapshot, err := q.Inspect(context.Background(), 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, snapshot.Depth)
+	assert.Equal(t, items, snapshot.Items)
+	assert.GreaterOrEqual(t, snapshot.OldestWaitSecs, 120.0)
+
+	_, err = q.Inspect(context.Background(), -1)
+	assert.Equal(t, ErrBadInput, err)
+}
+
+func TestAssignmentQueue_WakeDoesNotBlock(t *testing.T) {
+	q := NewAssignmentQueue(new(MockPendingAssignmentRepository), new(MockPendingAssigner), new(MockLocker), AssignmentQueueConfig{})
+
+	q.Wake()
+	q.Wake()
+
+	assert.Len(t, q.wake, 1)
+}
+
+func TestAssignmentQueue_ProcessOnce_Offered(t *testing.T) {
+	mockRepo := new(MockPendingAssignmentRepository)
+	mockAssigner := new(MockPendingAssigner)
+	items := []model.PendingAssignment{{ID: 1, OrderID: "o1", Order: model.ExternalOrder{ID: "o1"}, EnqueuedAt: time.Now()}}
+
+	mockRepo.On("ListDue", mock.Anything, mock.Anything, 50).Return(items, nil)
+	mockAssigner.On("AssignPending", mock.Anything, items[0].Order).Return(ErrOfferPending)
+	mockRepo.On("Delete", mock.Anything, "o1").Return(true, nil)
+	mockRepo.On("Stats", mock.Anything).Return(model.AssignmentQueueStats{}, nil)
+
+	q := NewAssignmentQueue(mockRepo, mockAssigner, new(MockLocker), AssignmentQueueConfig{})
+	q.processOnce(context.Background())
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "RecordFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAssignmentQueue_ProcessOnce_GivesUp(t *testing.T) {
+	mockRepo := new(MockPendingAssignmentRepository)
+	mockAssigner := new(MockPendingAssigner)
+	items := []model.PendingAssignment{{ID: 1, OrderID: "o1", Order: model.ExternalOrder{ID: "o1"}, Attempts: 2, EnqueuedAt: time.Now()}}
+
+	mockRepo.On("ListDue", mock.Anything, mock.Anything, 50).Return(items, nil)
+	mockAssigner.On("AssignPending", mock.Anything, items[0].Order).Return(ErrNoCapableCourier)
+	mockRepo.On("RecordFailure", mock.Anything, int64(1), ErrNoCapableCourier.Error(), (*time.Time)(nil)).Return(nil)
+	mockRepo.On("Stats", mock.Anything).Return(model.AssignmentQueueStats{Failed: 1}, nil)
+
+	q := NewAssignmentQueue(mockRepo, mockAssigner, new(MockLocker), AssignmentQueueConfig{MaxAttempts: 3})
+	q.processOnce(context.Background())
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestAssignmentQueue_Backoff(t *testing.T) {
+	q := NewAssignmentQueue(nil, nil, nil, AssignmentQueueConfig{BackoffBase: 10 * time.Second, BackoffMax: time.Minute})
+
+	assert.Equal(t, 10*time.Second, q.backoff(1))
+	assert.Equal(t, 20*time.Second, q.backoff(2))
+	assert.Equal(t, 40*time.Second, q.backoff(3))
+	assert.Equal(t, time.Minute, q.backoff(4))
+	assert.Equal(t, time.Minute, q.backoff(30))
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
+)
+
+// CourierCapabilities describes what a courier can carry: the limits of the
+// transport type, the courier's own overrides and the result of applying them.
+type CourierCapabilities struct {
+	CourierID     int                   `json:"courier_id"`
+	TransportType string                `json:"transport_type"`
+	Defaults      model.TransportLimits `json:"defaults"`
+	Overrides     model.TransportLimits `json:"overrides"`
+	Effective     model.TransportLimits `json:"effective"`
+}
+
+type CapabilityUsecase interface {
+	Get(ctx context.Context, courierID int) (CourierCapabilities, error)
+	Set(ctx context.Context, courierID int, overrides model.TransportLimits) (CourierCapabilities, error)
+	Reset(ctx context.Context, courierID int) error
+}
+
+type capabilityUsecase struct {
+	repo        repository.CapabilityRepository
+	courierRepo repository.CourierRepository
+	limits      map[string]model.TransportLimits
+}
+
+func NewCapabilityUsecase(r repository.CapabilityRepository, cr repository.CourierRepository, limits map[string]model.TransportLimits) CapabilityUsecase {
+	if limits == nil {
+		limits = DefaultTransportLimits
+	}
+	return &capabilityUsecase{
+		repo:        r,
+		courierRepo: cr,
+		limits:      limits,
+	}
+}
+
+func (u *capabilityUsecase) Get(ctx context.Context, courierID int) (CourierCapabilities, error) {
+	if courierID <= 0 {
+		return CourierCapabilities{}, ErrBadInput
+	}
+	courier, err := u.courierRepo.GetByID(ctx, courierID)
+	if err != nil {
+		return CourierCapabilities{}, err
+	}
+
+	var overrides model.TransportLimits
+	stored, err := u.repo.Get(ctx, courierID)
+	switch {
+	case err == nil:
+		overrides = stored.Limits
+	case !errors.Is(err, repository.ErrNotFound):
+		return CourierCapabilities{}, err
+	}
+
+	return u.describe(courier, overrides), nil
+}
+
+func (u *capabilityUsecase) Set(ctx context.Context, courierID int, overrides model.TransportLimits) (CourierCapabilities, error) {
+	if courierID <= 0 || !overrides.Valid() {
+		return CourierCapabilities{}, ErrBadInput
+	}
+	courier, err := u.courierRepo.GetByID(ctx, courierID)
+	if err != nil {
+		return CourierCapabilities{}, err
+	}
+
+	if err := u.repo.Save(ctx, &model.CourierCapability{CourierID: courierID, Limits: overrides}); err != nil {
+		return CourierCapabilities{}, err
+	}
+	return u.describe(courier, overrides), nil
+}
+
+func (u *capabilityUsecase) Reset(ctx context.Context, courierID int) error {
+	if courierID <= 0 {
+		return ErrBadInput
+	}
+	return u.repo.Delete(ctx, courierID)
+}
+
+func (u *capabilityUsecase) describe(c model.Courier, overrides model.TransportLimits) CourierCapabilities {
+	defaults := u.limits[c.TransportType]
+	return CourierCapabilities{
+		CourierID:     c.ID,
+		TransportType: c.TransportType,
+		Defaults:      defaults,
+		Overrides:     overrides,
+		Effective:     defaults.Override(overrides),
+	}
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCapabilityRepository struct {
+	mock.Mock
+}
+
+func (m *MockCapabilityRepository) Get(ctx context.Context, courierID int) (model.CourierCapability, error) {
+	args := m.Called(ctx, courierID)
+	return args.Get(0).(model.CourierCapability), args.Error(1)
+}
+
+func (m *MockCapabilityRepository) Save(ctx context.Context, c *model.CourierCapability) error {
+	args := m.Called(ctx, c)
+	return args.Error(0)
+}
+
+func (m *MockCapabilityRepository) Delete(ctx context.Context, courierID int) error {
+	args := m.Called(ctx, courierID)
+	return args.Error(0)
+}
+
+func TestCapabilityUsecase_Get_AppliesOverrides(t *testing.T) {
+	mockRepo := new(MockCapabilityRepository)
+	mockCourierRepo := new(MockCourierRepository)
+	mockCourierRepo.On("GetByID", mock.Anything, 1).Return(model.Courier{ID: 1, TransportType: "on_foot"}, nil)
+	mockRepo.On("Get", mock.Anything, 1).Return(model.CourierCapability{CourierID: 1, Limits: model.TransportLimits{MaxWeightKg: 10}}, nil)
+
+	uc := NewCapabilityUsecase(mockRepo, mockCourierRepo, nil)
+	caps, err := uc.Get(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.TransportLimits{MaxWeightKg: 10, MaxVolumeL: 20, MaxDistanceKm: 3}, caps.Effective)
+}
+
+func TestCapabilityUsecase_Get_WithoutOverrides(t *testing.T) {
+	mockRepo := new(MockCapabilityRepository)
+	mockCourierRepo := new(MockCourierRepository)
+	mockCourierRepo.On("GetByID", mock.Anything, 1).Return(model.Courier{ID: 1, TransportType: "car"}, nil)
+	mockRepo.On("Get", mock.Anything, 1).Return(model.CourierCapability{}, repository.ErrNotFound)
+
+	uc := NewCapabilityUsecase(mockRepo, mockCourierRepo, nil)
+	caps, err := uc.Get(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultTransportLimits["car"], caps.Effective)
+}
+
+func TestCapabilityUsecase_Set_RejectsNegative(t *testing.T) {
+	mockRepo := new(MockCapabilityRepository)
+	uc := NewCapabilityUsecase(mockRepo, new(MockCourierRepository), nil)
+
+	_, err := uc.Set(context.Background(), 1, model.TransportLimits{MaxWeightKg: -1})
+
+	assert.Equal(t, ErrBadInput, err)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"avito-courier/internal/model"
+)
+
+// MaxImportRows bounds a single import request.
+const MaxImportRows = 10000
+
+// CourierImportRow is one parsed input row; Row is its 1-based position in the
+// uploaded file. ParseError is set when the row could not be decoded.
+type CourierImportRow struct {
+	Row        int
+	Courier    model.Courier
+	ParseError string
+}
+
+type CourierImportRowError struct {
+	Row   int    `json:"row"`
+	Field string `json:"field,omitempty"`
+	Error string `json:"error"`
+}
+
+type CourierImportResult struct {
+	Total    int                     `json:"total"`
+	Created  int                     `json:"created"`
+	Failed   int                     `json:"failed"`
+	Couriers []model.CourierDTO      `json:"couriers"`
+	Errors   []CourierImportRowError `json:"errors"`
+}
+
+// Import validates every row with the same rules as Create and inserts the
+// valid ones. In atomic mode nothing is inserted unless every row is valid
+// and can be stored; otherwise valid rows are created and the rest reported.
+func (u *courierUsecase) Import(ctx context.Context, rows []CourierImportRow, atomic bool) (CourierImportResult, error) {
+	if len(rows) == 0 || len(rows) > MaxImportRows {
+		return CourierImportResult{}, ErrBadInput
+	}
+
+	result := CourierImportResult{
+		Total:    len(rows),
+		Couriers: []model.CourierDTO{},
+		Errors:   []CourierImportRowError{},
+	}
+
+	valid := make([]*model.Courier, 0, len(rows))
+	validRows := make([]int, 0, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		if row.ParseError != "" {
+			result.Errors = append(result.Errors, CourierImportRowError{Row: row.Row, Error: row.ParseError})
+			continue
+		}
+		if field := u.validate(&row.Courier); field != "" {
+			result.Errors = append(result.Errors, CourierImportRowError{Row: row.Row, Field: field, Error: "invalid " + field})
+			continue
+		}
+		valid = append(valid, &row.Courier)
+		validRows = append(validRows, row.Row)
+	}
+
+	if len(valid) > 0 && (!atomic || len(result.Errors) == 0) {
+		ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: "courier imported"})
+		rowErrs, err := u.repo.CreateBatch(ctx, valid, atomic)
+		if err != nil {
+			return CourierImportResult{}, err
+		}
+
+		for i, rowErr := range rowErrs {
+			if rowErr == nil {
+				continue
+			}
+			msg := rowErr.Error()
+			if errors.Is(rowErr, ErrConflict) {
+				msg = "courier with this phone or employee ID already exists"
+			}
+			result.Errors = append(result.Errors, CourierImportRowError{Row: validRows[i], Error: msg})
+		}
+
+		if !atomic || len(result.Errors) == 0 {
+			for i, c := range valid {
+				if rowErrs[i] == nil {
+					result.Couriers = append(result.Couriers, model.ToDTO(*c))
+				}
+			}
+		}
+	}
+
+	result.Created = len(result.Couriers)
+	result.Failed = len(result.Errors)
+	return result, nil
+}
+
+// Export streams the couriers matching the filters of q to fn.
+func (u *courierUsecase) Export(ctx context.Context, q model.CourierQuery, fn func(model.Courier) error) error {
+	for _, status := range q.Statuses {
+		if !validStatus[status] {
+			return ErrBadInput
+		}
+	}
+	for _, transport := range q.TransportTypes {
+		if !u.transportTypes[transport] {
+			return ErrBadInput
+		}
+	}
+	return u.repo.ForEach(ctx, q, fn)
+}
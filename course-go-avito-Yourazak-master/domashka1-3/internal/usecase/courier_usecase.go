@@ -2,7 +2,10 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
+	"net/mail"
 	"strings"
+	"unicode"
 
 	"avito-courier/internal/model"
 	"avito-courier/internal/repository"
@@ -12,24 +15,41 @@ var (
 	ErrNotFound = repository.ErrNotFound
 	ErrConflict = repository.ErrConflict
 	ErrBadInput = repository.ErrBadInput
+
+	ErrVersionMismatch = repository.ErrVersionMismatch
 )
 
 type CourierUsecase interface {
 	GetByID(ctx context.Context, id int) (model.Courier, error)
 	GetAll(ctx context.Context) ([]model.Courier, error)
+	Search(ctx context.Context, q model.CourierQuery) (model.CourierPage, error)
 	Create(ctx context.Context, c *model.Courier) error
 	Update(ctx context.Context, c *model.Courier) error
+	Patch(ctx context.Context, id int, patch []byte, version int64) (model.Courier, error)
+	Import(ctx context.Context, rows []CourierImportRow, atomic bool) (CourierImportResult, error)
+	Export(ctx context.Context, q model.CourierQuery, fn func(model.Courier) error) error
 }
 
 type courierUsecase struct {
-	repo       repository.CourierRepository
-	deliveryUC *DeliveryUsecase
+	repo           repository.CourierRepository
+	deliveryUC     *DeliveryUsecase
+	transportTypes map[string]bool
 }
 
-func NewCourierUsecase(r repository.CourierRepository, deliveryUC *DeliveryUsecase) CourierUsecase {
+// NewCourierUsecase accepts the registry of allowed transport types; when it
+// is empty the built-in OnFoot, Scooter and Car are allowed.
+func NewCourierUsecase(r repository.CourierRepository, deliveryUC *DeliveryUsecase, transportTypes []string) CourierUsecase {
+	if len(transportTypes) == 0 {
+		transportTypes = []string{string(OnFoot), string(Scooter), string(Car)}
+	}
+	registry := make(map[string]bool, len(transportTypes))
+	for _, t := range transportTypes {
+		registry[t] = true
+	}
 	return &courierUsecase{
-		repo:       r,
-		deliveryUC: deliveryUC,
+		repo:           r,
+		deliveryUC:     deliveryUC,
+		transportTypes: registry,
 	}
 }
 
@@ -54,13 +74,61 @@ func (u *courierUsecase) GetAll(ctx context.Context) ([]model.Courier, error) {
 	return u.repo.GetAll(ctx)
 }
 
-func (u *courierUsecase) Create(ctx context.Context, c *model.Courier) error {
-	if strings.TrimSpace(c.Name) == "" || strings.TrimSpace(c.Phone) == "" || strings.TrimSpace(c.Status) == "" {
-		return ErrBadInput
+const (
+	defaultCourierPageSize = 50
+	maxCourierPageSize     = 500
+)
+
+// Search returns a page of couriers. One extra row is fetched to find out
+// whether a next page exists.
+func (u *courierUsecase) Search(ctx context.Context, q model.CourierQuery) (model.CourierPage, error) {
+	if q.SortBy == "" {
+		q.SortBy = model.CourierSortID
 	}
-	if !validStatus[c.Status] {
+	if q.Limit == 0 {
+		q.Limit = defaultCourierPageSize
+	}
+	if q.Limit < 0 || q.Limit > maxCourierPageSize || q.Offset < 0 {
+		return model.CourierPage{}, ErrBadInput
+	}
+	if q.After != nil && (q.Offset > 0 || q.After.SortBy != q.SortBy || q.After.Desc != q.Desc) {
+		return model.CourierPage{}, ErrBadInput
+	}
+	if !q.CreatedFrom.IsZero() && !q.CreatedTo.IsZero() && !q.CreatedTo.After(q.CreatedFrom) {
+		return model.CourierPage{}, ErrBadInput
+	}
+	for _, status := range q.Statuses {
+		if !validStatus[status] {
+			return model.CourierPage{}, ErrBadInput
+		}
+	}
+	for _, transport := range q.TransportTypes {
+		if !u.transportTypes[transport] {
+			return model.CourierPage{}, ErrBadInput
+		}
+	}
+
+	limit := q.Limit
+	q.Limit++
+	couriers, total, err := u.repo.Search(ctx, q)
+	if err != nil {
+		return model.CourierPage{}, err
+	}
+
+	page := model.CourierPage{Items: couriers, Total: total}
+	if len(couriers) > limit {
+		page.Items = couriers[:limit]
+		next := model.NewCourierCursor(page.Items[limit-1], q.SortBy, q.Desc)
+		page.Next = &next
+	}
+	return page, nil
+}
+
+func (u *courierUsecase) Create(ctx context.Context, c *model.Courier) error {
+	if field := u.validate(c); field != "" {
 		return ErrBadInput
 	}
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: "courier created"})
 	if err := u.repo.Create(ctx, c); err != nil {
 		return err
 	}
@@ -72,14 +140,126 @@ func (u *courierUsecase) Update(ctx context.Context, c *model.Courier) error {
 		return ErrBadInput
 	}
 
-	if strings.TrimSpace(c.Phone) == "" || strings.TrimSpace(c.Name) == "" || strings.TrimSpace(c.Status) == "" {
-		return ErrBadInput
-	}
-	if !validStatus[c.Status] {
+	if field := u.validate(c); field != "" {
 		return ErrBadInput
 	}
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: "courier updated"})
 	if err := u.repo.Update(ctx, c); err != nil {
 		return err
 	}
 	return nil
 }
+
+// Patch applies a JSON Merge Patch to the courier's DTO representation. A
+// non-zero version must match the stored one. The update itself is checked
+// against the version that was read, so a concurrent write in between also
+// yields ErrVersionMismatch instead of being overwritten.
+func (u *courierUsecase) Patch(ctx context.Context, id int, patch []byte, version int64) (model.Courier, error) {
+	if id <= 0 {
+		return model.Courier{}, ErrBadInput
+	}
+
+	current, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return model.Courier{}, err
+	}
+	if version > 0 && current.Version != version {
+		return model.Courier{}, ErrVersionMismatch
+	}
+
+	doc, err := json.Marshal(model.ToDTO(current))
+	if err != nil {
+		return model.Courier{}, err
+	}
+	merged, err := applyMergePatch(doc, patch)
+	if err != nil {
+		return model.Courier{}, ErrBadInput
+	}
+	var dto model.CourierDTO
+	if err := json.Unmarshal(merged, &dto); err != nil {
+		return model.Courier{}, ErrBadInput
+	}
+
+	c := model.FromDTO(dto)
+	c.ID = id
+	c.Version = current.Version
+	if err := u.Update(ctx, &c); err != nil {
+		return model.Courier{}, err
+	}
+	return c, nil
+}
+
+// validate checks the courier and brings its fields to their stored form:
+// E.164 phone, default transport type, lower-case email, upper-case plate.
+// It returns the name of the first invalid field, or "" if all are valid.
+func (u *courierUsecase) validate(c *model.Courier) string {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return "name"
+	}
+
+	phone, ok := model.NormalizePhone(c.Phone)
+	if !ok {
+		return "phone"
+	}
+	c.Phone = phone
+
+	c.Status = strings.TrimSpace(c.Status)
+	if !validStatus[c.Status] {
+		return "status"
+	}
+
+	c.TransportType = strings.TrimSpace(c.TransportType)
+	if c.TransportType == "" {
+		c.TransportType = string(OnFoot)
+	}
+	if !u.transportTypes[c.TransportType] {
+		return "transport_type"
+	}
+
+	c.Email = strings.ToLower(strings.TrimSpace(c.Email))
+	if c.Email != "" {
+		addr, err := mail.ParseAddress(c.Email)
+		if err != nil || addr.Address != c.Email {
+			return "email"
+		}
+	}
+
+	plate, ok := normalizePlate(c.VehiclePlate)
+	if !ok {
+		return "vehicle_plate"
+	}
+	c.VehiclePlate = plate
+
+	if c.Rating != nil && (*c.Rating < 0 || *c.Rating > 5) {
+		return "rating"
+	}
+
+	c.ExternalEmployeeID = strings.TrimSpace(c.ExternalEmployeeID)
+	if len(c.ExternalEmployeeID) > 64 {
+		return "external_employee_id"
+	}
+	return ""
+}
+
+// normalizePlate upper-cases the plate and drops spaces and dashes. Only
+// letters and digits are allowed, 2 to 12 of them.
+func normalizePlate(s string) (string, bool) {
+	var b strings.Builder
+	n := 0
+	for _, r := range strings.TrimSpace(s) {
+		switch {
+		case r == ' ' || r == '-':
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToUpper(r))
+			n++
+		default:
+			return "", false
+		}
+	}
+	if n != 0 && (n < 2 || n > 12) {
+		return "", false
+	}
+	return b.String(), true
+}
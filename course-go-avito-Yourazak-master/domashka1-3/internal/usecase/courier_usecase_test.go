@@ -3,10 +3,12 @@ package usecase
 import (
 	"context"
 	"testing"
+	"time"
 
 	"avito-courier/internal/model"
 	"avito-courier/internal/repository"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -20,6 +22,24 @@ func (m *MockCourierRepository) GetByID(ctx context.Context, id int) (model.Cour
 	return args.Get(0).(model.Courier), args.Error(1)
 }
 
+func (m *MockCourierRepository) Search(ctx context.Context, q model.CourierQuery) ([]model.Courier, int, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).([]model.Courier), args.Int(1), args.Error(2)
+}
+
+func (m *MockCourierRepository) ForEach(ctx context.Context, q model.CourierQuery, fn func(model.Courier) error) error {
+	args := m.Called(ctx, q, fn)
+	return args.Error(0)
+}
+
+func (m *MockCourierRepository) CreateBatch(ctx context.Context, couriers []*model.Courier, atomic bool) ([]error, error) {
+	args := m.Called(ctx, couriers, atomic)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]error), args.Error(1)
+}
+
 func (m *MockCourierRepository) GetAll(ctx context.Context) ([]model.Courier, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]model.Courier), args.Error(1)
@@ -45,9 +65,29 @@ func (m *MockCourierRepository) UpdateStatus(ctx context.Context, id int, status
 	return args.Error(0)
 }
 
+func (m *MockCourierRepository) GetByIDForUpdateTx(ctx context.Context, tx pgx.Tx, id int) (model.Courier, error) {
+	args := m.Called(ctx, tx, id)
+	return args.Get(0).(model.Courier), args.Error(1)
+}
+
+func (m *MockCourierRepository) UpdateStatusTx(ctx context.Context, tx pgx.Tx, id int, status string) error {
+	args := m.Called(ctx, tx, id, status)
+	return args.Error(0)
+}
+
+func (m *MockCourierRepository) ReleaseOverdue(ctx context.Context) ([]int, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockCourierRepository) FindCandidatesTx(ctx context.Context, tx pgx.Tx, f model.CandidateFilter) ([]model.CourierCandidate, error) {
+	args := m.Called(ctx, tx, f)
+	return args.Get(0).([]model.CourierCandidate), args.Error(1)
+}
+
 func TestCourierService_GetByID_Success(t *testing.T) {
 	mockRepo := new(MockCourierRepository)
-	service := NewCourierUsecase(mockRepo)
+	service := NewCourierUsecase(mockRepo, nil, nil)
 
 	expectedCourier := model.Courier{
 		ID: 1, Name: "John", Phone: "+79123456789", Status: "available",
@@ -64,7 +104,7 @@ func TestCourierService_GetByID_Success(t *testing.T) {
 
 func TestCourierService_GetByID_NotFound(t *testing.T) {
 	mockRepo := new(MockCourierRepository)
-	service := NewCourierUsecase(mockRepo)
+	service := NewCourierUsecase(mockRepo, nil, nil)
 
 	mockRepo.On("GetByID", mock.Anything, 999).Return(model.Courier{}, repository.ErrNotFound)
 
@@ -77,7 +117,7 @@ func TestCourierService_GetByID_NotFound(t *testing.T) {
 
 func TestCourierService_GetByID_InvalidID(t *testing.T) {
 	mockRepo := new(MockCourierRepository)
-	service := NewCourierUsecase(mockRepo)
+	service := NewCourierUsecase(mockRepo, nil, nil)
 
 	courier, err := service.GetByID(context.Background(), 0)
 
@@ -89,7 +129,7 @@ func TestCourierService_GetByID_InvalidID(t *testing.T) {
 
 func TestCourierService_GetAll_Success(t *testing.T) {
 	mockRepo := new(MockCourierRepository)
-	service := NewCourierUsecase(mockRepo)
+	service := NewCourierUsecase(mockRepo, nil, nil)
 
 	expectedCouriers := []model.Courier{
 		{ID: 1, Name: "John", Phone: "+79123456789", Status: "available"},
@@ -107,7 +147,7 @@ func TestCourierService_GetAll_Success(t *testing.T) {
 
 func TestCourierService_Create_Success(t *testing.T) {
 	mockRepo := new(MockCourierRepository)
-	service := NewCourierUsecase(mockRepo)
+	service := NewCourierUsecase(mockRepo, nil, nil)
 
 	courier := &model.Courier{
 		Name:          "New Courier",
@@ -126,7 +166,7 @@ func TestCourierService_Create_Success(t *testing.T) {
 
 func TestCourierService_Create_InvalidData(t *testing.T) {
 	mockRepo := new(MockCourierRepository)
-	service := NewCourierUsecase(mockRepo)
+	service := NewCourierUsecase(mockRepo, nil, nil)
 
 	testCases := []struct {
 		name    string
@@ -149,7 +189,7 @@ func TestCourierService_Create_InvalidData(t *testing.T) {
 
 func TestCourierService_Update_Success(t *testing.T) {
 	mockRepo := new(MockCourierRepository)
-	service := NewCourierUsecase(mockRepo)
+	service := NewCourierUsecase(mockRepo, nil, nil)
 
 	courier := &model.Courier{
 		ID: 1, Name: "Updated Courier", Phone: "+79123456789", Status: "available",
@@ -165,7 +205,7 @@ func TestCourierService_Update_Success(t *testing.T) {
 
 func TestCourierService_Update_InvalidID(t *testing.T) {
 	mockRepo := new(MockCourierRepository)
-	service := NewCourierUsecase(mockRepo)
+	service := NewCourierUsecase(mockRepo, nil, nil)
 
 	courier := &model.Courier{
 		ID: 0, Name: "Updated Courier", Phone: "+79123456789", Status: "available",
@@ -177,3 +217,228 @@ func TestCourierService_Update_InvalidID(t *testing.T) {
 	assert.Equal(t, ErrBadInput, err)
 	mockRepo.AssertNotCalled(t, "Update")
 }
+
+func TestCourierService_Create_NormalizesProfile(t *testing.T) {
+	mockRepo := new(MockCourierRepository)
+	service := NewCourierUsecase(mockRepo, nil, nil)
+	rating := 4.5
+
+	courier := &model.Courier{
+		Name:               " New Courier ",
+		Phone:              "8 (912) 345-67-89",
+		Status:             "available",
+		Email:              " Courier@Example.com ",
+		VehiclePlate:       "a 123-bc 77",
+		Rating:             &rating,
+		ExternalEmployeeID: " emp-42 ",
+	}
+
+	mockRepo.On("Create", mock.Anything, courier).Return(nil)
+
+	err := service.Create(context.Background(), courier)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "New Courier", courier.Name)
+	assert.Equal(t, "+79123456789", courier.Phone)
+	assert.Equal(t, "on_foot", courier.TransportType)
+	assert.Equal(t, "courier@example.com", courier.Email)
+	assert.Equal(t, "A123BC77", courier.VehiclePlate)
+	assert.Equal(t, "emp-42", courier.ExternalEmployeeID)
+}
+
+func TestCourierService_Create_InvalidProfile(t *testing.T) {
+	mockRepo := new(MockCourierRepository)
+	service := NewCourierUsecase(mockRepo, nil, []string{"on_foot", "bike"})
+	tooHigh := 5.5
+
+	testCases := []struct {
+		name    string
+		courier *model.Courier
+	}{
+		{"Invalid Phone", &model.Courier{Name: "John", Phone: "12345", Status: "available"}},
+		{"Unknown Transport", &model.Courier{Name: "John", Phone: "+79123456789", Status: "available", TransportType: "car"}},
+		{"Invalid Email", &model.Courier{Name: "John", Phone: "+79123456789", Status: "available", Email: "john at example"}},
+		{"Invalid Plate", &model.Courier{Name: "John", Phone: "+79123456789", Status: "available", VehiclePlate: "A1#"}},
+		{"Invalid Rating", &model.Courier{Name: "John", Phone: "+79123456789", Status: "available", Rating: &tooHigh}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, ErrBadInput, service.Create(context.Background(), tc.courier))
+		})
+	}
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCourierService_Patch_MergesFields(t *testing.T) {
+	mockRepo := new(MockCourierRepository)
+	service := NewCourierUsecase(mockRepo, nil, nil)
+	rating := 4.0
+
+	mockRepo.On("GetByID", mock.Anything, 1).Return(model.Courier{
+		ID: 1, Name: "John", Phone: "+79123456789", Status: "available",
+		TransportType: "car", Email: "john@example.com", Rating: &rating, Version: 3,
+	}, nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	courier, err := service.Patch(context.Background(), 1, []byte(`{"status":"paused","email":null}`), 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "John", courier.Name)
+	assert.Equal(t, "paused", courier.Status)
+	assert.Equal(t, "car", courier.TransportType)
+	assert.Empty(t, courier.Email)
+	assert.Equal(t, &rating, courier.Rating)
+	mockRepo.AssertCalled(t, "Update", mock.Anything, mock.MatchedBy(func(c *model.Courier) bool {
+		return c.ID == 1 && c.Version == 3
+	}))
+}
+
+func TestCourierService_Patch_StaleVersion(t *testing.T) {
+	mockRepo := new(MockCourierRepository)
+	service := NewCourierUsecase(mockRepo, nil, nil)
+
+	mockRepo.On("GetByID", mock.Anything, 1).Return(model.Courier{
+		ID: 1, Name: "John", Phone: "+79123456789", Status: "available", Version: 4,
+	}, nil)
+
+	_, err := service.Patch(context.Background(), 1, []byte(`{"name":"Jack"}`), 3)
+
+	assert.Equal(t, ErrVersionMismatch, err)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestCourierService_Patch_InvalidPatch(t *testing.T) {
+	mockRepo := new(MockCourierRepository)
+	service := NewCourierUsecase(mockRepo, nil, nil)
+
+	mockRepo.On("GetByID", mock.Anything, 1).Return(model.Courier{
+		ID: 1, Name: "John", Phone: "+79123456789", Status: "available", Version: 1,
+	}, nil)
+
+	for _, patch := range []string{`{`, `["x"]`, `{"status":"sleeping"}`, `{"name":null}`} {
+		_, err := service.Patch(context.Background(), 1, []byte(patch), 0)
+		assert.Equal(t, ErrBadInput, err, patch)
+	}
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestCourierService_Search_ReturnsNextCursor(t *testing.T) {
+	mockRepo := new(MockCourierRepository)
+	service := NewCourierUsecase(mockRepo, nil, nil)
+
+	mockRepo.On("Search", mock.Anything, mock.MatchedBy(func(q model.CourierQuery) bool {
+		return q.Limit == 3 && q.SortBy == model.CourierSortName
+	})).Return([]model.Courier{
+		{ID: 5, Name: "Anna"},
+		{ID: 2, Name: "Boris"},
+		{ID: 9, Name: "Clara"},
+	}, 7, nil)
+
+	page, err := service.Search(context.Background(), model.CourierQuery{SortBy: model.CourierSortName, Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, page.Total)
+	assert.Len(t, page.Items, 2)
+	assert.Equal(t, &model.CourierCursor{SortBy: model.CourierSortName, Value: "Boris", ID: 2}, page.Next)
+}
+
+func TestCourierService_Search_LastPage(t *testing.T) {
+	mockRepo := new(MockCourierRepository)
+	service := NewCourierUsecase(mockRepo, nil, nil)
+
+	mockRepo.On("Search", mock.Anything, mock.Anything).Return([]model.Courier{{ID: 1}}, 1, nil)
+
+	page, err := service.Search(context.Background(), model.CourierQuery{})
+
+	assert.NoError(t, err)
+	assert.Nil(t, page.Next)
+	mockRepo.AssertCalled(t, "Search", mock.Anything, mock.MatchedBy(func(q model.CourierQuery) bool {
+		return q.Limit == defaultCourierPageSize+1 && q.SortBy == model.CourierSortID
+	}))
+}
+
+func TestCourierService_Search_InvalidQuery(t *testing.T) {
+	mockRepo := new(MockCourierRepository)
+	service := NewCourierUsecase(mockRepo, nil, nil)
+	now := time.Now()
+
+	queries := []model.CourierQuery{
+		{Limit: maxCourierPageSize + 1},
+		{Offset: -1},
+		{Statuses: []string{"sleeping"}},
+		{TransportTypes: []string{"rocket"}},
+		{CreatedFrom: now, CreatedTo: now.Add(-time.Hour)},
+		{Offset: 10, After: &model.CourierCursor{SortBy: model.CourierSortID, ID: 1}},
+		{SortBy: model.CourierSortName, After: &model.CourierCursor{SortBy: model.CourierSortID, ID: 1}},
+	}
+	for _, q := range queries {
+		_, err := service.Search(context.Background(), q)
+		assert.Equal(t, ErrBadInput, err)
+	}
+	mockRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+}
+
+func TestCourierService_Import_AtomicStopsOnInvalidRow(t *testing.T) {
+	mockRepo := new(MockCourierRepository)
+	service := NewCourierUsecase(mockRepo, nil, nil)
+
+	rows := []CourierImportRow{
+		{Row: 1, Courier: model.Courier{Name: "John", Phone: "+79123456789", Status: "available"}},
+		{Row: 2, Courier: model.Courier{Name: "Jane", Phone: "123", Status: "available"}},
+		{Row: 3, ParseError: "invalid JSON"},
+	}
+
+	result, err := service.Import(context.Background(), rows, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Created)
+	assert.Equal(t, []CourierImportRowError{
+		{Row: 2, Field: "phone", Error: "invalid phone"},
+		{Row: 3, Error: "invalid JSON"},
+	}, result.Errors)
+	mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCourierService_Import_BestEffort(t *testing.T) {
+	mockRepo := new(MockCourierRepository)
+	service := NewCourierUsecase(mockRepo, nil, nil)
+
+	rows := []CourierImportRow{
+		{Row: 1, Courier: model.Courier{Name: "John", Phone: "+79123456789", Status: "available"}},
+		{Row: 2, Courier: model.Courier{Name: "Jane", Phone: "+79123456780", Status: "flying"}},
+		{Row: 3, Courier: model.Courier{Name: "Jack", Phone: "+79123456781", Status: "paused"}},
+	}
+	mockRepo.On("CreateBatch", mock.Anything, mock.MatchedBy(func(cs []*model.Courier) bool {
+		return len(cs) == 2
+	}), false).Return([]error{nil, repository.ErrConflict}, nil)
+
+	result, err := service.Import(context.Background(), rows, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 2, result.Failed)
+	assert.Equal(t, "John", result.Couriers[0].Name)
+	assert.Equal(t, 2, result.Errors[0].Row)
+	assert.Equal(t, "status", result.Errors[0].Field)
+	assert.Equal(t, 3, result.Errors[1].Row)
+}
+
+func TestCourierService_Import_AtomicConflictCreatesNothing(t *testing.T) {
+	mockRepo := new(MockCourierRepository)
+	service := NewCourierUsecase(mockRepo, nil, nil)
+
+	rows := []CourierImportRow{
+		{Row: 1, Courier: model.Courier{Name: "John", Phone: "+79123456789", Status: "available"}},
+		{Row: 2, Courier: model.Courier{Name: "Jane", Phone: "+79123456789", Status: "available"}},
+	}
+	mockRepo.On("CreateBatch", mock.Anything, mock.Anything, true).Return([]error{nil, repository.ErrConflict}, nil)
+
+	result, err := service.Import(context.Background(), rows, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Created)
+	assert.Empty(t, result.Couriers)
+	assert.Equal(t, 2, result.Errors[0].Row)
+}
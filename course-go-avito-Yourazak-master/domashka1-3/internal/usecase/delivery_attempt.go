@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrReturnNotFailable = errors.New("return delivery cannot fail")
+
+// FailureRequest is what the courier app sends when an order could not be
+// handed over. Comment is required for FailureOther. A non-zero CourierID
+// restricts it to that courier's own deliveries.
+type FailureRequest struct {
+	CourierID int
+	Reason    string
+	Comment   string
+	Location  *model.GeoPoint
+}
+
+func validFailure(req FailureRequest) bool {
+	if !model.ValidFailureReason(req.Reason) {
+		return false
+	}
+	if req.Reason == model.FailureOther && strings.TrimSpace(req.Comment) == "" {
+		return false
+	}
+	return req.Location == nil || req.Location.Valid()
+}
+
+// Fail records a failed handover of an active delivery. While attempts are
+// left and the reason allows another one, the courier is released and the
+// order goes back to the assignment queue in the same transaction.
+// Otherwise the courier keeps the order and takes it back to the pickup
+// point as a return delivery, which is returned as well.
+func (u *DeliveryUsecase) Fail(ctx context.Context, deliveryID int, req FailureRequest) (model.DeliveryAttempt, *model.Delivery, error) {
+	if deliveryID <= 0 || req.CourierID < 0 || !validFailure(req) {
+		return model.DeliveryAttempt{}, nil, ErrBadInput
+	}
+
+	tx, err := u.pool.Begin(ctx)
+	if err != nil {
+		return model.DeliveryAttempt{}, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	delivery, err := u.lockOwnDeliveryTx(ctx, tx, deliveryID, req.CourierID)
+	if err != nil {
+		return model.DeliveryAttempt{}, nil, err
+	}
+	if !activeDelivery(delivery.Status) {
+		return model.DeliveryAttempt{}, nil, ErrDeliveryNotActive
+	}
+	if delivery.Kind == model.DeliveryKindReturn {
+		return model.DeliveryAttempt{}, nil, ErrReturnNotFailable
+	}
+
+	failed, err := u.deliveryRepo.CountAttemptsTx(ctx, tx, delivery.OrderID)
+	if err != nil {
+		return model.DeliveryAttempt{}, nil, err
+	}
+	attempt := model.DeliveryAttempt{
+		OrderID:    delivery.OrderID,
+		DeliveryID: delivery.ID,
+		CourierID:  delivery.CourierID,
+		Attempt:    failed + 1,
+		Reason:     req.Reason,
+		Comment:    req.Comment,
+		Outcome:    model.AttemptRetry,
+		Location:   req.Location,
+	}
+	if !model.FailureRetryable(req.Reason) || attempt.Attempt >= u.cfg.MaxAttempts {
+		attempt.Outcome = model.AttemptReturn
+	}
+
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: "delivery failed: " + req.Reason, OrderID: delivery.OrderID})
+	if err := u.deliveryRepo.RecordAttemptTx(ctx, tx, &attempt); err != nil {
+		return model.DeliveryAttempt{}, nil, err
+	}
+	if _, err := u.deliveryRepo.DeleteByOrderIDTx(ctx, tx, delivery.OrderID); err != nil {
+		return model.DeliveryAttempt{}, nil, err
+	}
+
+	// The booked slot is missed either way, so the window is not carried
+	// over to the next attempt.
+	o := u.enrichOrder(ctx, model.ExternalOrder{ID: delivery.OrderID, Priority: delivery.Priority, PINHash: delivery.PINHash, MerchantID: delivery.MerchantID})
+
+	if attempt.Outcome == model.AttemptRetry {
+		if err := u.releaseCourierTx(ctx, tx, delivery); err != nil {
+			return model.DeliveryAttempt{}, nil, err
+		}
+		if err := u.enqueueTx(ctx, tx, o); err != nil {
+			return model.DeliveryAttempt{}, nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return model.DeliveryAttempt{}, nil, err
+		}
+		log.Printf("Order %s queued for attempt %d", o.ID, attempt.Attempt+1)
+		return attempt, nil, nil
+	}
+
+	ret, err := u.createReturnTx(ctx, tx, delivery, o, req.Location)
+	if err != nil {
+		return model.DeliveryAttempt{}, nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.DeliveryAttempt{}, nil, err
+	}
+	return attempt, ret, nil
+}
+
+// createReturnTx hands the order back to the courier who failed to deliver
+// it, now bound for the pickup point. The courier stays busy, and stays on
+// its route, until the return is completed.
+func (u *DeliveryUsecase) createReturnTx(ctx context.Context, tx pgx.Tx, failed model.Delivery, o model.ExternalOrder, at *model.GeoPoint) (*model.Delivery, error) {
+	courier, err := u.courierRepo.GetByIDForUpdateTx(ctx, tx, failed.CourierID)
+	if err != nil {
+		return nil, err
+	}
+
+	back := model.ExternalOrder{ID: o.ID, Priority: o.Priority, Pickup: o.Dropoff, Dropoff: o.Pickup, MerchantID: o.MerchantID}
+	if at != nil {
+		back.Pickup = at
+	}
+
+	now := time.Now().UTC()
+	ret := &model.Delivery{
+		CourierID:  courier.ID,
+		OrderID:    o.ID,
+		AssignedAt: now,
+		Deadline:   u.factory.DeadlineFor(now, courier.TransportType, back),
+		Priority:   o.Priority,
+		Kind:       model.DeliveryKindReturn,
+		RouteID:    failed.RouteID,
+		ETA:        u.dropoffETA(now, courier.TransportType, back),
+		MerchantID: o.MerchantID,
+	}
+	if err := u.deliveryRepo.CreateTx(ctx, tx, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"avito-courier/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryUsecase_Fail_BadInput(t *testing.T) {
+	u := NewDeliveryUsecase(nil, nil, nil, nil, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+
+	cases := map[string]struct {
+		id  int
+		req FailureRequest
+	}{
+		"zero id":            {0, FailureRequest{Reason: model.FailureCustomerAbsent}},
+		"unknown reason":     {1, FailureRequest{Reason: "lost"}},
+		"other without note": {1, FailureRequest{Reason: model.FailureOther, Comment: "  "}},
+		"invalid location":   {1, FailureRequest{Reason: model.FailureNoAccess, Location: &model.GeoPoint{Lon: 181}}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, ret, err := u.Fail(context.Background(), tc.id, tc.req)
+			assert.Equal(t, ErrBadInput, err)
+			assert.Nil(t, ret)
+		})
+	}
+}
+
+func TestNewDeliveryUsecase_DefaultMaxAttempts(t *testing.T) {
+	u := NewDeliveryUsecase(nil, nil, nil, nil, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+	assert.Equal(t, 3, u.cfg.MaxAttempts)
+
+	u = NewDeliveryUsecase(nil, nil, nil, nil, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{MaxAttempts: 1})
+	assert.Equal(t, 1, u.cfg.MaxAttempts)
+}
@@ -1,6 +1,10 @@
 package usecase
 
-import "time"
+import (
+	"time"
+
+	"avito-courier/internal/model"
+)
 
 type TransportType string
 
@@ -10,19 +14,47 @@ const (
 	Car     TransportType = "car"
 )
 
+// DefaultTransportLimits is used when no limits are configured.
+var DefaultTransportLimits = map[string]model.TransportLimits{
+	string(OnFoot):  {MaxWeightKg: 5, MaxVolumeL: 20, MaxDistanceKm: 3},
+	string(Scooter): {MaxWeightKg: 15, MaxVolumeL: 60, MaxDistanceKm: 15},
+	string(Car):     {MaxWeightKg: 50, MaxVolumeL: 500},
+}
+
 type DeliveryTimeFactory struct{}
 
 func NewDeliveryTimeFactory() *DeliveryTimeFactory {
 	return &DeliveryTimeFactory{}
 }
 
-func (f *DeliveryTimeFactory) Deadline(now time.Time, transport string) time.Time {
+// Deadline gives the delivery window for the transport type, halved for
+// express orders and doubled for scheduled ones.
+func (f *DeliveryTimeFactory) Deadline(now time.Time, transport, priority string) time.Time {
+	var window time.Duration
 	switch transport {
 	case string(Scooter):
-		return now.Add(15 * time.Minute)
+		window = 15 * time.Minute
 	case string(Car):
-		return now.Add(5 * time.Minute)
+		window = 5 * time.Minute
 	default:
-		return now.Add(30 * time.Minute)
+		window = 30 * time.Minute
+	}
+
+	switch model.NormalizePriority(priority) {
+	case model.PriorityExpress:
+		window /= 2
+	case model.PriorityScheduled:
+		window *= 2
+	}
+	return now.Add(window)
+}
+
+// DeadlineFor is Deadline for a concrete order. A booked slot is a promise
+// to the customer, so its end is the deadline, whether it comes before or
+// after the regular window.
+func (f *DeliveryTimeFactory) DeadlineFor(now time.Time, transport string, o model.ExternalOrder) time.Time {
+	if o.WindowEnd != nil {
+		return *o.WindowEnd
 	}
+	return f.Deadline(now, transport, o.Priority)
 }
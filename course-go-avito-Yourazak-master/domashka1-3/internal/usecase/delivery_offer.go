@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"avito-courier/internal/middleware"
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrOfferPending    = errors.New("offer pending")
+	ErrOfferNotPending = errors.New("offer is no longer pending")
+)
+
+func (u *DeliveryUsecase) offering() bool {
+	return u.cfg.OfferMode && u.offers != nil
+}
+
+// offer proposes the order to the best courier who has not been offered it
+// recently. It returns ErrOfferPending once the offer is out; the courier is
+// reserved, but stays available, until it is answered or expires.
+func (u *DeliveryUsecase) offer(ctx context.Context, o model.ExternalOrder) error {
+	tx, err := u.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	exists, err := u.deliveryRepo.CheckOrderExistsTx(ctx, tx, o.ID)
+	if err != nil {
+		return err
+	}
+	pending, err := u.offers.PendingForOrderTx(ctx, tx, o.ID)
+	if err != nil {
+		return err
+	}
+	if exists || pending {
+		return ErrOrderAlreadyAssigned
+	}
+
+	now := time.Now().UTC()
+	exclude, err := u.offers.OfferedCouriersTx(ctx, tx, o.ID, now.Add(-u.cfg.OfferCooldown))
+	if err != nil {
+		return err
+	}
+	courier, err := u.selectCourierTx(ctx, tx, o, exclude)
+	if err != nil {
+		return err
+	}
+
+	offer := model.DeliveryOffer{OrderID: o.ID, CourierID: courier.ID, Order: o, ExpiresAt: now.Add(u.cfg.OfferTTL)}
+	if err := u.offers.CreateTx(ctx, tx, &offer); err != nil {
+		if errors.Is(err, ErrConflict) {
+			// Another replica offered the order or reserved the courier
+			// first; the order is retried from the queue.
+			return ErrNoAvailableCourier
+		}
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	middleware.CourierOffersTotal.WithLabelValues(model.OfferPending).Inc()
+	log.Printf("Order %s offered to courier %d until %s", o.ID, courier.ID, offer.ExpiresAt.Format(time.RFC3339))
+	return ErrOfferPending
+}
+
+// offerOrQueue offers the order, or puts it in the assignment queue when
+// there is nobody left to offer it to. An order that is already offered or
+// assigned is left alone.
+func (u *DeliveryUsecase) offerOrQueue(ctx context.Context, o model.ExternalOrder) error {
+	err := u.offer(ctx, o)
+	switch {
+	case errors.Is(err, ErrOfferPending), errors.Is(err, ErrOrderAlreadyAssigned):
+		return nil
+	case u.queueable(err):
+		return u.enqueue(ctx, o)
+	}
+	return err
+}
+
+// lockOwnOfferTx locks a pending offer made to the courier. Offers of other
+// couriers are reported as not found.
+func (u *DeliveryUsecase) lockOwnOfferTx(ctx context.Context, tx pgx.Tx, courierID int, offerID int64) (model.DeliveryOffer, error) {
+	offer, err := u.offers.GetForUpdateTx(ctx, tx, offerID)
+	if err != nil {
+		return model.DeliveryOffer{}, err
+	}
+	if offer.CourierID != courierID {
+		return model.DeliveryOffer{}, ErrNotFound
+	}
+	if offer.State != model.OfferPending || !time.Now().Before(offer.ExpiresAt) {
+		return model.DeliveryOffer{}, ErrOfferNotPending
+	}
+	return offer, nil
+}
+
+// AcceptOffer turns the courier's pending offer into a delivery, the same
+// way a forced assignment would.
+func (u *DeliveryUsecase) AcceptOffer(ctx context.Context, courierID int, offerID int64) (model.Delivery, error) {
+	if courierID <= 0 || offerID <= 0 || u.offers == nil {
+		return model.Delivery{}, ErrBadInput
+	}
+
+	tx, err := u.pool.Begin(ctx)
+	if err != nil {
+		return model.Delivery{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	offer, err := u.lockOwnOfferTx(ctx, tx, courierID, offerID)
+	if err != nil {
+		return model.Delivery{}, err
+	}
+
+	exists, err := u.deliveryRepo.CheckOrderExistsTx(ctx, tx, offer.OrderID)
+	if err != nil {
+		return model.Delivery{}, err
+	}
+	if exists {
+		// A dispatcher assigned the order by hand in the meantime.
+		if err := u.offers.RespondTx(ctx, tx, &offer, model.OfferCancelled); err != nil {
+			return model.Delivery{}, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return model.Delivery{}, err
+		}
+		return model.Delivery{}, ErrOrderAlreadyAssigned
+	}
+
+	courier, err := u.lockAvailableCourierTx(ctx, tx, courierID)
+	if err != nil {
+		return model.Delivery{}, err
+	}
+
+	o := offer.Order
+	now := time.Now().UTC()
+	delivery := &model.Delivery{
+		CourierID:   courier.ID,
+		OrderID:     o.ID,
+		AssignedAt:  now,
+		Deadline:    u.factory.DeadlineFor(now, courier.TransportType, o),
+		Priority:    o.Priority,
+		WindowStart: o.WindowStart,
+		WindowEnd:   o.WindowEnd,
+		ETA:         u.dropoffETA(now, courier.TransportType, o),
+		PINHash:     o.PINHash,
+		MerchantID:  o.MerchantID,
+	}
+	if err := u.deliveryRepo.CreateTx(ctx, tx, delivery); err != nil {
+		return model.Delivery{}, err
+	}
+	if err := u.offers.RespondTx(ctx, tx, &offer, model.OfferAccepted); err != nil {
+		return model.Delivery{}, err
+	}
+
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: "offer accepted", OrderID: o.ID})
+	if err := u.courierRepo.UpdateStatusTx(ctx, tx, courier.ID, "busy"); err != nil {
+		return model.Delivery{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Delivery{}, err
+	}
+	middleware.CourierOffersTotal.WithLabelValues(model.OfferAccepted).Inc()
+	return *delivery, nil
+}
+
+// DeclineOffer releases the courier from the offer and offers the order to
+// the next candidate.
+func (u *DeliveryUsecase) DeclineOffer(ctx context.Context, courierID int, offerID int64) error {
+	if courierID <= 0 || offerID <= 0 || u.offers == nil {
+		return ErrBadInput
+	}
+
+	tx, err := u.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	offer, err := u.lockOwnOfferTx(ctx, tx, courierID, offerID)
+	if err != nil {
+		return err
+	}
+	if err := u.offers.RespondTx(ctx, tx, &offer, model.OfferDeclined); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	middleware.CourierOffersTotal.WithLabelValues(model.OfferDeclined).Inc()
+
+	if err := u.offerOrQueue(ctx, offer.Order); err != nil {
+		log.Printf("Failed to pass on order %s after decline: %v", offer.OrderID, err)
+	}
+	return nil
+}
+
+// ExpireOffers is used by the offer expirer. Offers that ran out are passed
+// on to the next candidate; it returns how many expired.
+func (u *DeliveryUsecase) ExpireOffers(ctx context.Context, now time.Time, limit int) (int, error) {
+	expired, err := u.offers.ExpireDue(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+	for _, offer := range expired {
+		middleware.CourierOffersTotal.WithLabelValues(model.OfferExpired).Inc()
+		log.Printf("Offer of order %s to courier %d expired", offer.OrderID, offer.CourierID)
+		if err := u.offerOrQueue(ctx, offer.Order); err != nil {
+			log.Printf("Failed to pass on order %s after expiry: %v", offer.OrderID, err)
+		}
+	}
+	return len(expired), nil
+}
+
+// ListOffers returns the courier's pending offers.
+func (u *DeliveryUsecase) ListOffers(ctx context.Context, courierID int) ([]model.DeliveryOffer, error) {
+	if courierID <= 0 || u.offers == nil {
+		return nil, ErrBadInput
+	}
+	return u.offers.ListPendingByCourier(ctx, courierID)
+}
+
+func (u *DeliveryUsecase) OfferStats(ctx context.Context, courierID int) (model.OfferStats, error) {
+	if courierID <= 0 || u.offers == nil {
+		return model.OfferStats{}, ErrBadInput
+	}
+	return u.offers.Stats(ctx, courierID)
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// activeDelivery reports whether a delivery with this status is still with
+// its courier: assigned, or picked up and on its way.
+func activeDelivery(status string) bool {
+	return status == "assigned" || status == "picked_up"
+}
+
+// lockOwnDeliveryTx locks the delivery. With a courierID, a delivery of
+// another courier is reported as not found, so couriers can't probe for
+// each other's orders.
+func (u *DeliveryUsecase) lockOwnDeliveryTx(ctx context.Context, tx pgx.Tx, deliveryID, courierID int) (model.Delivery, error) {
+	delivery, err := u.deliveryRepo.GetByIDForUpdateTx(ctx, tx, deliveryID)
+	if err != nil {
+		return model.Delivery{}, err
+	}
+	if courierID > 0 && delivery.CourierID != courierID {
+		return model.Delivery{}, ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+// PickUp records that the courier collected the order of one of their
+// assigned deliveries.
+func (u *DeliveryUsecase) PickUp(ctx context.Context, courierID, deliveryID int) (model.Delivery, error) {
+	if courierID <= 0 || deliveryID <= 0 {
+		return model.Delivery{}, ErrBadInput
+	}
+
+	tx, err := u.pool.Begin(ctx)
+	if err != nil {
+		return model.Delivery{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	delivery, err := u.lockOwnDeliveryTx(ctx, tx, deliveryID, courierID)
+	if err != nil {
+		return model.Delivery{}, err
+	}
+	if delivery.Status != "assigned" {
+		return model.Delivery{}, ErrDeliveryNotActive
+	}
+	if err := u.deliveryRepo.PickUpTx(ctx, tx, &delivery); err != nil {
+		return model.Delivery{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Delivery{}, err
+	}
+	return delivery, nil
+}
+
+// ListActive returns the deliveries the courier is working on.
+func (u *DeliveryUsecase) ListActive(ctx context.Context, courierID int) ([]model.Delivery, error) {
+	if courierID <= 0 {
+		return nil, ErrBadInput
+	}
+	return u.deliveryRepo.ListActiveByCourier(ctx, courierID)
+}
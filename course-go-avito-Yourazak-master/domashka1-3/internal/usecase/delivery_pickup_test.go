@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockDeliveryRepository) Create(ctx context.Context, d *model.Delivery) error {
+	args := m.Called(ctx, d)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryRepository) CreateTx(ctx context.Context, tx pgx.Tx, d *model.Delivery) error {
+	args := m.Called(ctx, tx, d)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryRepository) GetByOrderID(ctx context.Context, orderID string) (model.Delivery, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(model.Delivery), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) GetByOrderIDTx(ctx context.Context, tx pgx.Tx, orderID string) (model.Delivery, error) {
+	args := m.Called(ctx, tx, orderID)
+	return args.Get(0).(model.Delivery), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) GetByIDForUpdateTx(ctx context.Context, tx pgx.Tx, id int) (model.Delivery, error) {
+	args := m.Called(ctx, tx, id)
+	return args.Get(0).(model.Delivery), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) GetByID(ctx context.Context, id int) (model.Delivery, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(model.Delivery), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) List(ctx context.Context, q model.DeliveryQuery) ([]model.Delivery, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).([]model.Delivery), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) ReassignTx(ctx context.Context, tx pgx.Tx, d *model.Delivery, fromCourierID int, reason string) error {
+	args := m.Called(ctx, tx, d, fromCourierID, reason)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryRepository) CompleteTx(ctx context.Context, tx pgx.Tx, id int, proof *model.DeliveryProof) error {
+	args := m.Called(ctx, tx, id, proof)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryRepository) CountAttemptsTx(ctx context.Context, tx pgx.Tx, orderID string) (int, error) {
+	args := m.Called(ctx, tx, orderID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) RecordAttemptTx(ctx context.Context, tx pgx.Tx, a *model.DeliveryAttempt) error {
+	args := m.Called(ctx, tx, a)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryRepository) PickUpTx(ctx context.Context, tx pgx.Tx, d *model.Delivery) error {
+	args := m.Called(ctx, tx, d)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryRepository) ListActiveByCourier(ctx context.Context, courierID int) ([]model.Delivery, error) {
+	args := m.Called(ctx, courierID)
+	return args.Get(0).([]model.Delivery), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) UpdateStatus(ctx context.Context, orderID, status string) error {
+	args := m.Called(ctx, orderID, status)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryRepository) UpdateStatusTx(ctx context.Context, tx pgx.Tx, orderID, status string) error {
+	args := m.Called(ctx, tx, orderID, status)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryRepository) DeleteByOrderID(ctx context.Context, orderID string) error {
+	args := m.Called(ctx, orderID)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryRepository) CheckOrderExistsTx(ctx context.Context, tx pgx.Tx, orderID string) (bool, error) {
+	args := m.Called(ctx, tx, orderID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) DeleteByOrderIDTx(ctx context.Context, tx pgx.Tx, orderID string) (int, error) {
+	args := m.Called(ctx, tx, orderID)
+	return args.Int(0), args.Error(1)
+}
+
+func TestActiveDelivery(t *testing.T) {
+	assert.True(t, activeDelivery("assigned"))
+	assert.True(t, activeDelivery("picked_up"))
+	assert.False(t, activeDelivery("completed"))
+	assert.False(t, activeDelivery("expired"))
+}
+
+func TestDeliveryUsecase_LockOwnDelivery(t *testing.T) {
+	repo := new(MockDeliveryRepository)
+	u := NewDeliveryUsecase(nil, nil, repo, nil, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+	repo.On("GetByIDForUpdateTx", mock.Anything, mock.Anything, 5).Return(model.Delivery{ID: 5, CourierID: 7}, nil)
+
+	d, err := u.lockOwnDeliveryTx(context.Background(), nil, 5, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, d.ID)
+
+	_, err = u.lockOwnDeliveryTx(context.Background(), nil, 5, 8)
+	assert.Equal(t, ErrDeliveryNotFound, err)
+
+	_, err = u.lockOwnDeliveryTx(context.Background(), nil, 5, 0)
+	assert.NoError(t, err)
+}
+
+func TestDeliveryUsecase_ListActive(t *testing.T) {
+	repo := new(MockDeliveryRepository)
+	u := NewDeliveryUsecase(nil, nil, repo, nil, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+	repo.On("ListActiveByCourier", mock.Anything, 7).Return([]model.Delivery{{ID: 1, CourierID: 7}}, nil)
+
+	items, err := u.ListActive(context.Background(), 7)
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+
+	_, err = u.ListActive(context.Background(), 0)
+	assert.Equal(t, ErrBadInput, err)
+	repo.AssertNumberOfCalls(t, "ListActiveByCourier", 1)
+}
+
+func TestDeliveryUsecase_PickUp_BadInput(t *testing.T) {
+	u := NewDeliveryUsecase(nil, nil, nil, nil, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+
+	_, err := u.PickUp(context.Background(), 0, 1)
+	assert.Equal(t, ErrBadInput, err)
+
+	_, err = u.PickUp(context.Background(), 1, 0)
+	assert.Equal(t, ErrBadInput, err)
+}
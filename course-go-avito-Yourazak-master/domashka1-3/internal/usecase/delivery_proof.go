@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"avito-courier/internal/model"
+)
+
+var ErrInvalidPIN = errors.New("invalid pin")
+
+// proofImageTypes maps the accepted upload content types to the extension
+// the object is stored with.
+var proofImageTypes = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/webp": ".webp",
+}
+
+// ProofUpload is a signature or photo taken by the courier at handover.
+type ProofUpload struct {
+	Body        io.Reader
+	ContentType string
+}
+
+// CompletionRequest is what the courier app sends when handing an order
+// over. PIN is required when the order was created with one. A non-zero
+// CourierID restricts it to that courier's own deliveries.
+type CompletionRequest struct {
+	CourierID int
+	PIN       string
+	Location  *model.GeoPoint
+	Signature *ProofUpload
+	Photo     *ProofUpload
+}
+
+func validCompletion(req CompletionRequest) bool {
+	if req.Location != nil && !req.Location.Valid() {
+		return false
+	}
+	for _, up := range []*ProofUpload{req.Signature, req.Photo} {
+		if up == nil {
+			continue
+		}
+		if _, ok := proofImageTypes[up.ContentType]; !ok || up.Body == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Complete finishes an active delivery with proof of handover: the PIN is
+// checked, uploads are put into the blob store and the courier is released.
+func (u *DeliveryUsecase) Complete(ctx context.Context, deliveryID int, req CompletionRequest) (model.Delivery, error) {
+	if deliveryID <= 0 || req.CourierID < 0 || !validCompletion(req) {
+		return model.Delivery{}, ErrBadInput
+	}
+	if (req.Signature != nil || req.Photo != nil) && u.blobs == nil {
+		return model.Delivery{}, ErrBadInput
+	}
+
+	tx, err := u.pool.Begin(ctx)
+	if err != nil {
+		return model.Delivery{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	delivery, err := u.lockOwnDeliveryTx(ctx, tx, deliveryID, req.CourierID)
+	if err != nil {
+		return model.Delivery{}, err
+	}
+	if !activeDelivery(delivery.Status) {
+		return model.Delivery{}, ErrDeliveryNotActive
+	}
+	if delivery.PINHash != "" && !pinMatches(delivery.PINHash, model.HashPIN(delivery.OrderID, req.PIN)) {
+		return model.Delivery{}, ErrInvalidPIN
+	}
+
+	proof := &model.DeliveryProof{
+		CompletedAt: time.Now().UTC(),
+		PINVerified: delivery.PINHash != "",
+		Location:    req.Location,
+	}
+	if proof.SignatureKey, err = u.putProof(ctx, delivery.ID, "signature", req.Signature); err != nil {
+		return model.Delivery{}, err
+	}
+	if proof.PhotoKey, err = u.putProof(ctx, delivery.ID, "photo", req.Photo); err != nil {
+		return model.Delivery{}, err
+	}
+
+	if err := u.deliveryRepo.CompleteTx(ctx, tx, delivery.ID, proof); err != nil {
+		return model.Delivery{}, err
+	}
+
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: "order completed", OrderID: delivery.OrderID})
+	if err := u.releaseCourierTx(ctx, tx, delivery); err != nil {
+		return model.Delivery{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Delivery{}, err
+	}
+
+	delivery.Status = "completed"
+	delivery.Proof = proof
+	return delivery, nil
+}
+
+func pinMatches(stored, given string) bool {
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(given)) == 1
+}
+
+// putProof stores an upload under deliveries/<id>/<name><ext> and returns
+// its key, or an empty key when there is nothing to store.
+func (u *DeliveryUsecase) putProof(ctx context.Context, deliveryID int, name string, up *ProofUpload) (string, error) {
+	if up == nil {
+		return "", nil
+	}
+	key := fmt.Sprintf("deliveries/%d/%s%s", deliveryID, name, proofImageTypes[up.ContentType])
+	if err := u.blobs.Put(ctx, key, up.Body); err != nil {
+		return "", err
+	}
+	return key, nil
+}
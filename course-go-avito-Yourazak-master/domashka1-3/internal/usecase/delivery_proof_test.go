@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"avito-courier/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryUsecase_Complete_BadInput(t *testing.T) {
+	u := NewDeliveryUsecase(nil, nil, nil, nil, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+	png := &ProofUpload{Body: strings.NewReader("img"), ContentType: "image/png"}
+
+	cases := map[string]struct {
+		id  int
+		req CompletionRequest
+	}{
+		"zero id":          {0, CompletionRequest{}},
+		"invalid location": {1, CompletionRequest{Location: &model.GeoPoint{Lat: 91}}},
+		"unsupported type": {1, CompletionRequest{Photo: &ProofUpload{Body: strings.NewReader("x"), ContentType: "text/plain"}}},
+		"missing body":     {1, CompletionRequest{Signature: &ProofUpload{ContentType: "image/png"}}},
+		"no blob store":    {1, CompletionRequest{Photo: png}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := u.Complete(context.Background(), tc.id, tc.req)
+			assert.Equal(t, ErrBadInput, err)
+		})
+	}
+}
+
+func TestPINMatches(t *testing.T) {
+	stored := model.HashPIN("o1", "1234")
+
+	assert.True(t, pinMatches(stored, model.HashPIN("o1", "1234")))
+	assert.False(t, pinMatches(stored, model.HashPIN("o1", "4321")))
+	assert.False(t, pinMatches(stored, model.HashPIN("o2", "1234")))
+	assert.False(t, pinMatches(stored, model.HashPIN("o1", "")))
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
+)
+
+// DeliveryReleaserLockKey is the pg advisory lock key that elects the single
+// replica allowed to expire overdue deliveries.
+const DeliveryReleaserLockKey int64 = 7_341_007
+
+type DeliveryReleaserConfig struct {
+	Interval time.Duration
+}
+
+type overdueReleaser interface {
+	ReleaseOverdue(ctx context.Context) ([]int, error)
+}
+
+// DeliveryReleaser expires deliveries that ran past their deadline and
+// frees their couriers.
+type DeliveryReleaser struct {
+	releaser overdueReleaser
+	locker   repository.Locker
+	interval time.Duration
+}
+
+func NewDeliveryReleaser(releaser overdueReleaser, locker repository.Locker, cfg DeliveryReleaserConfig) *DeliveryReleaser {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Second
+	}
+	return &DeliveryReleaser{
+		releaser: releaser,
+		locker:   locker,
+		interval: cfg.Interval,
+	}
+}
+
+func (r *DeliveryReleaser) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	defer r.locker.Release(context.Background())
+
+	log.Printf("Delivery releaser started (ticker: %v)", r.interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Delivery releaser stopped")
+			return
+		case <-ticker.C:
+			acquired, err := r.locker.TryAcquire(ctx)
+			if err != nil {
+				log.Printf("Delivery releaser: failed to acquire lock: %v", err)
+				continue
+			}
+			if acquired {
+				r.runOnce(ctx)
+			}
+		}
+	}
+}
+
+func (r *DeliveryReleaser) runOnce(ctx context.Context) {
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Actor: model.ActorAutoReleaseJob, Reason: "delivery deadline passed"})
+	released, err := r.releaser.ReleaseOverdue(ctx)
+	if err != nil {
+		log.Printf("Delivery releaser: failed to release overdue deliveries: %v", err)
+		return
+	}
+	if len(released) > 0 {
+		log.Printf("Delivery releaser: couriers %v are available again", released)
+	}
+}
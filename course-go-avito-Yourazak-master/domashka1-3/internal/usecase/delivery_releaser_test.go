@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"avito-courier/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func fromAutoRelease() any {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		return model.StatusChangeFrom(ctx).Actor == model.ActorAutoReleaseJob
+	})
+}
+
+func TestDeliveryReleaser_RunOnce(t *testing.T) {
+	mockRepo := new(MockCourierRepository)
+	mockRepo.On("ReleaseOverdue", fromAutoRelease()).Return([]int{7}, nil).Once()
+	mockRepo.On("ReleaseOverdue", fromAutoRelease()).Return([]int(nil), errors.New("db down")).Once()
+
+	u := NewDeliveryUsecase(nil, mockRepo, nil, nil, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+	r := NewDeliveryReleaser(u, new(MockLocker), DeliveryReleaserConfig{})
+	r.runOnce(context.Background())
+	r.runOnce(context.Background())
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeliveryReleaser_OverdueDeliveryEmitsExpired(t *testing.T) {
+	stream := NewDeliveryStream()
+	events, cancel := stream.Subscribe(model.DeliveryEventFilter{OrderID: "o1"})
+	defer cancel()
+
+	// The deliveries trigger notifies about the expiry; stand in for it and
+	// the LISTEN loop that feeds the stream.
+	mockRepo := new(MockCourierRepository)
+	mockRepo.On("ReleaseOverdue", fromAutoRelease()).Run(func(mock.Arguments) {
+		stream.Publish(`{"type":"expired","delivery_id":1,"order_id":"o1","courier_id":7,"status":"expired","previous_status":"assigned","kind":"delivery","at":"2025-01-01T12:00:00Z"}`)
+	}).Return([]int{7}, nil)
+
+	u := NewDeliveryUsecase(nil, mockRepo, nil, nil, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+	NewDeliveryReleaser(u, new(MockLocker), DeliveryReleaserConfig{}).runOnce(context.Background())
+
+	if assert.Len(t, events, 1) {
+		e := <-events
+		assert.Equal(t, model.DeliveryEventExpired, e.Type)
+		assert.Equal(t, 7, e.CourierID)
+		if assert.NotNil(t, e.PreviousStatus) {
+			assert.Equal(t, "assigned", *e.PreviousStatus)
+		}
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeliveryReleaser_RecordsStatusHistoryActor(t *testing.T) {
+	var got model.StatusChange
+	mockRepo := new(MockCourierRepository)
+	mockRepo.On("ReleaseOverdue", mock.Anything).Run(func(args mock.Arguments) {
+		got = model.StatusChangeFrom(args.Get(0).(context.Context))
+	}).Return([]int{7}, nil)
+
+	u := NewDeliveryUsecase(nil, mockRepo, nil, nil, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+	NewDeliveryReleaser(u, new(MockLocker), DeliveryReleaserConfig{}).runOnce(context.Background())
+
+	assert.Equal(t, model.StatusChange{Actor: model.ActorAutoReleaseJob, Reason: "delivery deadline passed"}, got)
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
+)
+
+// DeliverySchedulerLockKey is the pg advisory lock key that elects the single
+// replica allowed to trigger scheduled deliveries.
+const DeliverySchedulerLockKey int64 = 7_341_003
+
+type DeliverySchedulerConfig struct {
+	Interval  time.Duration
+	LeadTime  time.Duration
+	BatchSize int
+}
+
+type scheduledAssigner interface {
+	AssignScheduled(ctx context.Context, s model.ScheduledDelivery) error
+}
+
+// DeliveryScheduler assigns scheduled deliveries once their window starts
+// within the lead time.
+type DeliveryScheduler struct {
+	repo      repository.ScheduledDeliveryRepository
+	assigner  scheduledAssigner
+	locker    repository.Locker
+	interval  time.Duration
+	leadTime  time.Duration
+	batchSize int
+}
+
+func NewDeliveryScheduler(r repository.ScheduledDeliveryRepository, assigner scheduledAssigner, locker repository.Locker, cfg DeliverySchedulerConfig) *DeliveryScheduler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.LeadTime <= 0 {
+		cfg.LeadTime = 30 * time.Minute
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	return &DeliveryScheduler{
+		repo:      r,
+		assigner:  assigner,
+		locker:    locker,
+		interval:  cfg.Interval,
+		leadTime:  cfg.LeadTime,
+		batchSize: cfg.BatchSize,
+	}
+}
+
+func (s *DeliveryScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	defer s.locker.Release(context.Background())
+
+	log.Printf("Delivery scheduler started (ticker: %v, lead time: %v)", s.interval, s.leadTime)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Delivery scheduler stopped")
+			return
+		case t := <-ticker.C:
+			acquired, err := s.locker.TryAcquire(ctx)
+			if err != nil {
+				log.Printf("Delivery scheduler: failed to acquire lock: %v", err)
+				continue
+			}
+			if acquired {
+				s.runOnce(ctx, t.UTC())
+			}
+		}
+	}
+}
+
+func (s *DeliveryScheduler) runOnce(ctx context.Context, now time.Time) {
+	due, err := s.repo.ListDue(ctx, now.Add(s.leadTime), s.batchSize)
+	if err != nil {
+		log.Printf("Delivery scheduler: failed to list due deliveries: %v", err)
+		return
+	}
+
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Actor: model.ActorDeliveryScheduler})
+	for _, d := range due {
+		if err := s.assigner.AssignScheduled(ctx, d); err != nil {
+			log.Printf("Delivery scheduler: failed to assign order %s: %v", d.OrderID, err)
+		}
+	}
+}
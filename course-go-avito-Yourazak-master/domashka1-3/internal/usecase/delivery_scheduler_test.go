@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockScheduledDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockScheduledDeliveryRepository) Create(ctx context.Context, s *model.ScheduledDelivery) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
+
+func (m *MockScheduledDeliveryRepository) ListDue(ctx context.Context, before time.Time, limit int) ([]model.ScheduledDelivery, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).([]model.ScheduledDelivery), args.Error(1)
+}
+
+func (m *MockScheduledDeliveryRepository) ListByCourier(ctx context.Context, courierID int, after time.Time) ([]model.ScheduledDelivery, error) {
+	args := m.Called(ctx, courierID, after)
+	return args.Get(0).([]model.ScheduledDelivery), args.Error(1)
+}
+
+func (m *MockScheduledDeliveryRepository) SetState(ctx context.Context, id int64, state string, courierID, deliveryID *int) error {
+	args := m.Called(ctx, id, state, courierID, deliveryID)
+	return args.Error(0)
+}
+
+func (m *MockScheduledDeliveryRepository) Cancel(ctx context.Context, orderID string) (bool, error) {
+	args := m.Called(ctx, orderID)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockScheduledAssigner struct {
+	mock.Mock
+}
+
+func (m *MockScheduledAssigner) AssignScheduled(ctx context.Context, s model.ScheduledDelivery) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
+
+func TestDeliveryScheduler_RunOnce(t *testing.T) {
+	mockRepo := new(MockScheduledDeliveryRepository)
+	mockAssigner := new(MockScheduledAssigner)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	due := []model.ScheduledDelivery{
+		{ID: 1, OrderID: "o1", WindowStart: now.Add(10 * time.Minute)},
+		{ID: 2, OrderID: "o2", WindowStart: now.Add(20 * time.Minute)},
+	}
+
+	fromScheduler := mock.MatchedBy(func(ctx context.Context) bool {
+		return model.StatusChangeFrom(ctx).Actor == model.ActorDeliveryScheduler
+	})
+	mockRepo.On("ListDue", mock.Anything, now.Add(15*time.Minute), 50).Return(due, nil)
+	mockAssigner.On("AssignScheduled", fromScheduler, due[0]).Return(errors.New("db down"))
+	mockAssigner.On("AssignScheduled", fromScheduler, due[1]).Return(nil)
+
+	s := NewDeliveryScheduler(mockRepo, mockAssigner, new(MockLocker), DeliverySchedulerConfig{LeadTime: 15 * time.Minute})
+	s.runOnce(context.Background(), now)
+
+	mockRepo.AssertExpectations(t)
+	mockAssigner.AssertExpectations(t)
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+
+	"avito-courier/internal/middleware"
+	"avito-courier/internal/model"
+)
+
+// deliveryStreamBuffer is how many events a subscriber may lag behind before
+// further events to it are dropped.
+const deliveryStreamBuffer = 64
+
+// DeliveryStream fans delivery events out to the stream subscribers of this
+// replica. Events come in through Publish, which is fed by LISTEN on
+// model.DeliveryEventsChannel, so every replica sees every change.
+type DeliveryStream struct {
+	mu     sync.Mutex
+	subs   map[*deliverySubscriber]struct{}
+	closed bool
+}
+
+type deliverySubscriber struct {
+	filter model.DeliveryEventFilter
+	events chan model.DeliveryEvent
+}
+
+func NewDeliveryStream() *DeliveryStream {
+	return &DeliveryStream{subs: make(map[*deliverySubscriber]struct{})}
+}
+
+// Subscribe returns the events matching f and a function that ends the
+// subscription. The channel is closed only by Close, so that open streams
+// end when the server shuts down.
+func (s *DeliveryStream) Subscribe(f model.DeliveryEventFilter) (<-chan model.DeliveryEvent, func()) {
+	sub := &deliverySubscriber{filter: f, events: make(chan model.DeliveryEvent, deliveryStreamBuffer)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		close(sub.events)
+		return sub.events, func() {}
+	}
+	s.subs[sub] = struct{}{}
+	middleware.DeliveryStreamSubscribers.Inc()
+
+	return sub.events, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subs[sub]; ok {
+			delete(s.subs, sub)
+			middleware.DeliveryStreamSubscribers.Dec()
+		}
+	}
+}
+
+// Close ends every open subscription and refuses new ones.
+func (s *DeliveryStream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for sub := range s.subs {
+		close(sub.events)
+		delete(s.subs, sub)
+		middleware.DeliveryStreamSubscribers.Dec()
+	}
+}
+
+// Publish decodes a NOTIFY payload and hands the event to every matching
+// subscriber. It never blocks: a subscriber whose buffer is full misses the
+// event.
+func (s *DeliveryStream) Publish(payload string) {
+	var e model.DeliveryEvent
+	if err := json.Unmarshal([]byte(payload), &e); err != nil {
+		log.Printf("DeliveryStream: bad event payload %q: %v", payload, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subs {
+		if !sub.filter.Matches(e) {
+			continue
+		}
+		select {
+		case sub.events <- e:
+			middleware.DeliveryStreamEventsTotal.WithLabelValues("sent").Inc()
+		default:
+			middleware.DeliveryStreamEventsTotal.WithLabelValues("dropped").Inc()
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"testing"
+
+	"avito-courier/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryStream_PublishFilters(t *testing.T) {
+	s := NewDeliveryStream()
+	all, cancelAll := s.Subscribe(model.DeliveryEventFilter{})
+	defer cancelAll()
+	courier, cancelCourier := s.Subscribe(model.DeliveryEventFilter{CourierID: 7})
+	defer cancelCourier()
+
+	s.Publish(`{"type":"assigned","delivery_id":1,"order_id":"o1","courier_id":7,"status":"assigned","kind":"delivery","at":"2025-01-01T12:00:00Z"}`)
+	s.Publish(`{"type":"expired","delivery_id":2,"order_id":"o2","courier_id":8,"status":"expired","previous_status":"assigned","kind":"delivery","at":"2025-01-01T12:00:00Z"}`)
+	s.Publish(`not json`)
+
+	assert.Len(t, all, 2)
+	assert.Len(t, courier, 1)
+	e := <-courier
+	assert.Equal(t, model.DeliveryEventAssigned, e.Type)
+	assert.Equal(t, "o1", e.OrderID)
+	assert.Nil(t, e.PreviousStatus)
+}
+
+func TestDeliveryStream_DropsWhenSubscriberLags(t *testing.T) {
+	s := NewDeliveryStream()
+	events, cancel := s.Subscribe(model.DeliveryEventFilter{})
+	defer cancel()
+
+	for i := 0; i < deliveryStreamBuffer+5; i++ {
+		s.Publish(`{"type":"status_changed","delivery_id":1,"order_id":"o1","courier_id":7,"status":"picked_up"}`)
+	}
+
+	assert.Len(t, events, deliveryStreamBuffer)
+}
+
+func TestDeliveryStream_CancelAndClose(t *testing.T) {
+	s := NewDeliveryStream()
+	cancelled, cancel := s.Subscribe(model.DeliveryEventFilter{})
+	open, _ := s.Subscribe(model.DeliveryEventFilter{})
+
+	cancel()
+	cancel()
+	s.Publish(`{"type":"assigned","delivery_id":1,"order_id":"o1","courier_id":7}`)
+	assert.Len(t, cancelled, 0)
+	assert.Len(t, open, 1)
+
+	s.Close()
+	<-open
+	_, ok := <-open
+	assert.False(t, ok)
+
+	late, _ := s.Subscribe(model.DeliveryEventFilter{})
+	_, ok = <-late
+	assert.False(t, ok)
+}
@@ -3,49 +3,589 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"time"
 
+	"avito-courier/internal/gateway/blob"
+	"avito-courier/internal/gateway/order"
 	"avito-courier/internal/model"
 	"avito-courier/internal/repository"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var (
 	ErrNoAvailableCourier   = errors.New("no available courier")
+	ErrNoCapableCourier     = errors.New("no capable courier")
 	ErrOrderAlreadyAssigned = errors.New("order already assigned")
+	ErrCourierUnavailable   = errors.New("courier unavailable")
+	ErrDeliveryNotActive    = errors.New("delivery not active")
+	ErrAssignmentQueued     = errors.New("assignment queued")
+	ErrDeliveryScheduled    = errors.New("delivery scheduled")
+	ErrDeliveryInRoute      = errors.New("delivery is part of a route")
+
+	ErrDeliveryNotFound = repository.ErrDeliveryNotFound
 )
 
 type IDeliveryUsecase interface {
-	Assign(ctx context.Context, orderID string) (model.Delivery, model.Courier, error)
+	Assign(ctx context.Context, order model.ExternalOrder) (model.Delivery, model.Courier, error)
+	AssignTo(ctx context.Context, order model.ExternalOrder, courierID int) (model.Delivery, model.Courier, error)
+	Reassign(ctx context.Context, deliveryID, courierID int, reason string) (model.Delivery, model.Courier, error)
 	Unassign(ctx context.Context, orderID string) error
-	AutoRelease(ctx context.Context, interval time.Duration)
-	AssignForEvent(ctx context.Context, orderID string) error
+	AssignForEvent(ctx context.Context, order model.ExternalOrder) error
 	UnassignForEvent(ctx context.Context, orderID string) error
 	CompleteForEvent(ctx context.Context, orderID string) error
+	Complete(ctx context.Context, deliveryID int, req CompletionRequest) (model.Delivery, error)
+	Fail(ctx context.Context, deliveryID int, req FailureRequest) (model.DeliveryAttempt, *model.Delivery, error)
+	PickUp(ctx context.Context, courierID, deliveryID int) (model.Delivery, error)
+	ListActive(ctx context.Context, courierID int) ([]model.Delivery, error)
+	AcceptOffer(ctx context.Context, courierID int, offerID int64) (model.Delivery, error)
+	DeclineOffer(ctx context.Context, courierID int, offerID int64) error
+	ListOffers(ctx context.Context, courierID int) ([]model.DeliveryOffer, error)
+	OfferStats(ctx context.Context, courierID int) (model.OfferStats, error)
 	GetByOrderID(ctx context.Context, orderID string) (model.Delivery, error)
+	GetByID(ctx context.Context, id int) (model.Delivery, error)
+	List(ctx context.Context, q model.DeliveryQuery) ([]model.Delivery, error)
 	Create(ctx context.Context, d *model.Delivery) error
 	DeleteByOrderID(ctx context.Context, orderID string) error
+	ListScheduled(ctx context.Context, courierID int) ([]model.ScheduledDelivery, error)
+}
+
+type AssignmentConfig struct {
+	SearchRadiusKm float64
+	LocationMaxAge time.Duration
+	CandidateLimit int
+	// ZoneSpillover lets an order whose zone has no free courier be assigned
+	// to a courier from one of the zone's neighbours.
+	ZoneSpillover bool
+	// TransportLimits holds the limits per transport type; couriers can have
+	// their own overrides on top.
+	TransportLimits map[string]model.TransportLimits
+	// ScheduleLeadTime is how long before its window a booked order is
+	// assigned. Orders whose window starts later are stored as scheduled
+	// deliveries until then.
+	ScheduleLeadTime time.Duration
+	// Batching makes orders from events wait for the route batcher instead
+	// of being assigned one by one.
+	Batching bool
+	// MaxAttempts is how many handovers are tried before an order goes back
+	// to its pickup point.
+	MaxAttempts int
+	// OfferMode makes automatic assignment offer the order to the chosen
+	// courier for OfferTTL instead of assigning it. A courier who declined
+	// or let an offer expire is skipped for that order for OfferCooldown.
+	OfferMode     bool
+	OfferTTL      time.Duration
+	OfferCooldown time.Duration
 }
 
 type DeliveryUsecase struct {
 	pool         *pgxpool.Pool
 	courierRepo  repository.CourierRepository
 	deliveryRepo repository.DeliveryRepository
+	pending      repository.PendingAssignmentRepository
+	scheduled    repository.ScheduledDeliveryRepository
+	routes       repository.RouteRepository
+	offers       repository.OfferRepository
 	factory      *DeliveryTimeFactory
+	planner      *RoutePlanner
+	blobs        blob.Store
+	orderGateway order.OrderGateway
+	zones        ZoneUsecase
+	cfg          AssignmentConfig
 }
 
-func NewDeliveryUsecase(pool *pgxpool.Pool, cr repository.CourierRepository, dr repository.DeliveryRepository, f *DeliveryTimeFactory) *DeliveryUsecase {
+func NewDeliveryUsecase(pool *pgxpool.Pool, cr repository.CourierRepository, dr repository.DeliveryRepository, pending repository.PendingAssignmentRepository, scheduled repository.ScheduledDeliveryRepository, routes repository.RouteRepository, offers repository.OfferRepository, f *DeliveryTimeFactory, planner *RoutePlanner, blobs blob.Store, gateway order.OrderGateway, zones ZoneUsecase, cfg AssignmentConfig) *DeliveryUsecase {
+	if cfg.SearchRadiusKm <= 0 {
+		cfg.SearchRadiusKm = 10
+	}
+	if cfg.LocationMaxAge <= 0 {
+		cfg.LocationMaxAge = 10 * time.Minute
+	}
+	if cfg.CandidateLimit <= 0 {
+		cfg.CandidateLimit = 20
+	}
+	if cfg.TransportLimits == nil {
+		cfg.TransportLimits = DefaultTransportLimits
+	}
+	if cfg.ScheduleLeadTime <= 0 {
+		cfg.ScheduleLeadTime = 30 * time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.OfferTTL <= 0 {
+		cfg.OfferTTL = time.Minute
+	}
+	if cfg.OfferCooldown <= 0 {
+		cfg.OfferCooldown = 10 * time.Minute
+	}
+	if planner == nil {
+		planner = NewRoutePlanner(nil, 0)
+	}
 	return &DeliveryUsecase{
 		pool:         pool,
 		courierRepo:  cr,
 		deliveryRepo: dr,
+		pending:      pending,
+		scheduled:    scheduled,
+		routes:       routes,
+		offers:       offers,
 		factory:      f,
+		planner:      planner,
+		blobs:        blobs,
+		orderGateway: gateway,
+		zones:        zones,
+		cfg:          cfg,
+	}
+}
+
+// enrichOrder fills in order attributes missing from the event or request
+// (pickup and dropoff points, region, weight, volume and priority class)
+// from the order service.
+func (u *DeliveryUsecase) enrichOrder(ctx context.Context, o model.ExternalOrder) model.ExternalOrder {
+	o = u.fetchMissing(ctx, o)
+	o.Priority = model.NormalizePriority(o.Priority)
+	return o
+}
+
+func (u *DeliveryUsecase) fetchMissing(ctx context.Context, o model.ExternalOrder) model.ExternalOrder {
+	if (o.Pickup != nil && o.Region > 0 && o.Weight > 0 && o.Priority != "") || u.orderGateway == nil {
+		return o
+	}
+
+	full, err := u.orderGateway.GetOrder(ctx, o.ID)
+	if err != nil {
+		log.Printf("Failed to fetch order %s details: %v", o.ID, err)
+		return o
+	}
+	if o.Pickup == nil && full.Pickup != nil && full.Pickup.Valid() {
+		o.Pickup = full.Pickup
+	}
+	if o.Dropoff == nil && full.Dropoff != nil && full.Dropoff.Valid() {
+		o.Dropoff = full.Dropoff
+	}
+	if o.Region <= 0 {
+		o.Region = full.Region
+	}
+	if o.Weight <= 0 {
+		o.Weight = full.Weight
+	}
+	if o.Volume <= 0 {
+		o.Volume = full.Volume
+	}
+	if o.Priority == "" {
+		o.Priority = full.Priority
+	}
+	if o.MerchantID == "" {
+		o.MerchantID = full.MerchantID
+	}
+	return o
+}
+
+// zoneTiers returns the zone filters to try in order: the order's own zone,
+// then its neighbours when spillover is enabled. Orders outside every zone
+// get a single unfiltered tier.
+func (u *DeliveryUsecase) zoneTiers(ctx context.Context, o model.ExternalOrder) ([][]int, error) {
+	if u.zones == nil {
+		return [][]int{nil}, nil
+	}
+
+	zone, err := u.zones.Resolve(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	if zone == nil {
+		return [][]int{nil}, nil
+	}
+
+	tiers := [][]int{{zone.ID}}
+	if u.cfg.ZoneSpillover && len(zone.NeighbourIDs) > 0 {
+		tiers = append(tiers, zone.NeighbourIDs)
+	}
+	return tiers, nil
+}
+
+// selectCourierTx picks the courier for an order. Candidates are limited to
+// the order's delivery zone (spilling over to neighbouring zones if it has
+// none) and to couriers whose transport limits fit the order. With a known
+// pickup point the nearest courier by haversine distance inside the search
+// radius wins, then a courier whose position is unknown; otherwise the
+// longest-registered one.
+func (u *DeliveryUsecase) selectCourierTx(ctx context.Context, tx pgx.Tx, o model.ExternalOrder, exclude []int) (model.Courier, error) {
+	tiers, err := u.zoneTiers(ctx, o)
+	if err != nil {
+		return model.Courier{}, err
+	}
+
+	filter := model.CandidateFilter{Limit: 1}
+	if o.Pickup != nil {
+		filter = model.CandidateFilter{
+			Near:           o.Pickup,
+			RadiusKm:       u.cfg.SearchRadiusKm,
+			MaxLocationAge: u.cfg.LocationMaxAge,
+			Limit:          u.cfg.CandidateLimit,
+		}
+	}
+	filter.ExcludeIDs = exclude
+	filter.MinWeightKg = o.Weight
+	filter.MinVolumeL = o.Volume
+	filter.TransportLimits = u.cfg.TransportLimits
+
+	best, found, err := u.searchTiers(ctx, tx, tiers, filter, o, true)
+	if err != nil {
+		return model.Courier{}, err
+	}
+	if found {
+		return best.Courier, nil
+	}
+
+	// Tell "nobody is free" apart from "nobody free can carry this order".
+	filter.MinWeightKg, filter.MinVolumeL = 0, 0
+	_, found, err = u.searchTiers(ctx, tx, tiers, filter, o, false)
+	if err != nil {
+		return model.Courier{}, err
+	}
+	if found {
+		return model.Courier{}, ErrNoCapableCourier
+	}
+	return model.Courier{}, ErrNoAvailableCourier
+}
+
+func (u *DeliveryUsecase) searchTiers(ctx context.Context, tx pgx.Tx, tiers [][]int, filter model.CandidateFilter, o model.ExternalOrder, capableOnly bool) (model.CourierCandidate, bool, error) {
+	for i, zoneIDs := range tiers {
+		filter.ZoneIDs = zoneIDs
+		candidates, err := u.courierRepo.FindCandidatesTx(ctx, tx, filter)
+		if err != nil {
+			return model.CourierCandidate{}, false, err
+		}
+		if capableOnly {
+			candidates = capableCandidates(candidates, o, u.cfg.TransportLimits)
+		}
+
+		if best, ok := nearestCandidate(candidates, o.Pickup, u.cfg.SearchRadiusKm); ok {
+			if i > 0 && capableOnly {
+				log.Printf("Order %s spilled over to neighbouring zones %v", o.ID, zoneIDs)
+			}
+			return best, true, nil
+		}
+	}
+	return model.CourierCandidate{}, false, nil
+}
+
+// capableCandidates keeps the couriers whose effective limits fit the order.
+// The trip distance is courier to pickup plus pickup to dropoff, as far as
+// those points are known.
+func capableCandidates(candidates []model.CourierCandidate, o model.ExternalOrder, limits map[string]model.TransportLimits) []model.CourierCandidate {
+	var legKm float64
+	if o.Pickup != nil && o.Dropoff != nil {
+		legKm = o.Pickup.DistanceKm(*o.Dropoff)
+	}
+
+	out := candidates[:0:0]
+	for _, c := range candidates {
+		tripKm := legKm
+		if o.Pickup != nil && c.Location != nil {
+			tripKm += o.Pickup.DistanceKm(c.Location.Point())
+		}
+
+		effective := limits[c.Courier.TransportType].Override(c.Overrides)
+		if effective.Allows(o.Weight, o.Volume, tripKm) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// nearestCandidate returns the closest located candidate inside radiusKm of
+// the pickup point. When there is none, the first candidate without a
+// location is used, so couriers who have not reported a position can still
+// get orders.
+func nearestCandidate(candidates []model.CourierCandidate, pickup *model.GeoPoint, radiusKm float64) (model.CourierCandidate, bool) {
+	if pickup == nil {
+		if len(candidates) == 0 {
+			return model.CourierCandidate{}, false
+		}
+		return candidates[0], true
+	}
+
+	var best, unlocated model.CourierCandidate
+	found, hasUnlocated := false, false
+	for _, c := range candidates {
+		if c.Location == nil {
+			if !hasUnlocated {
+				unlocated, hasUnlocated = c, true
+			}
+			continue
+		}
+		c.DistanceKm = pickup.DistanceKm(c.Location.Point())
+		if c.DistanceKm > radiusKm {
+			continue
+		}
+		if !found || c.DistanceKm < best.DistanceKm {
+			best = c
+			found = true
+		}
 	}
+	if !found && hasUnlocated {
+		return unlocated, true
+	}
+	return best, found
+}
+
+// Assign picks a courier for the order. When nobody can take it right now
+// the order is put in the assignment queue and ErrAssignmentQueued is
+// returned. An order booked for a window further away than the lead time is
+// stored until then and ErrDeliveryScheduled is returned. In offer mode the
+// order is offered to the courier and ErrOfferPending is returned.
+func (u *DeliveryUsecase) Assign(ctx context.Context, o model.ExternalOrder) (model.Delivery, model.Courier, error) {
+	o = u.enrichOrder(ctx, o)
+
+	if !o.ValidWindow() {
+		return model.Delivery{}, model.Courier{}, ErrBadInput
+	}
+	if u.schedulable(o, time.Now()) {
+		if err := u.schedule(ctx, o, 0); err != nil {
+			return model.Delivery{}, model.Courier{}, err
+		}
+		return model.Delivery{}, model.Courier{}, ErrDeliveryScheduled
+	}
+
+	yield, err := u.yieldToQueue(ctx, o)
+	if err != nil {
+		return model.Delivery{}, model.Courier{}, err
+	}
+	if yield {
+		if err := u.enqueue(ctx, o); err != nil {
+			return model.Delivery{}, model.Courier{}, err
+		}
+		return model.Delivery{}, model.Courier{}, ErrAssignmentQueued
+	}
+
+	delivery, courier, err := u.assign(ctx, o, 0)
+	if u.queueable(err) {
+		if err := u.enqueue(ctx, o); err != nil {
+			return model.Delivery{}, model.Courier{}, err
+		}
+		return model.Delivery{}, model.Courier{}, ErrAssignmentQueued
+	}
+	return delivery, courier, err
+}
+
+// AssignPending is used by the assignment queue worker. Unlike Assign it
+// never queues the order again.
+func (u *DeliveryUsecase) AssignPending(ctx context.Context, o model.ExternalOrder) error {
+	_, _, err := u.assign(ctx, u.enrichOrder(ctx, o), 0)
+	return err
+}
+
+// yieldScanLimit bounds how many queued orders yieldToQueue looks at.
+const yieldScanLimit = 20
+
+// yieldToQueue reports whether an order of a higher priority class than o is
+// waiting for a courier o could get. Such an order must not be overtaken, so
+// o joins the queue behind it instead of taking the next free courier.
+// Orders backing off after failed attempts or given up are not waited for,
+// nor are orders served from other zones than o.
+func (u *DeliveryUsecase) yieldToQueue(ctx context.Context, o model.ExternalOrder) (bool, error) {
+	if u.pending == nil {
+		return false, nil
+	}
+	rank := model.PriorityRank(o.Priority)
+	stats, err := u.pending.Stats(ctx)
+	if err != nil {
+		return false, err
+	}
+	if stats.Depth == 0 || stats.TopPriority <= rank {
+		return false, nil
+	}
+
+	queued, err := u.pending.ListDue(ctx, time.Now().UTC(), yieldScanLimit)
+	if err != nil {
+		return false, err
+	}
+	tiers, err := u.zoneTiers(ctx, o)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range queued {
+		if p.Priority <= rank {
+			break
+		}
+		theirs, err := u.zoneTiers(ctx, p.Order)
+		if err != nil {
+			return false, err
+		}
+		if tiersOverlap(tiers, theirs) {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
-func (u *DeliveryUsecase) Assign(ctx context.Context, orderID string) (model.Delivery, model.Courier, error) {
+// tiersOverlap reports whether two orders can be served by the same courier
+// judging by their zone tiers. A nil tier stands for any zone.
+func tiersOverlap(a, b [][]int) bool {
+	zones := make(map[int]bool)
+	for _, tier := range a {
+		if tier == nil {
+			return true
+		}
+		for _, id := range tier {
+			zones[id] = true
+		}
+	}
+	for _, tier := range b {
+		if tier == nil {
+			return true
+		}
+		for _, id := range tier {
+			if zones[id] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// queueable reports whether the order should wait in the queue. Only a
+// momentary lack of free couriers qualifies; an order no free courier can
+// carry is rejected instead, as waiting would rarely help it.
+func (u *DeliveryUsecase) queueable(err error) bool {
+	return u.pending != nil && errors.Is(err, ErrNoAvailableCourier)
+}
+
+func (u *DeliveryUsecase) enqueue(ctx context.Context, o model.ExternalOrder) error {
+	p := model.PendingAssignment{OrderID: o.ID, Order: o, Priority: model.PriorityRank(o.Priority)}
+	if err := u.pending.Enqueue(ctx, &p); err != nil {
+		return err
+	}
+	log.Printf("Order %s queued for assignment", o.ID)
+	return nil
+}
+
+// enqueueTx queues the order inside tx, so that it is only queued if the
+// rest of tx commits.
+func (u *DeliveryUsecase) enqueueTx(ctx context.Context, tx pgx.Tx, o model.ExternalOrder) error {
+	p := model.PendingAssignment{OrderID: o.ID, Order: o, Priority: model.PriorityRank(o.Priority)}
+	return u.pending.EnqueueTx(ctx, tx, &p)
+}
+
+func (u *DeliveryUsecase) dequeue(ctx context.Context, orderID string) (bool, error) {
+	if u.pending == nil {
+		return false, nil
+	}
+	return u.pending.Delete(ctx, orderID)
+}
+
+// AssignTo gives the order to the chosen courier, skipping zone, distance
+// and capacity checks. The courier has to be available. An order booked for
+// a later window is scheduled with the courier pre-booked instead.
+func (u *DeliveryUsecase) AssignTo(ctx context.Context, o model.ExternalOrder, courierID int) (model.Delivery, model.Courier, error) {
+	if courierID <= 0 || !o.ValidWindow() {
+		return model.Delivery{}, model.Courier{}, ErrBadInput
+	}
+	o = u.enrichOrder(ctx, o)
+	if u.schedulable(o, time.Now()) {
+		if err := u.schedule(ctx, o, courierID); err != nil {
+			return model.Delivery{}, model.Courier{}, err
+		}
+		return model.Delivery{}, model.Courier{}, ErrDeliveryScheduled
+	}
+	return u.assign(ctx, o, courierID)
+}
+
+// schedulable reports whether the order's window starts too far ahead to
+// assign a courier now.
+func (u *DeliveryUsecase) schedulable(o model.ExternalOrder, now time.Time) bool {
+	return u.scheduled != nil && o.WindowStart != nil && o.WindowStart.Sub(now) > u.cfg.ScheduleLeadTime
+}
+
+func (u *DeliveryUsecase) schedule(ctx context.Context, o model.ExternalOrder, courierID int) error {
+	s := model.ScheduledDelivery{OrderID: o.ID, Order: o, WindowStart: o.WindowStart.UTC(), WindowEnd: o.WindowEnd.UTC()}
+	if courierID > 0 {
+		s.CourierID = &courierID
+	}
+	if err := u.scheduled.Create(ctx, &s); err != nil {
+		if errors.Is(err, ErrConflict) {
+			return ErrOrderAlreadyAssigned
+		}
+		return err
+	}
+	log.Printf("Order %s scheduled for %s", o.ID, s.WindowStart.Format(time.RFC3339))
+	return nil
+}
+
+// AssignScheduled is used by the scheduled delivery scheduler once the
+// order's window is within the lead time. The pre-booked courier gets it if
+// still free, otherwise any courier; with nobody free the order goes to the
+// assignment queue.
+func (u *DeliveryUsecase) AssignScheduled(ctx context.Context, s model.ScheduledDelivery) error {
+	o := u.enrichOrder(ctx, s.Order)
+
+	var (
+		delivery model.Delivery
+		courier  model.Courier
+		err      error
+	)
+	if s.CourierID != nil {
+		delivery, courier, err = u.assign(ctx, o, *s.CourierID)
+		if errors.Is(err, ErrCourierUnavailable) || errors.Is(err, ErrNotFound) {
+			log.Printf("Pre-booked courier %d can't take order %s: %v", *s.CourierID, s.OrderID, err)
+			s.CourierID = nil
+		}
+	}
+	if s.CourierID == nil {
+		yield, yerr := u.yieldToQueue(ctx, o)
+		if yerr != nil {
+			return yerr
+		}
+		if yield {
+			err = ErrNoAvailableCourier
+		} else {
+			delivery, courier, err = u.assign(ctx, o, 0)
+		}
+	}
+
+	switch {
+	case err == nil:
+		return u.scheduled.SetState(ctx, s.ID, model.ScheduledAssigned, &courier.ID, &delivery.ID)
+	case errors.Is(err, ErrOrderAlreadyAssigned), errors.Is(err, ErrOfferPending):
+		return u.scheduled.SetState(ctx, s.ID, model.ScheduledAssigned, nil, nil)
+	case u.queueable(err):
+		if err := u.enqueue(ctx, o); err != nil {
+			return err
+		}
+		return u.scheduled.SetState(ctx, s.ID, model.ScheduledQueued, nil, nil)
+	}
+	return err
+}
+
+// ListScheduled returns the courier's upcoming scheduled deliveries, both
+// those still waiting for their window and those already assigned.
+func (u *DeliveryUsecase) ListScheduled(ctx context.Context, courierID int) ([]model.ScheduledDelivery, error) {
+	if courierID <= 0 {
+		return nil, ErrBadInput
+	}
+	if _, err := u.courierRepo.GetByID(ctx, courierID); err != nil {
+		return nil, err
+	}
+	if u.scheduled == nil {
+		return []model.ScheduledDelivery{}, nil
+	}
+	return u.scheduled.ListByCourier(ctx, courierID, time.Now().UTC())
+}
+
+// assign expects o to be enriched already. In offer mode an order without
+// a chosen courier is offered instead and ErrOfferPending is returned.
+func (u *DeliveryUsecase) assign(ctx context.Context, o model.ExternalOrder, courierID int) (model.Delivery, model.Courier, error) {
+	if courierID == 0 && u.offering() {
+		return model.Delivery{}, model.Courier{}, u.offer(ctx, o)
+	}
+	orderID := o.ID
+
 	tx, err := u.pool.Begin(ctx)
 	if err != nil {
 		return model.Delivery{}, model.Courier{}, err
@@ -57,28 +597,37 @@ func (u *DeliveryUsecase) Assign(ctx context.Context, orderID string) (model.Del
 		return model.Delivery{}, model.Courier{}, ErrOrderAlreadyAssigned
 	}
 
-	courier, err := u.courierRepo.FindAvailableCourier(ctx)
+	var courier model.Courier
+	if courierID > 0 {
+		courier, err = u.lockAvailableCourierTx(ctx, tx, courierID)
+	} else {
+		courier, err = u.selectCourierTx(ctx, tx, o, nil)
+	}
 	if err != nil {
-		if err == repository.ErrNotFound {
-			return model.Delivery{}, model.Courier{}, ErrNoAvailableCourier
-		}
 		return model.Delivery{}, model.Courier{}, err
 	}
 
 	now := time.Now().UTC()
-	deadline := u.factory.Deadline(now, courier.TransportType)
+	deadline := u.factory.DeadlineFor(now, courier.TransportType, o)
 
 	newDelivery := &model.Delivery{
-		CourierID:  courier.ID,
-		OrderID:    orderID,
-		AssignedAt: now,
-		Deadline:   deadline,
+		CourierID:   courier.ID,
+		OrderID:     orderID,
+		AssignedAt:  now,
+		Deadline:    deadline,
+		Priority:    o.Priority,
+		WindowStart: o.WindowStart,
+		WindowEnd:   o.WindowEnd,
+		ETA:         u.dropoffETA(now, courier.TransportType, o),
+		PINHash:     o.PINHash,
+		MerchantID:  o.MerchantID,
 	}
 
 	if err := u.deliveryRepo.CreateTx(ctx, tx, newDelivery); err != nil {
 		return model.Delivery{}, model.Courier{}, err
 	}
 
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: "order assigned", OrderID: orderID})
 	if err := u.courierRepo.UpdateStatusTx(ctx, tx, courier.ID, "busy"); err != nil {
 		return model.Delivery{}, model.Courier{}, err
 	}
@@ -90,6 +639,204 @@ func (u *DeliveryUsecase) Assign(ctx context.Context, orderID string) (model.Del
 	return *newDelivery, courier, nil
 }
 
+// Reassign moves an active delivery to another courier in one transaction:
+// the chosen one, or the best other candidate when courierID is zero. The
+// deadline is recalculated for the new courier, the old one is freed, and
+// the move is recorded with the actor from ctx.
+func (u *DeliveryUsecase) Reassign(ctx context.Context, deliveryID, courierID int, reason string) (model.Delivery, model.Courier, error) {
+	if deliveryID <= 0 || courierID < 0 {
+		return model.Delivery{}, model.Courier{}, ErrBadInput
+	}
+
+	tx, err := u.pool.Begin(ctx)
+	if err != nil {
+		return model.Delivery{}, model.Courier{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	delivery, err := u.deliveryRepo.GetByIDForUpdateTx(ctx, tx, deliveryID)
+	if err != nil {
+		return model.Delivery{}, model.Courier{}, err
+	}
+	if !activeDelivery(delivery.Status) {
+		return model.Delivery{}, model.Courier{}, ErrDeliveryNotActive
+	}
+	if delivery.RouteID != nil {
+		return model.Delivery{}, model.Courier{}, ErrDeliveryInRoute
+	}
+	if courierID == delivery.CourierID {
+		return model.Delivery{}, model.Courier{}, ErrBadInput
+	}
+
+	// The deadline depends on the trip, so the order details are needed
+	// for a chosen courier too.
+	o := u.enrichOrder(ctx, model.ExternalOrder{ID: delivery.OrderID, Priority: delivery.Priority, WindowStart: delivery.WindowStart, WindowEnd: delivery.WindowEnd, MerchantID: delivery.MerchantID})
+	var courier model.Courier
+	if courierID > 0 {
+		courier, err = u.lockAvailableCourierTx(ctx, tx, courierID)
+	} else {
+		courier, err = u.selectCourierTx(ctx, tx, o, []int{delivery.CourierID})
+	}
+	if err != nil {
+		return model.Delivery{}, model.Courier{}, err
+	}
+
+	previous := delivery
+	oldCourierID := delivery.CourierID
+	now := time.Now().UTC()
+	delivery.CourierID = courier.ID
+	delivery.AssignedAt = now
+	delivery.Deadline = u.factory.DeadlineFor(now, courier.TransportType, o)
+
+	if err := u.deliveryRepo.ReassignTx(ctx, tx, &delivery, oldCourierID, reason); err != nil {
+		return model.Delivery{}, model.Courier{}, err
+	}
+
+	change := model.StatusChange{Reason: "order reassigned", OrderID: delivery.OrderID}
+	if reason != "" {
+		change.Reason += ": " + reason
+	}
+	ctx = model.WithStatusChange(ctx, change)
+	if err := u.releaseCourierTx(ctx, tx, previous); err != nil {
+		return model.Delivery{}, model.Courier{}, err
+	}
+	if err := u.courierRepo.UpdateStatusTx(ctx, tx, courier.ID, "busy"); err != nil {
+		return model.Delivery{}, model.Courier{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Delivery{}, model.Courier{}, err
+	}
+	courier.Status = "busy"
+	return delivery, courier, nil
+}
+
+// AssignBatch is used by the route batcher for one group of orders. A
+// single order is assigned on its own; a larger group goes to one courier as
+// a route. Orders that could not be assigned are put in the assignment
+// queue, so nothing taken from the batch is lost.
+func (u *DeliveryUsecase) AssignBatch(ctx context.Context, orders []model.ExternalOrder) (model.Route, error) {
+	var (
+		route model.Route
+		err   error
+	)
+	if len(orders) == 1 {
+		_, _, err = u.assign(ctx, orders[0], 0)
+	} else {
+		route, err = u.assignRoute(ctx, orders)
+	}
+	if err == nil || errors.Is(err, ErrOrderAlreadyAssigned) || errors.Is(err, ErrOfferPending) {
+		return route, nil
+	}
+	if u.pending == nil {
+		return model.Route{}, err
+	}
+	if !u.queueable(err) {
+		log.Printf("Route assignment failed, queueing %d orders: %v", len(orders), err)
+	}
+	for _, o := range orders {
+		if err := u.enqueue(ctx, o); err != nil {
+			return model.Route{}, err
+		}
+	}
+	return model.Route{}, nil
+}
+
+// assignRoute gives all orders not assigned yet to one courier that can
+// carry them together, with a delivery per order and the stops in planned
+// order.
+func (u *DeliveryUsecase) assignRoute(ctx context.Context, orders []model.ExternalOrder) (model.Route, error) {
+	tx, err := u.pool.Begin(ctx)
+	if err != nil {
+		return model.Route{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	open := make([]model.ExternalOrder, 0, len(orders))
+	for _, o := range orders {
+		exists, err := u.deliveryRepo.CheckOrderExistsTx(ctx, tx, o.ID)
+		if err != nil {
+			return model.Route{}, err
+		}
+		if !exists {
+			open = append(open, o)
+		}
+	}
+	if len(open) == 0 {
+		return model.Route{}, ErrOrderAlreadyAssigned
+	}
+
+	now := time.Now().UTC()
+	stops := u.planner.Plan(nil, now, "", routeStops(open))
+	courier, err := u.selectCourierTx(ctx, tx, combineOrders(open, stops), nil)
+	if err != nil {
+		return model.Route{}, err
+	}
+
+	route := model.Route{CourierID: courier.ID, Status: model.RouteActive, Stops: u.planner.Plan(nil, now, courier.TransportType, stops)}
+	if err := u.routes.CreateTx(ctx, tx, &route); err != nil {
+		return model.Route{}, err
+	}
+
+	etas := make(map[string]*time.Time, len(open))
+	for _, s := range route.Stops {
+		if s.Kind == model.StopDropoff {
+			etas[s.OrderID] = s.ETA
+		}
+	}
+	for _, o := range open {
+		delivery := &model.Delivery{
+			CourierID:   courier.ID,
+			OrderID:     o.ID,
+			AssignedAt:  now,
+			Deadline:    u.factory.DeadlineFor(now, courier.TransportType, o),
+			Priority:    o.Priority,
+			WindowStart: o.WindowStart,
+			WindowEnd:   o.WindowEnd,
+			RouteID:     &route.ID,
+			ETA:         etas[o.ID],
+			PINHash:     o.PINHash,
+			MerchantID:  o.MerchantID,
+		}
+		if err := u.deliveryRepo.CreateTx(ctx, tx, delivery); err != nil {
+			return model.Route{}, err
+		}
+	}
+
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: fmt.Sprintf("route %d assigned", route.ID), OrderID: open[0].ID})
+	if err := u.courierRepo.UpdateStatusTx(ctx, tx, courier.ID, "busy"); err != nil {
+		return model.Route{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Route{}, err
+	}
+	return route, nil
+}
+
+// dropoffETA estimates when a single order reaches its dropoff point,
+// counting from the pickup.
+func (u *DeliveryUsecase) dropoffETA(now time.Time, transport string, o model.ExternalOrder) *time.Time {
+	if o.Pickup == nil || o.Dropoff == nil {
+		return nil
+	}
+	stops := u.planner.Plan(nil, now, transport, routeStops([]model.ExternalOrder{o}))
+	return stops[len(stops)-1].ETA
+}
+
+// lockAvailableCourierTx locks the courier a dispatcher picked by hand and
+// checks that it is free to take an order.
+func (u *DeliveryUsecase) lockAvailableCourierTx(ctx context.Context, tx pgx.Tx, courierID int) (model.Courier, error) {
+	courier, err := u.courierRepo.GetByIDForUpdateTx(ctx, tx, courierID)
+	if err != nil {
+		return model.Courier{}, err
+	}
+	if courier.Status != "available" {
+		return model.Courier{}, ErrCourierUnavailable
+	}
+	return courier, nil
+}
+
 func (u *DeliveryUsecase) Unassign(ctx context.Context, orderID string) error {
 	tx, err := u.pool.Begin(ctx)
 	if err != nil {
@@ -97,22 +844,41 @@ func (u *DeliveryUsecase) Unassign(ctx context.Context, orderID string) error {
 	}
 	defer tx.Rollback(ctx)
 
-	courierID, err := u.deliveryRepo.DeleteByOrderIDTx(ctx, tx, orderID)
+	delivery, err := u.deliveryRepo.GetByOrderIDTx(ctx, tx, orderID)
 	if err != nil {
-		if err.Error() == "delivery not found" {
-			return errors.New("delivery not found")
-		}
+		return err
+	}
+	if !activeDelivery(delivery.Status) {
+		return ErrDeliveryNotActive
+	}
+	if _, err := u.deliveryRepo.DeleteByOrderIDTx(ctx, tx, orderID); err != nil {
 		return err
 	}
 
-	if err := u.courierRepo.UpdateStatusTx(ctx, tx, courierID, "available"); err != nil {
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: "order unassigned", OrderID: orderID})
+	if err := u.releaseCourierTx(ctx, tx, delivery); err != nil {
 		return err
 	}
 
 	return tx.Commit(ctx)
 }
 
-func (u *DeliveryUsecase) AssignForEvent(ctx context.Context, orderID string) error {
+func (u *DeliveryUsecase) AssignForEvent(ctx context.Context, o model.ExternalOrder) error {
+	orderID := o.ID
+	o = u.enrichOrder(ctx, o)
+
+	if !o.ValidWindow() {
+		return ErrBadInput
+	}
+	if u.schedulable(o, time.Now()) {
+		err := u.schedule(ctx, o, 0)
+		if errors.Is(err, ErrOrderAlreadyAssigned) {
+			log.Printf("Order %s already assigned, skipping", orderID)
+			return nil
+		}
+		return err
+	}
+
 	tx, err := u.pool.Begin(ctx)
 	if err != nil {
 		return err
@@ -128,25 +894,59 @@ func (u *DeliveryUsecase) AssignForEvent(ctx context.Context, orderID string) er
 		return nil
 	}
 
-	courier, err := u.courierRepo.FindAvailableCourier(ctx)
+	if u.cfg.Batching && u.routes != nil {
+		tx.Rollback(ctx)
+		if err := u.routes.Collect(ctx, o); err != nil {
+			return err
+		}
+		log.Printf("Order %s collected for route batching", orderID)
+		return nil
+	}
+
+	yield, err := u.yieldToQueue(ctx, o)
+	if err != nil {
+		return err
+	}
+	if yield {
+		tx.Rollback(ctx)
+		return u.enqueue(ctx, o)
+	}
+
+	if u.offering() {
+		tx.Rollback(ctx)
+		return u.offerOrQueue(ctx, o)
+	}
+
+	courier, err := u.selectCourierTx(ctx, tx, o, nil)
+	if u.queueable(err) {
+		tx.Rollback(ctx)
+		return u.enqueue(ctx, o)
+	}
 	if err != nil {
 		return err
 	}
 
 	now := time.Now().UTC()
-	deadline := u.factory.Deadline(now, courier.TransportType)
+	deadline := u.factory.DeadlineFor(now, courier.TransportType, o)
 
 	delivery := &model.Delivery{
-		CourierID:  courier.ID,
-		OrderID:    orderID,
-		AssignedAt: now,
-		Deadline:   deadline,
+		CourierID:   courier.ID,
+		OrderID:     orderID,
+		AssignedAt:  now,
+		Deadline:    deadline,
+		Priority:    o.Priority,
+		WindowStart: o.WindowStart,
+		WindowEnd:   o.WindowEnd,
+		ETA:         u.dropoffETA(now, courier.TransportType, o),
+		PINHash:     o.PINHash,
+		MerchantID:  o.MerchantID,
 	}
 
 	if err := u.deliveryRepo.CreateTx(ctx, tx, delivery); err != nil {
 		return err
 	}
 
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: "order assigned", OrderID: orderID})
 	if err := u.courierRepo.UpdateStatusTx(ctx, tx, courier.ID, "busy"); err != nil {
 		return err
 	}
@@ -167,18 +967,63 @@ func (u *DeliveryUsecase) UnassignForEvent(ctx context.Context, orderID string)
 
 	delivery, err := u.deliveryRepo.GetByOrderIDTx(ctx, tx, orderID)
 	if err != nil {
-		if err.Error() == "delivery not found" {
+		if errors.Is(err, ErrDeliveryNotFound) {
+			removed, err := u.dequeue(ctx, orderID)
+			if err != nil {
+				return err
+			}
+			if removed {
+				log.Printf("Order %s removed from assignment queue", orderID)
+				return nil
+			}
+			if u.routes != nil {
+				uncollected, err := u.routes.Uncollect(ctx, orderID)
+				if err != nil {
+					return err
+				}
+				if uncollected {
+					log.Printf("Order %s removed from route batching", orderID)
+					return nil
+				}
+			}
+			if u.offers != nil {
+				withdrawn, err := u.offers.Cancel(ctx, orderID)
+				if err != nil {
+					return err
+				}
+				if withdrawn {
+					log.Printf("Offer of order %s withdrawn", orderID)
+					return nil
+				}
+			}
+			if u.scheduled != nil {
+				cancelled, err := u.scheduled.Cancel(ctx, orderID)
+				if err != nil {
+					return err
+				}
+				if cancelled {
+					log.Printf("Scheduled delivery for order %s cancelled", orderID)
+					return nil
+				}
+			}
 			log.Printf("Delivery for order %s not found, skipping", orderID)
 			return nil
 		}
 		return err
 	}
+	// A finished delivery keeps its record, and its courier may already be
+	// on another one.
+	if !activeDelivery(delivery.Status) {
+		log.Printf("Delivery for order %s is %s, skipping cancellation", orderID, delivery.Status)
+		return nil
+	}
 
 	if _, err := u.deliveryRepo.DeleteByOrderIDTx(ctx, tx, orderID); err != nil {
 		return err
 	}
 
-	if err := u.courierRepo.UpdateStatusTx(ctx, tx, delivery.CourierID, "available"); err != nil {
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: "order cancelled", OrderID: orderID})
+	if err := u.releaseCourierTx(ctx, tx, delivery); err != nil {
 		return err
 	}
 
@@ -194,24 +1039,77 @@ func (u *DeliveryUsecase) CompleteForEvent(ctx context.Context, orderID string)
 
 	delivery, err := u.deliveryRepo.GetByOrderIDTx(ctx, tx, orderID)
 	if err != nil {
-		if err.Error() == "delivery not found" {
+		if errors.Is(err, ErrDeliveryNotFound) {
 			log.Printf("Delivery for order %s not found, skipping completion", orderID)
 			return nil
 		}
 		return err
 	}
+	if !activeDelivery(delivery.Status) {
+		log.Printf("Delivery for order %s is %s, skipping completion", orderID, delivery.Status)
+		return nil
+	}
 
-	if err := u.courierRepo.UpdateStatusTx(ctx, tx, delivery.CourierID, "available"); err != nil {
+	if err := u.deliveryRepo.UpdateStatusTx(ctx, tx, orderID, "completed"); err != nil {
+		return err
+	}
+
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: "order completed", OrderID: orderID})
+	if err := u.releaseCourierTx(ctx, tx, delivery); err != nil {
 		return err
 	}
 
 	return tx.Commit(ctx)
 }
 
+// releaseCourierTx makes the courier of a finished or dropped delivery
+// available again. A courier on a route stays busy until the last delivery
+// of the route is done.
+func (u *DeliveryUsecase) releaseCourierTx(ctx context.Context, tx pgx.Tx, d model.Delivery) error {
+	if d.RouteID != nil && u.routes != nil {
+		done, err := u.routes.CompleteIfDoneTx(ctx, tx, *d.RouteID)
+		if err != nil {
+			return err
+		}
+		if !done {
+			return nil
+		}
+	}
+	return u.courierRepo.UpdateStatusTx(ctx, tx, d.CourierID, "available")
+}
+
 func (u *DeliveryUsecase) GetByOrderID(ctx context.Context, orderID string) (model.Delivery, error) {
 	return u.deliveryRepo.GetByOrderID(ctx, orderID)
 }
 
+func (u *DeliveryUsecase) GetByID(ctx context.Context, id int) (model.Delivery, error) {
+	if id <= 0 {
+		return model.Delivery{}, ErrBadInput
+	}
+	return u.deliveryRepo.GetByID(ctx, id)
+}
+
+const (
+	defaultDeliveryPageSize = 50
+	maxDeliveryPageSize     = 500
+)
+
+// List returns a page of deliveries, newest first, with their ETA and proof.
+func (u *DeliveryUsecase) List(ctx context.Context, q model.DeliveryQuery) ([]model.Delivery, error) {
+	if q.Limit == 0 {
+		q.Limit = defaultDeliveryPageSize
+	}
+	if q.CourierID < 0 || q.Limit < 0 || q.Limit > maxDeliveryPageSize || q.Offset < 0 {
+		return nil, ErrBadInput
+	}
+	for _, status := range q.Statuses {
+		if !model.ValidDeliveryStatus(status) {
+			return nil, ErrBadInput
+		}
+	}
+	return u.deliveryRepo.List(ctx, q)
+}
+
 func (u *DeliveryUsecase) Create(ctx context.Context, d *model.Delivery) error {
 	return u.deliveryRepo.Create(ctx, d)
 }
@@ -220,29 +1118,8 @@ func (u *DeliveryUsecase) DeleteByOrderID(ctx context.Context, orderID string) e
 	return u.deliveryRepo.DeleteByOrderID(ctx, orderID)
 }
 
-func (u *DeliveryUsecase) AutoRelease(ctx context.Context, interval time.Duration) {
-	if interval <= 0 {
-		interval = 10 * time.Second
-	}
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			u.pool.Exec(ctx, `
-				UPDATE couriers
-				SET status = 'available', updated_at = NOW()
-				FROM (
-					SELECT DISTINCT courier_id
-					FROM deliveries
-					WHERE deadline < NOW()
-				) d
-				WHERE couriers.id = d.courier_id
-				  AND couriers.status = 'busy';
-			`)
-		}
-	}
+// ReleaseOverdue is used by the delivery releaser. It expires the active
+// deliveries past their deadline and returns the couriers made available.
+func (u *DeliveryUsecase) ReleaseOverdue(ctx context.Context) ([]int, error) {
+	return u.courierRepo.ReleaseOverdue(ctx)
 }
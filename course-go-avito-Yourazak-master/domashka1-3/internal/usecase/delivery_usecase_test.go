@@ -0,0 +1,315 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNearestCandidate(t *testing.T) {
+	pickup := &model.GeoPoint{Lat: 55.7558, Lon: 37.6173}
+	candidates := []model.CourierCandidate{
+		{Courier: model.Courier{ID: 1}, Location: &model.CourierLocation{Lat: 55.80, Lon: 37.70}},
+		{Courier: model.Courier{ID: 2}, Location: &model.CourierLocation{Lat: 55.76, Lon: 37.62}},
+		{Courier: model.Courier{ID: 3}},
+		{Courier: model.Courier{ID: 4}, Location: &model.CourierLocation{Lat: 56.50, Lon: 38.50}},
+	}
+
+	best, ok := nearestCandidate(candidates, pickup, 10)
+
+	assert.True(t, ok)
+	assert.Equal(t, 2, best.Courier.ID)
+	assert.Less(t, best.DistanceKm, 1.0)
+}
+
+func TestNearestCandidate_OutsideRadius(t *testing.T) {
+	pickup := &model.GeoPoint{Lat: 55.7558, Lon: 37.6173}
+	candidates := []model.CourierCandidate{
+		{Courier: model.Courier{ID: 4}, Location: &model.CourierLocation{Lat: 56.50, Lon: 38.50}},
+	}
+
+	_, ok := nearestCandidate(candidates, pickup, 10)
+
+	assert.False(t, ok)
+}
+
+func TestNearestCandidate_FallsBackToUnlocated(t *testing.T) {
+	pickup := &model.GeoPoint{Lat: 55.7558, Lon: 37.6173}
+	candidates := []model.CourierCandidate{
+		{Courier: model.Courier{ID: 4}, Location: &model.CourierLocation{Lat: 56.50, Lon: 38.50}},
+		{Courier: model.Courier{ID: 5}},
+		{Courier: model.Courier{ID: 6}},
+	}
+
+	best, ok := nearestCandidate(candidates, pickup, 10)
+
+	assert.True(t, ok)
+	assert.Equal(t, 5, best.Courier.ID)
+
+	// A located courier inside the radius still wins over an unlocated one
+	// listed before it.
+	candidates = append([]model.CourierCandidate{{Courier: model.Courier{ID: 7}}},
+		model.CourierCandidate{Courier: model.Courier{ID: 8}, Location: &model.CourierLocation{Lat: 55.76, Lon: 37.62}})
+	best, ok = nearestCandidate(candidates, pickup, 10)
+
+	assert.True(t, ok)
+	assert.Equal(t, 8, best.Courier.ID)
+}
+
+func TestNearestCandidate_NoPickup(t *testing.T) {
+	candidates := []model.CourierCandidate{
+		{Courier: model.Courier{ID: 7}},
+		{Courier: model.Courier{ID: 8}},
+	}
+
+	best, ok := nearestCandidate(candidates, nil, 10)
+
+	assert.True(t, ok)
+	assert.Equal(t, 7, best.Courier.ID)
+
+	_, ok = nearestCandidate(nil, nil, 10)
+	assert.False(t, ok)
+}
+
+func TestCapableCandidates(t *testing.T) {
+	pickup := &model.GeoPoint{Lat: 55.7558, Lon: 37.6173}
+	near := &model.CourierLocation{Lat: 55.76, Lon: 37.62}
+	candidates := []model.CourierCandidate{
+		{Courier: model.Courier{ID: 1, TransportType: "on_foot"}, Location: near},
+		{Courier: model.Courier{ID: 2, TransportType: "on_foot"}, Location: near, Overrides: model.TransportLimits{MaxWeightKg: 30}},
+		{Courier: model.Courier{ID: 3, TransportType: "car"}, Location: near},
+	}
+
+	heavy := capableCandidates(candidates, model.ExternalOrder{ID: "o1", Weight: 25, Pickup: pickup}, DefaultTransportLimits)
+	assert.Len(t, heavy, 2)
+	assert.Equal(t, 2, heavy[0].Courier.ID)
+	assert.Equal(t, 3, heavy[1].Courier.ID)
+
+	far := model.ExternalOrder{ID: "o2", Weight: 1, Pickup: pickup, Dropoff: &model.GeoPoint{Lat: 55.80, Lon: 37.70}}
+	farResult := capableCandidates(candidates, far, DefaultTransportLimits)
+	assert.Len(t, farResult, 1)
+	assert.Equal(t, 3, farResult[0].Courier.ID)
+}
+
+func TestDeliveryUsecase_ManualAssignment_InvalidInput(t *testing.T) {
+	u := NewDeliveryUsecase(nil, nil, nil, nil, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+
+	_, _, err := u.AssignTo(context.Background(), model.ExternalOrder{ID: "o1"}, 0)
+	assert.Equal(t, ErrBadInput, err)
+
+	_, _, err = u.Reassign(context.Background(), 0, 5, "")
+	assert.Equal(t, ErrBadInput, err)
+
+	_, _, err = u.Reassign(context.Background(), 3, -1, "")
+	assert.Equal(t, ErrBadInput, err)
+}
+
+func TestDeliveryTimeFactory_Deadline_Priority(t *testing.T) {
+	f := NewDeliveryTimeFactory()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, now.Add(15*time.Minute), f.Deadline(now, string(Scooter), model.PriorityStandard))
+	assert.Equal(t, now.Add(15*time.Minute), f.Deadline(now, string(Scooter), ""))
+	assert.Equal(t, now.Add(15*time.Minute/2), f.Deadline(now, string(Scooter), model.PriorityExpress))
+	assert.Equal(t, now.Add(60*time.Minute), f.Deadline(now, string(OnFoot), model.PriorityScheduled))
+}
+
+func TestDeliveryUsecase_Assign_YieldsToHigherPriorityQueue(t *testing.T) {
+	pending := new(MockPendingAssignmentRepository)
+	u := NewDeliveryUsecase(nil, nil, nil, pending, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+
+	express := model.PriorityRank(model.PriorityExpress)
+	pending.On("Stats", mock.Anything).Return(model.AssignmentQueueStats{Depth: 1, TopPriority: express}, nil)
+	pending.On("ListDue", mock.Anything, mock.Anything, yieldScanLimit).
+		Return([]model.PendingAssignment{{ID: 1, OrderID: "o0", Priority: express, Order: model.ExternalOrder{ID: "o0"}}}, nil)
+	pending.On("Enqueue", mock.Anything, mock.MatchedBy(func(p *model.PendingAssignment) bool {
+		return p.OrderID == "o1" && p.Priority == model.PriorityRank(model.PriorityStandard)
+	})).Return(nil)
+
+	_, _, err := u.Assign(context.Background(), model.ExternalOrder{ID: "o1"})
+
+	assert.Equal(t, ErrAssignmentQueued, err)
+	pending.AssertExpectations(t)
+}
+
+func TestDeliveryUsecase_YieldToQueue_SamePriority(t *testing.T) {
+	pending := new(MockPendingAssignmentRepository)
+	u := NewDeliveryUsecase(nil, nil, nil, pending, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+
+	pending.On("Stats", mock.Anything).Return(model.AssignmentQueueStats{Depth: 3, TopPriority: model.PriorityRank(model.PriorityExpress)}, nil)
+
+	yield, err := u.yieldToQueue(context.Background(), model.ExternalOrder{ID: "o1", Priority: model.PriorityExpress})
+
+	assert.NoError(t, err)
+	assert.False(t, yield)
+}
+
+func TestDeliveryUsecase_Queueable(t *testing.T) {
+	u := NewDeliveryUsecase(nil, nil, nil, new(MockPendingAssignmentRepository), nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+
+	assert.True(t, u.queueable(ErrNoAvailableCourier))
+	assert.False(t, u.queueable(ErrNoCapableCourier))
+	assert.False(t, u.queueable(ErrCourierUnavailable))
+
+	u = NewDeliveryUsecase(nil, nil, nil, nil, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+	assert.False(t, u.queueable(ErrNoAvailableCourier))
+}
+
+func TestDeliveryUsecase_YieldToQueue_IgnoresBackingOff(t *testing.T) {
+	pending := new(MockPendingAssignmentRepository)
+	u := NewDeliveryUsecase(nil, nil, nil, pending, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+
+	// The express order is queued but not due, so ListDue leaves it out.
+	pending.On("Stats", mock.Anything).Return(model.AssignmentQueueStats{Depth: 1, TopPriority: model.PriorityRank(model.PriorityExpress)}, nil)
+	pending.On("ListDue", mock.Anything, mock.Anything, yieldScanLimit).Return([]model.PendingAssignment{}, nil)
+
+	yield, err := u.yieldToQueue(context.Background(), model.ExternalOrder{ID: "o1", Priority: model.PriorityStandard})
+
+	assert.NoError(t, err)
+	assert.False(t, yield)
+}
+
+func TestDeliveryUsecase_YieldToQueue_OtherZone(t *testing.T) {
+	zoneRepo := new(MockZoneRepository)
+	north, south := 1, 2
+	zoneRepo.On("FindByRegion", mock.Anything, north).Return(model.DeliveryZone{ID: 10, RegionID: &north}, nil)
+	zoneRepo.On("FindByRegion", mock.Anything, south).Return(model.DeliveryZone{ID: 20, RegionID: &south, NeighbourIDs: []int{10}}, nil)
+	pending := new(MockPendingAssignmentRepository)
+	u := NewDeliveryUsecase(nil, nil, nil, pending, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, NewZoneUsecase(zoneRepo, nil), AssignmentConfig{})
+
+	express := model.PriorityRank(model.PriorityExpress)
+	pending.On("Stats", mock.Anything).Return(model.AssignmentQueueStats{Depth: 1, TopPriority: express}, nil)
+	pending.On("ListDue", mock.Anything, mock.Anything, yieldScanLimit).
+		Return([]model.PendingAssignment{{ID: 1, OrderID: "o0", Priority: express, Order: model.ExternalOrder{ID: "o0", Region: south}}}, nil)
+
+	// Without spillover the southern order cannot take a northern courier.
+	yield, err := u.yieldToQueue(context.Background(), model.ExternalOrder{ID: "o1", Region: north})
+	assert.NoError(t, err)
+	assert.False(t, yield)
+
+	// With spillover it can.
+	u.cfg.ZoneSpillover = true
+	yield, err = u.yieldToQueue(context.Background(), model.ExternalOrder{ID: "o1", Region: north})
+	assert.NoError(t, err)
+	assert.True(t, yield)
+}
+
+func TestTiersOverlap(t *testing.T) {
+	assert.True(t, tiersOverlap([][]int{nil}, [][]int{{1}}))
+	assert.True(t, tiersOverlap([][]int{{1}, {2, 3}}, [][]int{{3}}))
+	assert.False(t, tiersOverlap([][]int{{1}}, [][]int{{2}, {3}}))
+}
+
+func TestDeliveryTimeFactory_DeadlineFor_Window(t *testing.T) {
+	f := NewDeliveryTimeFactory()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	start, end := now.Add(-10*time.Minute), now.Add(50*time.Minute)
+	early := now.Add(5 * time.Minute)
+
+	assert.Equal(t, end, f.DeadlineFor(now, string(Scooter), model.ExternalOrder{WindowStart: &start, WindowEnd: &end}))
+	assert.Equal(t, early, f.DeadlineFor(now, string(Scooter), model.ExternalOrder{WindowStart: &start, WindowEnd: &early}))
+	assert.Equal(t, now.Add(15*time.Minute), f.DeadlineFor(now, string(Scooter), model.ExternalOrder{}))
+}
+
+func TestDeliveryTimeFactory_DeadlineFor_ClampsToWindowEnd(t *testing.T) {
+	f := NewDeliveryTimeFactory()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	end := now.Add(2 * time.Minute)
+
+	deadline := f.DeadlineFor(now, string(Car), model.ExternalOrder{Priority: model.PriorityExpress, WindowEnd: &end})
+
+	assert.Equal(t, end, deadline)
+	assert.True(t, deadline.Before(f.Deadline(now, string(Car), model.PriorityExpress)))
+}
+
+func TestDeliveryUsecase_Assign_SchedulesFutureWindow(t *testing.T) {
+	scheduled := new(MockScheduledDeliveryRepository)
+	u := NewDeliveryUsecase(nil, nil, nil, nil, scheduled, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{ScheduleLeadTime: 30 * time.Minute})
+	start := time.Now().Add(2 * time.Hour)
+	end := start.Add(time.Hour)
+
+	scheduled.On("Create", mock.Anything, mock.MatchedBy(func(s *model.ScheduledDelivery) bool {
+		return s.OrderID == "o1" && s.CourierID != nil && *s.CourierID == 4 && s.WindowStart.Equal(start) && s.WindowEnd.Equal(end)
+	})).Return(nil)
+
+	_, _, err := u.AssignTo(context.Background(), model.ExternalOrder{ID: "o1", WindowStart: &start, WindowEnd: &end}, 4)
+
+	assert.Equal(t, ErrDeliveryScheduled, err)
+	scheduled.AssertExpectations(t)
+}
+
+func TestDeliveryUsecase_Assign_InvalidWindow(t *testing.T) {
+	scheduled := new(MockScheduledDeliveryRepository)
+	u := NewDeliveryUsecase(nil, nil, nil, nil, scheduled, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+	start := time.Now().Add(2 * time.Hour)
+	before := start.Add(-time.Hour)
+
+	_, _, err := u.Assign(context.Background(), model.ExternalOrder{ID: "o1", WindowStart: &start, WindowEnd: &before})
+	assert.Equal(t, ErrBadInput, err)
+
+	_, _, err = u.Assign(context.Background(), model.ExternalOrder{ID: "o1", WindowStart: &start})
+	assert.Equal(t, ErrBadInput, err)
+
+	scheduled.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestDeliveryUsecase_AssignScheduled_QueuesWithoutCourier(t *testing.T) {
+	scheduled := new(MockScheduledDeliveryRepository)
+	pending := new(MockPendingAssignmentRepository)
+	u := NewDeliveryUsecase(nil, nil, nil, pending, scheduled, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+
+	express := model.PriorityRank(model.PriorityExpress)
+	pending.On("Stats", mock.Anything).Return(model.AssignmentQueueStats{Depth: 1, TopPriority: express}, nil)
+	pending.On("ListDue", mock.Anything, mock.Anything, yieldScanLimit).
+		Return([]model.PendingAssignment{{ID: 1, OrderID: "o0", Priority: express, Order: model.ExternalOrder{ID: "o0"}}}, nil)
+	pending.On("Enqueue", mock.Anything, mock.MatchedBy(func(p *model.PendingAssignment) bool { return p.OrderID == "o1" })).Return(nil)
+	scheduled.On("SetState", mock.Anything, int64(9), model.ScheduledQueued, (*int)(nil), (*int)(nil)).Return(nil)
+
+	err := u.AssignScheduled(context.Background(), model.ScheduledDelivery{ID: 9, OrderID: "o1", Order: model.ExternalOrder{ID: "o1"}})
+
+	assert.NoError(t, err)
+	pending.AssertExpectations(t)
+	scheduled.AssertExpectations(t)
+}
+
+func TestDeliveryUsecase_GetByID(t *testing.T) {
+	repo := new(MockDeliveryRepository)
+	u := NewDeliveryUsecase(nil, nil, repo, nil, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+	eta := time.Now().Add(20 * time.Minute)
+	repo.On("GetByID", mock.Anything, 5).Return(model.Delivery{ID: 5, ETA: &eta, Proof: &model.DeliveryProof{PINVerified: true}}, nil)
+
+	d, err := u.GetByID(context.Background(), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, &eta, d.ETA)
+	assert.True(t, d.Proof.PINVerified)
+
+	_, err = u.GetByID(context.Background(), 0)
+	assert.Equal(t, ErrBadInput, err)
+}
+
+func TestDeliveryUsecase_List(t *testing.T) {
+	repo := new(MockDeliveryRepository)
+	u := NewDeliveryUsecase(nil, nil, repo, nil, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+	repo.On("List", mock.Anything, model.DeliveryQuery{CourierID: 7, Statuses: []string{"completed"}, Limit: defaultDeliveryPageSize}).
+		Return([]model.Delivery{{ID: 1, CourierID: 7, Status: "completed"}}, nil)
+
+	items, err := u.List(context.Background(), model.DeliveryQuery{CourierID: 7, Statuses: []string{"completed"}})
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+
+	for _, q := range []model.DeliveryQuery{
+		{Statuses: []string{"cancelled"}},
+		{Limit: maxDeliveryPageSize + 1},
+		{Offset: -1},
+		{CourierID: -1},
+	} {
+		_, err := u.List(context.Background(), q)
+		assert.Equal(t, ErrBadInput, err)
+	}
+	repo.AssertNumberOfCalls(t, "List", 1)
+}
@@ -17,7 +17,7 @@ func NewEventDeliveryUsecase(deliveryUC *DeliveryUsecase) *EventDeliveryUsecase
 }
 
 func (uc *EventDeliveryUsecase) HandleCreated(ctx context.Context, event model.OrderEvent) error {
-	return uc.deliveryUC.AssignForEvent(ctx, event.OrderID)
+	return uc.deliveryUC.AssignForEvent(ctx, event.Order())
 }
 
 func (uc *EventDeliveryUsecase) HandleCancelled(ctx context.Context, event model.OrderEvent) error {
@@ -9,6 +9,10 @@ type EventHandler interface {
 	Handle(ctx context.Context, event model.OrderEvent) error
 }
 
+type EventDispatcher interface {
+	GetHandler(status string) EventHandler
+}
+
 type EventHandlerFactory struct {
 	eventDeliveryUC *EventDeliveryUsecase
 }
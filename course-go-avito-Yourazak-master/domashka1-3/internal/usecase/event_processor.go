@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"avito-courier/internal/gateway/order"
+	"avito-courier/internal/model"
+)
+
+var (
+	ErrInvalidEvent   = errors.New("invalid order event")
+	ErrStatusMismatch = errors.New("order status mismatch")
+	ErrNoEventHandler = errors.New("no handler for order status")
+)
+
+// OrderEventProcessor is the single handling path for order events,
+// regardless of whether they came from Kafka, the poller or a webhook.
+type OrderEventProcessor interface {
+	Process(ctx context.Context, event model.OrderEvent) error
+}
+
+type orderEventProcessor struct {
+	dispatcher   EventDispatcher
+	orderGateway order.OrderGateway
+}
+
+func NewOrderEventProcessor(dispatcher EventDispatcher, gateway order.OrderGateway) OrderEventProcessor {
+	return &orderEventProcessor{
+		dispatcher:   dispatcher,
+		orderGateway: gateway,
+	}
+}
+
+func (p *orderEventProcessor) Process(ctx context.Context, event model.OrderEvent) error {
+	if !event.Validate() {
+		return ErrInvalidEvent
+	}
+
+	if p.orderGateway != nil {
+		actualOrder, err := p.orderGateway.GetOrderStatus(ctx, event.OrderID)
+		if err != nil {
+			log.Printf("Failed to verify order status for %s: %v", event.OrderID, err)
+		} else if actualOrder.Status != event.Status {
+			log.Printf("Status mismatch for %s: event=%s, actual=%s - skipping",
+				event.OrderID, event.Status, actualOrder.Status)
+			return ErrStatusMismatch
+		}
+	}
+
+	handler := p.dispatcher.GetHandler(event.Status)
+	if handler == nil {
+		return ErrNoEventHandler
+	}
+
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Actor: model.ActorEvent, OrderID: event.OrderID})
+	return handler.Handle(ctx, event)
+}
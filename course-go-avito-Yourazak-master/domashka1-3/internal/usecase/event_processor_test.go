@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOrderEventProcessor_Process(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Invalid event", func(t *testing.T) {
+		processor := NewOrderEventProcessor(&MockEventDispatcher{}, nil)
+
+		err := processor.Process(context.Background(), model.OrderEvent{OrderID: "o1", Status: "unknown", CreatedAt: createdAt})
+
+		assert.ErrorIs(t, err, ErrInvalidEvent)
+	})
+
+	t.Run("Status mismatch", func(t *testing.T) {
+		mockGateway := new(MockOrderGateway)
+		mockHandler := new(MockEventHandler)
+		mockGateway.On("GetOrderStatus", mock.Anything, "o1").Return(&model.OrderEvent{OrderID: "o1", Status: "cancelled"}, nil)
+
+		processor := NewOrderEventProcessor(&MockEventDispatcher{handlers: map[string]EventHandler{"created": mockHandler}}, mockGateway)
+
+		err := processor.Process(context.Background(), model.OrderEvent{OrderID: "o1", Status: "created", CreatedAt: createdAt})
+
+		assert.ErrorIs(t, err, ErrStatusMismatch)
+		mockHandler.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Dispatches to handler", func(t *testing.T) {
+		mockHandler := new(MockEventHandler)
+		mockHandler.On("Handle", mock.Anything, "o1").Return(nil)
+
+		processor := NewOrderEventProcessor(&MockEventDispatcher{handlers: map[string]EventHandler{"completed": mockHandler}}, nil)
+
+		err := processor.Process(context.Background(), model.OrderEvent{OrderID: "o1", Status: "completed", CreatedAt: createdAt})
+
+		assert.NoError(t, err)
+		mockHandler.AssertCalled(t, "Handle", mock.Anything, "o1")
+	})
+}
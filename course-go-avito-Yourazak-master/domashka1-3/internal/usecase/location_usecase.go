@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
+)
+
+// maxLocationClockSkew bounds how far in the future a device timestamp may be.
+const maxLocationClockSkew = time.Minute
+
+type LocationUsecase interface {
+	Report(ctx context.Context, loc *model.CourierLocation) error
+	GetLatest(ctx context.Context, courierID int) (model.CourierLocation, error)
+}
+
+type locationUsecase struct {
+	repo repository.LocationRepository
+}
+
+func NewLocationUsecase(r repository.LocationRepository) LocationUsecase {
+	return &locationUsecase{repo: r}
+}
+
+func (u *locationUsecase) Report(ctx context.Context, loc *model.CourierLocation) error {
+	if loc.CourierID <= 0 || !loc.Point().Valid() || loc.AccuracyM < 0 {
+		return ErrBadInput
+	}
+
+	now := time.Now().UTC()
+	if loc.RecordedAt.IsZero() {
+		loc.RecordedAt = now
+	}
+	if loc.RecordedAt.After(now.Add(maxLocationClockSkew)) {
+		return ErrBadInput
+	}
+
+	return u.repo.Save(ctx, loc)
+}
+
+func (u *locationUsecase) GetLatest(ctx context.Context, courierID int) (model.CourierLocation, error) {
+	if courierID <= 0 {
+		return model.CourierLocation{}, ErrBadInput
+	}
+	return u.repo.GetLatest(ctx, courierID)
+}
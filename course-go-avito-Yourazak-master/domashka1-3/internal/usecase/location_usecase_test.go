@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLocationRepository struct {
+	mock.Mock
+}
+
+func (m *MockLocationRepository) Save(ctx context.Context, loc *model.CourierLocation) error {
+	args := m.Called(ctx, loc)
+	return args.Error(0)
+}
+
+func (m *MockLocationRepository) GetLatest(ctx context.Context, courierID int) (model.CourierLocation, error) {
+	args := m.Called(ctx, courierID)
+	return args.Get(0).(model.CourierLocation), args.Error(1)
+}
+
+func TestLocationUsecase_Report_Success(t *testing.T) {
+	mockRepo := new(MockLocationRepository)
+	service := NewLocationUsecase(mockRepo)
+
+	loc := &model.CourierLocation{CourierID: 1, Lat: 55.75, Lon: 37.61}
+	mockRepo.On("Save", mock.Anything, loc).Return(nil)
+
+	err := service.Report(context.Background(), loc)
+
+	assert.NoError(t, err)
+	assert.False(t, loc.RecordedAt.IsZero())
+}
+
+func TestLocationUsecase_Report_InvalidData(t *testing.T) {
+	service := NewLocationUsecase(new(MockLocationRepository))
+
+	testCases := []struct {
+		name string
+		loc  *model.CourierLocation
+	}{
+		{"No courier", &model.CourierLocation{Lat: 55.75, Lon: 37.61}},
+		{"Latitude out of range", &model.CourierLocation{CourierID: 1, Lat: 95, Lon: 37.61}},
+		{"Negative accuracy", &model.CourierLocation{CourierID: 1, Lat: 55.75, Lon: 37.61, AccuracyM: -1}},
+		{"From the future", &model.CourierLocation{CourierID: 1, Lat: 55.75, Lon: 37.61, RecordedAt: time.Now().Add(time.Hour)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := service.Report(context.Background(), tc.loc)
+			assert.Equal(t, ErrBadInput, err)
+		})
+	}
+}
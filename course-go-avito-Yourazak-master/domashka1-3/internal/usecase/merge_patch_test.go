@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	cases := []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":"foo"}`, `["bar"]`, `["bar"]`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+	}
+
+	for _, tc := range cases {
+		got, err := applyMergePatch([]byte(tc.target), []byte(tc.patch))
+		assert.NoError(t, err)
+		assert.JSONEq(t, tc.want, string(got), tc.patch)
+	}
+
+	_, err := applyMergePatch([]byte(`{}`), []byte(`{`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
+)
+
+// OfferExpirerLockKey is the pg advisory lock key that elects the single
+// replica allowed to expire delivery offers.
+const OfferExpirerLockKey int64 = 7_341_005
+
+type OfferExpirerConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+type offerExpirer interface {
+	ExpireOffers(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+// OfferExpirer expires offers nobody answered in time so that the order
+// moves on to the next courier.
+type OfferExpirer struct {
+	expirer   offerExpirer
+	locker    repository.Locker
+	interval  time.Duration
+	batchSize int
+}
+
+func NewOfferExpirer(expirer offerExpirer, locker repository.Locker, cfg OfferExpirerConfig) *OfferExpirer {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	return &OfferExpirer{
+		expirer:   expirer,
+		locker:    locker,
+		interval:  cfg.Interval,
+		batchSize: cfg.BatchSize,
+	}
+}
+
+func (e *OfferExpirer) Start(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	defer e.locker.Release(context.Background())
+
+	log.Printf("Offer expirer started (ticker: %v)", e.interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Offer expirer stopped")
+			return
+		case t := <-ticker.C:
+			acquired, err := e.locker.TryAcquire(ctx)
+			if err != nil {
+				log.Printf("Offer expirer: failed to acquire lock: %v", err)
+				continue
+			}
+			if acquired {
+				e.runOnce(ctx, t.UTC())
+			}
+		}
+	}
+}
+
+func (e *OfferExpirer) runOnce(ctx context.Context, now time.Time) {
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Actor: model.ActorOfferExpirer})
+	if _, err := e.expirer.ExpireOffers(ctx, now, e.batchSize); err != nil {
+		log.Printf("Offer expirer: failed to expire offers: %v", err)
+	}
+}
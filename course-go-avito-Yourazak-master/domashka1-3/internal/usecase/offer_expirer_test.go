@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOfferRepository struct {
+	mock.Mock
+}
+
+func (m *MockOfferRepository) CreateTx(ctx context.Context, tx pgx.Tx, o *model.DeliveryOffer) error {
+	args := m.Called(ctx, tx, o)
+	return args.Error(0)
+}
+
+func (m *MockOfferRepository) PendingForOrderTx(ctx context.Context, tx pgx.Tx, orderID string) (bool, error) {
+	args := m.Called(ctx, tx, orderID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockOfferRepository) OfferedCouriersTx(ctx context.Context, tx pgx.Tx, orderID string, since time.Time) ([]int, error) {
+	args := m.Called(ctx, tx, orderID, since)
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockOfferRepository) GetForUpdateTx(ctx context.Context, tx pgx.Tx, id int64) (model.DeliveryOffer, error) {
+	args := m.Called(ctx, tx, id)
+	return args.Get(0).(model.DeliveryOffer), args.Error(1)
+}
+
+func (m *MockOfferRepository) RespondTx(ctx context.Context, tx pgx.Tx, o *model.DeliveryOffer, state string) error {
+	args := m.Called(ctx, tx, o, state)
+	return args.Error(0)
+}
+
+func (m *MockOfferRepository) ExpireDue(ctx context.Context, now time.Time, limit int) ([]model.DeliveryOffer, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]model.DeliveryOffer), args.Error(1)
+}
+
+func (m *MockOfferRepository) ListPendingByCourier(ctx context.Context, courierID int) ([]model.DeliveryOffer, error) {
+	args := m.Called(ctx, courierID)
+	return args.Get(0).([]model.DeliveryOffer), args.Error(1)
+}
+
+func (m *MockOfferRepository) Cancel(ctx context.Context, orderID string) (bool, error) {
+	args := m.Called(ctx, orderID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockOfferRepository) Stats(ctx context.Context, courierID int) (model.OfferStats, error) {
+	args := m.Called(ctx, courierID)
+	return args.Get(0).(model.OfferStats), args.Error(1)
+}
+
+type MockOfferExpirer struct {
+	mock.Mock
+}
+
+func (m *MockOfferExpirer) ExpireOffers(ctx context.Context, now time.Time, limit int) (int, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func TestOfferExpirer_RunOnce(t *testing.T) {
+	mockExpirer := new(MockOfferExpirer)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	fromExpirer := mock.MatchedBy(func(ctx context.Context) bool {
+		return model.StatusChangeFrom(ctx).Actor == model.ActorOfferExpirer
+	})
+	mockExpirer.On("ExpireOffers", fromExpirer, now, 100).Return(2, nil)
+
+	e := NewOfferExpirer(mockExpirer, new(MockLocker), OfferExpirerConfig{})
+	e.runOnce(context.Background(), now)
+
+	mockExpirer.AssertExpectations(t)
+}
+
+func TestDeliveryUsecase_ExpireOffers_NothingDue(t *testing.T) {
+	offers := new(MockOfferRepository)
+	u := NewDeliveryUsecase(nil, nil, nil, nil, nil, nil, offers, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{OfferMode: true})
+	now := time.Now()
+	offers.On("ExpireDue", mock.Anything, now, 10).Return([]model.DeliveryOffer{}, nil)
+
+	n, err := u.ExpireOffers(context.Background(), now, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	offers.AssertExpectations(t)
+}
+
+func TestDeliveryUsecase_Offers(t *testing.T) {
+	offers := new(MockOfferRepository)
+	u := NewDeliveryUsecase(nil, nil, nil, nil, nil, nil, offers, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{OfferMode: true})
+	assert.True(t, u.offering())
+	assert.Equal(t, time.Minute, u.cfg.OfferTTL)
+	assert.Equal(t, 10*time.Minute, u.cfg.OfferCooldown)
+
+	offers.On("ListPendingByCourier", mock.Anything, 7).Return([]model.DeliveryOffer{{ID: 1, CourierID: 7}}, nil)
+	offers.On("Stats", mock.Anything, 7).Return(model.OfferStats{CourierID: 7, Accepted: 1, AcceptanceRate: 1}, nil)
+
+	list, err := u.ListOffers(context.Background(), 7)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	stats, err := u.OfferStats(context.Background(), 7)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, stats.AcceptanceRate)
+
+	_, err = u.ListOffers(context.Background(), 0)
+	assert.Equal(t, ErrBadInput, err)
+	_, err = u.OfferStats(context.Background(), -1)
+	assert.Equal(t, ErrBadInput, err)
+	_, err = u.AcceptOffer(context.Background(), 7, 0)
+	assert.Equal(t, ErrBadInput, err)
+	assert.Equal(t, ErrBadInput, u.DeclineOffer(context.Background(), 0, 1))
+}
+
+func TestDeliveryUsecase_OfferingNeedsRepository(t *testing.T) {
+	u := NewDeliveryUsecase(nil, nil, nil, nil, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{OfferMode: true})
+	assert.False(t, u.offering())
+
+	_, err := u.AcceptOffer(context.Background(), 7, 1)
+	assert.Equal(t, ErrBadInput, err)
+}
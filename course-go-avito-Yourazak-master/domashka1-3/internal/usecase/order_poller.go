@@ -2,67 +2,250 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"log"
+	"sort"
 	"time"
 
 	"avito-courier/internal/gateway/order"
+	"avito-courier/internal/middleware"
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
 )
 
+const (
+	orderPollerCursorName = "order_poller"
+
+	// OrderPollerLockKey is the pg advisory lock key that elects the single
+	// replica allowed to poll the order service.
+	OrderPollerLockKey int64 = 7_341_001
+)
+
+type OrderPollerConfig struct {
+	Interval  time.Duration
+	Overlap   time.Duration
+	BatchSize int
+	// MaxRetries is how many times an event is tried before it is dropped
+	// so that it no longer holds the cursor back.
+	MaxRetries int
+}
+
 type OrderPoller struct {
 	gateway    order.OrderGateway
-	deliveryUC *DeliveryUsecase
+	processor  OrderEventProcessor
+	cursors    repository.CursorRepository
+	locker     repository.Locker
 	interval   time.Duration
-	lastFetch  time.Time
+	overlap    time.Duration
+	batchSize  int
+	maxRetries int
+	leader     bool
+
+	// cursor is the created_at of the newest event that has been processed
+	// without gaps; it is only moved forward after successful processing.
+	cursor time.Time
+	// seen holds events already processed inside the overlap window so that
+	// re-fetching [cursor-overlap, ...) does not handle them twice.
+	seen map[string]time.Time
+	// failures counts the failed attempts of events not yet processed.
+	failures map[string]int
 }
 
-func NewOrderPoller(gateway order.OrderGateway, deliveryUC *DeliveryUsecase) *OrderPoller {
+func NewOrderPoller(gateway order.OrderGateway, processor OrderEventProcessor, cursors repository.CursorRepository, locker repository.Locker, cfg OrderPollerConfig) *OrderPoller {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	if cfg.Overlap < 0 {
+		cfg.Overlap = 0
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 5
+	}
 	return &OrderPoller{
 		gateway:    gateway,
-		deliveryUC: deliveryUC,
-		interval:   5 * time.Second,
-		lastFetch:  time.Now().Add(-5 * time.Second),
+		processor:  processor,
+		cursors:    cursors,
+		locker:     locker,
+		interval:   cfg.Interval,
+		overlap:    cfg.Overlap,
+		batchSize:  cfg.BatchSize,
+		maxRetries: cfg.MaxRetries,
+		seen:       make(map[string]time.Time),
+		failures:   make(map[string]int),
 	}
 }
 
 func (p *OrderPoller) Start(ctx context.Context) {
 	ticker := time.NewTicker(p.interval)
 	defer ticker.Stop()
+	defer p.locker.Release(context.Background())
 
-	log.Printf("Order poller started (ticker: %v)", p.interval)
+	log.Printf("Order poller started (ticker: %v, batch: %d)", p.interval, p.batchSize)
 
 	for {
 		select {
 		case <-ctx.Done():
 			log.Println("Order poller stopped")
 			return
-		case t := <-ticker.C:
-			p.processTick(ctx, t)
+		case <-ticker.C:
+			if p.acquireLeadership(ctx) {
+				p.processTick(ctx)
+			}
 		}
 	}
 }
 
-func (p *OrderPoller) processTick(ctx context.Context, tickTime time.Time) {
-	start := time.Now()
-	cursor := tickTime.Add(-5 * time.Second)
+// acquireLeadership reports whether this replica holds the poller lock. The
+// cursor is reloaded every time leadership is (re)gained because another
+// replica may have advanced it in the meantime.
+func (p *OrderPoller) acquireLeadership(ctx context.Context) bool {
+	acquired, err := p.locker.TryAcquire(ctx)
+	if err != nil {
+		log.Printf("Order poller: failed to acquire lock: %v", err)
+		acquired = false
+	}
+
+	if acquired && !p.leader {
+		if err := p.loadCursor(ctx); err != nil {
+			log.Printf("Order poller: failed to load cursor: %v", err)
+			p.locker.Release(ctx)
+			return false
+		}
+		p.seen = make(map[string]time.Time)
+		p.failures = make(map[string]int)
+		log.Printf("Order poller: acquired leadership (cursor: %s)", p.cursor.Format(time.RFC3339))
+	}
+	if !acquired && p.leader {
+		log.Println("Order poller: lost leadership")
+	}
+
+	p.leader = acquired
+	return acquired
+}
 
-	orders, err := p.gateway.GetOrdersByCursor(ctx, cursor)
+// loadCursor starts from the stored cursor, or from one interval ago on the
+// very first run. Any other error is returned so that polling does not
+// silently skip the events since the stored cursor.
+func (p *OrderPoller) loadCursor(ctx context.Context) error {
+	cursor, err := p.cursors.Get(ctx, orderPollerCursorName)
 	if err != nil {
-		log.Printf("Failed to fetch orders: %v", err)
-		return
+		if errors.Is(err, repository.ErrNotFound) {
+			p.cursor = time.Now().UTC().Add(-p.interval)
+			return nil
+		}
+		return err
 	}
+	p.cursor = cursor
+	return nil
+}
 
-	if len(orders) == 0 {
-		return
+func (p *OrderPoller) processTick(ctx context.Context) {
+	start := time.Now()
+	from := p.cursor.Add(-p.overlap)
+	processed := 0
+
+	for {
+		orders, err := p.gateway.GetOrdersPage(ctx, from, p.batchSize)
+		if err != nil {
+			log.Printf("Failed to fetch orders: %v", err)
+			break
+		}
+
+		n, ok := p.processPage(ctx, orders)
+		processed += n
+
+		if err := p.cursors.Save(ctx, orderPollerCursorName, p.cursor); err != nil {
+			log.Printf("Order poller: failed to save cursor: %v", err)
+		}
+
+		if !ok || len(orders) < p.batchSize {
+			break
+		}
+
+		next := orders[len(orders)-1].CreatedAt
+		if !next.After(from) {
+			log.Printf("Order poller: page of %d orders shares created_at %s, increase POLLER_BATCH_SIZE",
+				len(orders), next.Format(time.RFC3339Nano))
+			break
+		}
+		from = next
+	}
+
+	p.pruneSeen()
+
+	if processed > 0 {
+		log.Printf("Tick processed in %v (orders: %d, cursor: %s)",
+			time.Since(start), processed, p.cursor.Format(time.RFC3339Nano))
 	}
+}
 
+// processPage handles a page in created_at order and advances the cursor up
+// to, but not past, the first event that failed. It reports false when the
+// page contained a failure so that paging stops and the event is retried on
+// the next tick. An event that failed maxRetries times is dropped and no
+// longer holds the cursor back.
+func (p *OrderPoller) processPage(ctx context.Context, orders []model.OrderEvent) (int, bool) {
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].CreatedAt.Before(orders[j].CreatedAt)
+	})
+
+	processed := 0
+	failed := false
 	for _, o := range orders {
-		if o.Status == "created" {
-			if err := p.deliveryUC.AssignForEvent(ctx, o.OrderID); err != nil {
-				log.Printf("Failed to assign courier to order %s: %v", o.OrderID, err)
+		key := o.OrderID + ":" + o.Status
+		if _, dup := p.seen[key]; dup {
+			if !failed && o.CreatedAt.After(p.cursor) {
+				p.cursor = o.CreatedAt
+			}
+			continue
+		}
+
+		if err := p.handle(ctx, o); err != nil {
+			p.failures[key]++
+			if p.failures[key] < p.maxRetries {
+				log.Printf("Failed to process order %s (%s): %v", o.OrderID, o.Status, err)
+				failed = true
+				continue
 			}
+			log.Printf("Dropping order %s (%s) after %d failed attempts: %v", o.OrderID, o.Status, p.failures[key], err)
+			middleware.OrderPollerEventsDroppedTotal.Inc()
+		} else {
+			processed++
+		}
+
+		delete(p.failures, key)
+		p.seen[key] = o.CreatedAt
+		if !failed && o.CreatedAt.After(p.cursor) {
+			p.cursor = o.CreatedAt
 		}
 	}
+	return processed, !failed
+}
+
+// handle runs the event through the processor shared with Kafka and
+// webhooks. Events that are invalid, outdated or have no handler are skipped
+// rather than retried.
+func (p *OrderPoller) handle(ctx context.Context, o model.OrderEvent) error {
+	err := p.processor.Process(ctx, o)
+	switch {
+	case errors.Is(err, ErrInvalidEvent), errors.Is(err, ErrNoEventHandler):
+		log.Printf("Skipping order %s (%s): %v", o.OrderID, o.Status, err)
+		return nil
+	case errors.Is(err, ErrStatusMismatch):
+		// already logged by the processor
+		return nil
+	}
+	return err
+}
 
-	p.lastFetch = tickTime
-	log.Printf("Tick processed in %v (orders: %d)", time.Since(start), len(orders))
+func (p *OrderPoller) pruneSeen() {
+	threshold := p.cursor.Add(-p.overlap)
+	for key, createdAt := range p.seen {
+		if createdAt.Before(threshold) {
+			delete(p.seen, key)
+		}
+	}
 }
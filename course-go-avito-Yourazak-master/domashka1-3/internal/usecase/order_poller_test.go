@@ -2,9 +2,13 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -13,39 +17,83 @@ type MockOrderGateway struct {
 	mock.Mock
 }
 
-func (m *MockOrderGateway) GetOrdersByCursor(ctx context.Context, cursor time.Time) ([]OrderEvent, error) {
+func (m *MockOrderGateway) GetOrdersByCursor(ctx context.Context, cursor time.Time) ([]model.OrderEvent, error) {
 	args := m.Called(ctx, cursor)
-	return args.Get(0).([]OrderEvent), args.Error(1)
+	return args.Get(0).([]model.OrderEvent), args.Error(1)
 }
 
-func (m *MockOrderGateway) GetOrderStatus(ctx context.Context, orderID string) (*OrderEvent, error) {
-	args := m.Called(ctx, orderID)
-	return args.Get(0).(*OrderEvent), args.Error(1)
+func (m *MockOrderGateway) GetOrdersPage(ctx context.Context, cursor time.Time, limit int) ([]model.OrderEvent, error) {
+	args := m.Called(ctx, cursor, limit)
+	return args.Get(0).([]model.OrderEvent), args.Error(1)
 }
 
-type MockPollerDeliveryUsecase struct {
-	mock.Mock
+func (m *MockOrderGateway) GetOrderStatus(ctx context.Context, orderID string) (*model.OrderEvent, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(*model.OrderEvent), args.Error(1)
 }
 
-func (m *MockPollerDeliveryUsecase) Assign(ctx context.Context, orderID string) (Delivery, Courier, error) {
+func (m *MockOrderGateway) GetOrder(ctx context.Context, orderID string) (*model.ExternalOrder, error) {
 	args := m.Called(ctx, orderID)
-	return args.Get(0).(Delivery), args.Get(1).(Courier), args.Error(2)
+	return args.Get(0).(*model.ExternalOrder), args.Error(1)
 }
 
-func (m *MockPollerDeliveryUsecase) Unassign(ctx context.Context, orderID string) error {
-	args := m.Called(ctx, orderID)
+type MockEventHandler struct {
+	mock.Mock
+}
+
+func (m *MockEventHandler) Handle(ctx context.Context, event model.OrderEvent) error {
+	args := m.Called(ctx, event.OrderID)
 	return args.Error(0)
 }
 
-func (m *MockPollerDeliveryUsecase) AutoRelease(ctx context.Context, interval time.Duration) {
-	m.Called(ctx, interval)
+type MockEventDispatcher struct {
+	handlers map[string]EventHandler
+}
+
+func (d *MockEventDispatcher) GetHandler(status string) EventHandler {
+	if h, ok := d.handlers[status]; ok {
+		return h
+	}
+	return nil
+}
+
+type MockLocker struct {
+	mock.Mock
+}
+
+func (m *MockLocker) TryAcquire(ctx context.Context) (bool, error) {
+	args := m.Called(ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLocker) Release(ctx context.Context) {
+	m.Called(ctx)
+}
+
+type MockCursorRepository struct {
+	mock.Mock
+}
+
+func (m *MockCursorRepository) Get(ctx context.Context, name string) (time.Time, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockCursorRepository) Save(ctx context.Context, name string, cursor time.Time) error {
+	args := m.Called(ctx, name, cursor)
+	return args.Error(0)
 }
 
 func TestOrderPoller_Start(t *testing.T) {
 	mockGateway := new(MockOrderGateway)
-	mockDeliveryUC := new(MockPollerDeliveryUsecase)
+	mockCursors := new(MockCursorRepository)
+	mockLocker := new(MockLocker)
 
-	poller := NewOrderPoller(mockGateway, mockDeliveryUC)
+	mockLocker.On("TryAcquire", mock.Anything).Return(false, nil)
+	mockLocker.On("Release", mock.Anything).Return()
+
+	poller := NewOrderPoller(mockGateway, NewOrderEventProcessor(&MockEventDispatcher{}, nil), mockCursors, mockLocker,
+		OrderPollerConfig{Interval: 20 * time.Millisecond})
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -54,7 +102,201 @@ func TestOrderPoller_Start(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 
 	cancel()
-	time.Sleep(100 * time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	mockLocker.AssertCalled(t, "TryAcquire", mock.Anything)
+	mockLocker.AssertCalled(t, "Release", mock.Anything)
+	mockGateway.AssertNotCalled(t, "GetOrdersPage", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrderPoller_AcquireLeadership_LoadsCursor(t *testing.T) {
+	mockCursors := new(MockCursorRepository)
+	mockLocker := new(MockLocker)
+	stored := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	mockLocker.On("TryAcquire", mock.Anything).Return(true, nil)
+	mockCursors.On("Get", mock.Anything, orderPollerCursorName).Return(stored, nil).Once()
+
+	poller := NewOrderPoller(new(MockOrderGateway), NewOrderEventProcessor(&MockEventDispatcher{}, nil), mockCursors, mockLocker, OrderPollerConfig{})
+
+	assert.True(t, poller.acquireLeadership(context.Background()))
+	assert.True(t, poller.acquireLeadership(context.Background()))
+	assert.Equal(t, stored, poller.cursor)
+	mockCursors.AssertNumberOfCalls(t, "Get", 1)
+}
+
+func TestOrderPoller_LoadCursor_ResumesFromStored(t *testing.T) {
+	mockCursors := new(MockCursorRepository)
+	stored := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mockCursors.On("Get", mock.Anything, orderPollerCursorName).Return(stored, nil)
+
+	poller := NewOrderPoller(new(MockOrderGateway), NewOrderEventProcessor(&MockEventDispatcher{}, nil), mockCursors, new(MockLocker), OrderPollerConfig{})
+
+	err := poller.loadCursor(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, stored, poller.cursor)
+}
+
+func TestOrderPoller_ProcessTick_PagesUntilExhausted(t *testing.T) {
+	mockGateway := new(MockOrderGateway)
+	mockHandler := new(MockEventHandler)
+	mockCursors := new(MockCursorRepository)
+	dispatcher := &MockEventDispatcher{handlers: map[string]EventHandler{"created": mockHandler}}
+
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	page1 := []model.OrderEvent{
+		{OrderID: "o1", Status: "created", CreatedAt: base.Add(1 * time.Second)},
+		{OrderID: "o2", Status: "created", CreatedAt: base.Add(2 * time.Second)},
+	}
+	page2 := []model.OrderEvent{
+		{OrderID: "o2", Status: "created", CreatedAt: base.Add(2 * time.Second)},
+		{OrderID: "o3", Status: "created", CreatedAt: base.Add(3 * time.Second)},
+	}
+	page3 := []model.OrderEvent{
+		{OrderID: "o3", Status: "created", CreatedAt: base.Add(3 * time.Second)},
+	}
+
+	mockGateway.On("GetOrdersPage", mock.Anything, base.Add(-time.Second), 2).Return(page1, nil)
+	mockGateway.On("GetOrdersPage", mock.Anything, base.Add(2*time.Second), 2).Return(page2, nil)
+	mockGateway.On("GetOrdersPage", mock.Anything, base.Add(3*time.Second), 2).Return(page3, nil)
+	mockHandler.On("Handle", mock.Anything, mock.Anything).Return(nil)
+	mockCursors.On("Save", mock.Anything, orderPollerCursorName, mock.Anything).Return(nil)
+
+	poller := NewOrderPoller(mockGateway, NewOrderEventProcessor(dispatcher, nil), mockCursors, new(MockLocker), OrderPollerConfig{Overlap: time.Second, BatchSize: 2})
+	poller.cursor = base
+
+	poller.processTick(context.Background())
+
+	mockHandler.AssertNumberOfCalls(t, "Handle", 3)
+	assert.Equal(t, base.Add(3*time.Second), poller.cursor)
+	mockCursors.AssertCalled(t, "Save", mock.Anything, orderPollerCursorName, base.Add(3*time.Second))
+}
+
+func TestOrderPoller_ProcessTick_DoesNotAdvancePastFailure(t *testing.T) {
+	mockGateway := new(MockOrderGateway)
+	createdHandler := new(MockEventHandler)
+	cancelledHandler := new(MockEventHandler)
+	mockCursors := new(MockCursorRepository)
+	dispatcher := &MockEventDispatcher{handlers: map[string]EventHandler{
+		"created":   createdHandler,
+		"cancelled": cancelledHandler,
+	}}
+
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	orders := []model.OrderEvent{
+		{OrderID: "o1", Status: "cancelled", CreatedAt: base.Add(1 * time.Second)},
+		{OrderID: "o2", Status: "created", CreatedAt: base.Add(2 * time.Second)},
+		{OrderID: "o3", Status: "created", CreatedAt: base.Add(3 * time.Second)},
+	}
+
+	mockGateway.On("GetOrdersPage", mock.Anything, base, 10).Return(orders, nil)
+	cancelledHandler.On("Handle", mock.Anything, "o1").Return(nil)
+	createdHandler.On("Handle", mock.Anything, "o2").Return(errors.New("db down"))
+	createdHandler.On("Handle", mock.Anything, "o3").Return(nil)
+	mockCursors.On("Save", mock.Anything, orderPollerCursorName, mock.Anything).Return(nil)
+
+	poller := NewOrderPoller(mockGateway, NewOrderEventProcessor(dispatcher, nil), mockCursors, new(MockLocker), OrderPollerConfig{BatchSize: 10})
+	poller.cursor = base
+
+	poller.processTick(context.Background())
+
+	assert.Equal(t, base.Add(1*time.Second), poller.cursor)
+	assert.Contains(t, poller.seen, "o1:cancelled")
+	assert.Contains(t, poller.seen, "o3:created")
+	assert.NotContains(t, poller.seen, "o2:created")
+}
+
+func TestOrderPoller_LoadCursor_DefaultsWhenMissing(t *testing.T) {
+	mockCursors := new(MockCursorRepository)
+	mockCursors.On("Get", mock.Anything, orderPollerCursorName).Return(time.Time{}, repository.ErrNotFound)
+
+	poller := NewOrderPoller(new(MockOrderGateway), NewOrderEventProcessor(&MockEventDispatcher{}, nil), mockCursors, new(MockLocker),
+		OrderPollerConfig{Interval: time.Minute})
+
+	err := poller.loadCursor(context.Background())
+
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(-time.Minute), poller.cursor, 5*time.Second)
+}
+
+func TestOrderPoller_ProcessTick_DropsAfterMaxRetries(t *testing.T) {
+	mockGateway := new(MockOrderGateway)
+	mockHandler := new(MockEventHandler)
+	mockCursors := new(MockCursorRepository)
+	dispatcher := &MockEventDispatcher{handlers: map[string]EventHandler{"created": mockHandler}}
+
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	orders := []model.OrderEvent{
+		{OrderID: "o1", Status: "created", CreatedAt: base.Add(1 * time.Second)},
+		{OrderID: "o2", Status: "created", CreatedAt: base.Add(2 * time.Second)},
+	}
+
+	mockGateway.On("GetOrdersPage", mock.Anything, base, 10).Return(orders, nil)
+	mockHandler.On("Handle", mock.Anything, "o1").Return(errors.New("bad payload"))
+	mockHandler.On("Handle", mock.Anything, "o2").Return(nil)
+	mockCursors.On("Save", mock.Anything, orderPollerCursorName, mock.Anything).Return(nil)
+
+	poller := NewOrderPoller(mockGateway, NewOrderEventProcessor(dispatcher, nil), mockCursors, new(MockLocker), OrderPollerConfig{BatchSize: 10, MaxRetries: 2})
+	poller.cursor = base
+
+	poller.processTick(context.Background())
+	assert.Equal(t, base, poller.cursor)
+	assert.Equal(t, 1, poller.failures["o1:created"])
+
+	poller.processTick(context.Background())
+	assert.Equal(t, base.Add(2*time.Second), poller.cursor)
+	assert.NotContains(t, poller.failures, "o1:created")
+	mockHandler.AssertNumberOfCalls(t, "Handle", 3)
+}
+
+func TestOrderPoller_LoadCursor_ReturnsStorageError(t *testing.T) {
+	mockCursors := new(MockCursorRepository)
+	mockCursors.On("Get", mock.Anything, orderPollerCursorName).Return(time.Time{}, errors.New("db down"))
+	mockLocker := new(MockLocker)
+	mockLocker.On("TryAcquire", mock.Anything).Return(true, nil)
+	mockLocker.On("Release", mock.Anything).Return()
+
+	poller := NewOrderPoller(new(MockOrderGateway), NewOrderEventProcessor(&MockEventDispatcher{}, nil), mockCursors, mockLocker, OrderPollerConfig{})
+
+	assert.Error(t, poller.loadCursor(context.Background()))
+	assert.True(t, poller.cursor.IsZero())
+
+	assert.False(t, poller.acquireLeadership(context.Background()))
+	mockLocker.AssertCalled(t, "Release", mock.Anything)
+}
+
+func TestOrderPoller_ProcessTick_UsesEventProcessor(t *testing.T) {
+	mockGateway := new(MockOrderGateway)
+	mockHandler := new(MockEventHandler)
+	mockCursors := new(MockCursorRepository)
+	dispatcher := &MockEventDispatcher{handlers: map[string]EventHandler{"created": mockHandler}}
+
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	orders := []model.OrderEvent{
+		{OrderID: "o1", Status: "created", CreatedAt: base.Add(1 * time.Second)},
+		{OrderID: "o2", Status: "shipped", CreatedAt: base.Add(2 * time.Second)},
+		{OrderID: "o3", Status: "completed", CreatedAt: base.Add(3 * time.Second)},
+		{OrderID: "o4", Status: "created", CreatedAt: base.Add(4 * time.Second)},
+	}
+
+	mockGateway.On("GetOrdersPage", mock.Anything, base, 10).Return(orders, nil)
+	mockGateway.On("GetOrderStatus", mock.Anything, "o1").Return(&model.OrderEvent{OrderID: "o1", Status: "cancelled"}, nil)
+	mockGateway.On("GetOrderStatus", mock.Anything, "o3").Return(&model.OrderEvent{OrderID: "o3", Status: "completed"}, nil)
+	mockGateway.On("GetOrderStatus", mock.Anything, "o4").Return(&model.OrderEvent{OrderID: "o4", Status: "created"}, nil)
+	mockHandler.On("Handle", mock.MatchedBy(func(ctx context.Context) bool {
+		return model.StatusChangeFrom(ctx).Actor == model.ActorEvent
+	}), "o4").Return(nil)
+	mockCursors.On("Save", mock.Anything, orderPollerCursorName, mock.Anything).Return(nil)
+
+	poller := NewOrderPoller(mockGateway, NewOrderEventProcessor(dispatcher, mockGateway), mockCursors, new(MockLocker), OrderPollerConfig{BatchSize: 10})
+	poller.cursor = base
+
+	poller.processTick(context.Background())
 
-	assert.True(t, true)
+	// The outdated o1, the invalid o2 and the unhandled o3 are skipped
+	// without holding the cursor back.
+	assert.Equal(t, base.Add(4*time.Second), poller.cursor)
+	mockHandler.AssertNumberOfCalls(t, "Handle", 1)
+	mockHandler.AssertExpectations(t)
 }
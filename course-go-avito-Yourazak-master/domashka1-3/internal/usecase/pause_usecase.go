@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
+)
+
+type PauseUsecase interface {
+	Pause(ctx context.Context, courierID int, reason string, resumeAt *time.Time) (model.CourierPause, error)
+	Resume(ctx context.Context, courierID int) (model.Courier, error)
+}
+
+type pauseUsecase struct {
+	repo        repository.PauseRepository
+	courierRepo repository.CourierRepository
+}
+
+func NewPauseUsecase(r repository.PauseRepository, cr repository.CourierRepository) PauseUsecase {
+	return &pauseUsecase{
+		repo:        r,
+		courierRepo: cr,
+	}
+}
+
+// Pause takes the courier off the line with one of the known reasons. An
+// optional resumeAt must be in the future; the pause scheduler resumes the
+// courier at that time.
+func (u *pauseUsecase) Pause(ctx context.Context, courierID int, reason string, resumeAt *time.Time) (model.CourierPause, error) {
+	if courierID <= 0 || !model.ValidPauseReason(reason) {
+		return model.CourierPause{}, ErrBadInput
+	}
+	if resumeAt != nil {
+		if !resumeAt.After(time.Now()) {
+			return model.CourierPause{}, ErrBadInput
+		}
+		utc := resumeAt.UTC()
+		resumeAt = &utc
+	}
+
+	p := model.CourierPause{CourierID: courierID, Reason: reason, ResumeAt: resumeAt}
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: "paused: " + reason})
+	if err := u.repo.Pause(ctx, &p); err != nil {
+		return model.CourierPause{}, err
+	}
+	return p, nil
+}
+
+func (u *pauseUsecase) Resume(ctx context.Context, courierID int) (model.Courier, error) {
+	if courierID <= 0 {
+		return model.Courier{}, ErrBadInput
+	}
+
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Reason: "resumed"})
+	if err := u.repo.Resume(ctx, courierID); err != nil {
+		return model.Courier{}, err
+	}
+	return u.courierRepo.GetByID(ctx, courierID)
+}
+
+// PauseScheduler resumes couriers whose pause has a resume time that has
+// passed.
+type PauseScheduler struct {
+	repo     repository.PauseRepository
+	interval time.Duration
+}
+
+func NewPauseScheduler(r repository.PauseRepository, interval time.Duration) *PauseScheduler {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &PauseScheduler{
+		repo:     r,
+		interval: interval,
+	}
+}
+
+func (s *PauseScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	log.Printf("Pause scheduler started (ticker: %v)", s.interval)
+
+	s.runOnce(ctx, time.Now().UTC())
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Pause scheduler stopped")
+			return
+		case t := <-ticker.C:
+			s.runOnce(ctx, t.UTC())
+		}
+	}
+}
+
+func (s *PauseScheduler) runOnce(ctx context.Context, now time.Time) {
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Actor: model.ActorPauseScheduler, Reason: "auto resume"})
+
+	resumed, err := s.repo.ResumeDue(ctx, now)
+	if err != nil {
+		log.Printf("Pause scheduler: failed to resume couriers: %v", err)
+	} else if len(resumed) > 0 {
+		log.Printf("Pause scheduler: couriers %v are now available", resumed)
+	}
+}
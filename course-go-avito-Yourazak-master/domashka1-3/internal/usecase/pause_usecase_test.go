@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPauseRepository struct {
+	mock.Mock
+}
+
+func (m *MockPauseRepository) Get(ctx context.Context, courierID int) (model.CourierPause, error) {
+	args := m.Called(ctx, courierID)
+	return args.Get(0).(model.CourierPause), args.Error(1)
+}
+
+func (m *MockPauseRepository) Pause(ctx context.Context, p *model.CourierPause) error {
+	args := m.Called(ctx, p)
+	return args.Error(0)
+}
+
+func (m *MockPauseRepository) Resume(ctx context.Context, courierID int) error {
+	args := m.Called(ctx, courierID)
+	return args.Error(0)
+}
+
+func (m *MockPauseRepository) ResumeDue(ctx context.Context, now time.Time) ([]int, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func TestPauseUsecase_Pause_Success(t *testing.T) {
+	mockRepo := new(MockPauseRepository)
+	service := NewPauseUsecase(mockRepo, new(MockCourierRepository))
+	resumeAt := time.Now().Add(30 * time.Minute)
+
+	mockRepo.On("Pause", mock.MatchedBy(func(ctx context.Context) bool {
+		return model.StatusChangeFrom(ctx).Reason == "paused: break"
+	}), mock.MatchedBy(func(p *model.CourierPause) bool {
+		return p.CourierID == 3 && p.Reason == model.PauseBreak && p.ResumeAt.Equal(resumeAt)
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.CourierPause).State = model.PausePending
+	}).Return(nil)
+
+	pause, err := service.Pause(context.Background(), 3, model.PauseBreak, &resumeAt)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.PausePending, pause.State)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPauseUsecase_Pause_InvalidInput(t *testing.T) {
+	mockRepo := new(MockPauseRepository)
+	service := NewPauseUsecase(mockRepo, new(MockCourierRepository))
+	past := time.Now().Add(-time.Minute)
+
+	_, err := service.Pause(context.Background(), 3, "lunch", nil)
+	assert.Equal(t, ErrBadInput, err)
+
+	_, err = service.Pause(context.Background(), 3, model.PauseEndOfDay, &past)
+	assert.Equal(t, ErrBadInput, err)
+
+	mockRepo.AssertNotCalled(t, "Pause", mock.Anything, mock.Anything)
+}
+
+func TestPauseUsecase_Resume_NotPaused(t *testing.T) {
+	mockRepo := new(MockPauseRepository)
+	mockCourierRepo := new(MockCourierRepository)
+	service := NewPauseUsecase(mockRepo, mockCourierRepo)
+
+	mockRepo.On("Resume", mock.Anything, 3).Return(ErrConflict)
+
+	_, err := service.Resume(context.Background(), 3)
+
+	assert.Equal(t, ErrConflict, err)
+	mockCourierRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestPauseUsecase_Resume_Success(t *testing.T) {
+	mockRepo := new(MockPauseRepository)
+	mockCourierRepo := new(MockCourierRepository)
+	service := NewPauseUsecase(mockRepo, mockCourierRepo)
+
+	mockRepo.On("Resume", mock.Anything, 3).Return(nil)
+	mockCourierRepo.On("GetByID", mock.Anything, 3).Return(model.Courier{ID: 3, Status: "available"}, nil)
+
+	courier, err := service.Resume(context.Background(), 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "available", courier.Status)
+}
+
+func TestPauseScheduler_RunOnce(t *testing.T) {
+	mockRepo := new(MockPauseRepository)
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	mockRepo.On("ResumeDue", mock.MatchedBy(func(ctx context.Context) bool {
+		return model.StatusChangeFrom(ctx).Actor == model.ActorPauseScheduler
+	}), now).Return([]int{4}, nil)
+
+	NewPauseScheduler(mockRepo, time.Minute).runOnce(context.Background(), now)
+
+	mockRepo.AssertExpectations(t)
+}
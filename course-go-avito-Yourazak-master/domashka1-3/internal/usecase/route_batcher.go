@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
+)
+
+// RouteBatcherLockKey is the pg advisory lock key that elects the single
+// replica allowed to batch collected orders.
+const RouteBatcherLockKey int64 = 7_341_004
+
+type RouteBatcherConfig struct {
+	// Window is how long orders are collected before they are grouped.
+	Window time.Duration
+	// MaxOrders caps the number of orders on one route.
+	MaxOrders int
+	// RadiusKm is how far apart the pickups of one route may be.
+	RadiusKm float64
+	// Capacity is the most a single courier can carry; zero means unlimited.
+	Capacity  model.TransportLimits
+	BatchSize int
+}
+
+type batchAssigner interface {
+	AssignBatch(ctx context.Context, orders []model.ExternalOrder) (model.Route, error)
+}
+
+// RouteBatcher groups the orders collected since its last run into routes
+// and has each group assigned to a single courier.
+type RouteBatcher struct {
+	repo     repository.RouteRepository
+	assigner batchAssigner
+	locker   repository.Locker
+	cfg      RouteBatcherConfig
+}
+
+func NewRouteBatcher(r repository.RouteRepository, assigner batchAssigner, locker repository.Locker, cfg RouteBatcherConfig) *RouteBatcher {
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.MaxOrders <= 0 {
+		cfg.MaxOrders = 4
+	}
+	if cfg.RadiusKm <= 0 {
+		cfg.RadiusKm = 2
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	return &RouteBatcher{
+		repo:     r,
+		assigner: assigner,
+		locker:   locker,
+		cfg:      cfg,
+	}
+}
+
+// MaxCapacity returns the largest load any transport type can carry, used
+// as the capacity limit of a route before its courier is known.
+func MaxCapacity(limits map[string]model.TransportLimits) model.TransportLimits {
+	var capacity model.TransportLimits
+	weightCapped, volumeCapped := len(limits) > 0, len(limits) > 0
+	for _, l := range limits {
+		weightCapped = weightCapped && l.MaxWeightKg > 0
+		volumeCapped = volumeCapped && l.MaxVolumeL > 0
+		capacity.MaxWeightKg = max(capacity.MaxWeightKg, l.MaxWeightKg)
+		capacity.MaxVolumeL = max(capacity.MaxVolumeL, l.MaxVolumeL)
+	}
+	if !weightCapped {
+		capacity.MaxWeightKg = 0
+	}
+	if !volumeCapped {
+		capacity.MaxVolumeL = 0
+	}
+	return capacity
+}
+
+func (b *RouteBatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(b.cfg.Window)
+	defer ticker.Stop()
+	defer b.locker.Release(context.Background())
+
+	log.Printf("Route batcher started (window: %v, max orders: %d, radius: %.1f km)", b.cfg.Window, b.cfg.MaxOrders, b.cfg.RadiusKm)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Route batcher stopped")
+			return
+		case <-ticker.C:
+			acquired, err := b.locker.TryAcquire(ctx)
+			if err != nil {
+				log.Printf("Route batcher: failed to acquire lock: %v", err)
+				continue
+			}
+			if acquired {
+				b.runOnce(ctx)
+			}
+		}
+	}
+}
+
+func (b *RouteBatcher) runOnce(ctx context.Context) {
+	orders, err := b.repo.TakeCollected(ctx, b.cfg.BatchSize)
+	if err != nil {
+		log.Printf("Route batcher: failed to take collected orders: %v", err)
+		return
+	}
+	if len(orders) == 0 {
+		return
+	}
+
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Actor: model.ActorRouteBatcher})
+	for _, group := range groupOrders(orders, b.cfg) {
+		route, err := b.assigner.AssignBatch(ctx, group)
+		if err != nil {
+			log.Printf("Route batcher: failed to assign %d orders starting with %s: %v", len(group), group[0].ID, err)
+			continue
+		}
+		if route.ID > 0 {
+			log.Printf("Route batcher: route %d with %d orders assigned to courier %d", route.ID, len(group), route.CourierID)
+		}
+	}
+}
+
+// groupOrders puts each order into the first group it is compatible with,
+// keeping arrival order within groups.
+func groupOrders(orders []model.ExternalOrder, cfg RouteBatcherConfig) [][]model.ExternalOrder {
+	var (
+		groups [][]model.ExternalOrder
+		loads  []model.ExternalOrder
+	)
+	for _, o := range orders {
+		placed := false
+		for i, g := range groups {
+			if len(g) >= cfg.MaxOrders || !batchable(g[0], o, cfg.RadiusKm) || !fitsCapacity(loads[i], o, cfg.Capacity) {
+				continue
+			}
+			groups[i] = append(g, o)
+			loads[i].Weight += o.Weight
+			loads[i].Volume += o.Volume
+			placed = true
+			break
+		}
+		if !placed {
+			groups = append(groups, []model.ExternalOrder{o})
+			loads = append(loads, model.ExternalOrder{Weight: o.Weight, Volume: o.Volume})
+		}
+	}
+	return groups
+}
+
+// batchable reports whether two orders can share a route: same region and
+// priority class, pickups close to each other and, for booked slots,
+// overlapping windows.
+func batchable(a, b model.ExternalOrder, radiusKm float64) bool {
+	if a.Region != b.Region || model.PriorityRank(a.Priority) != model.PriorityRank(b.Priority) {
+		return false
+	}
+	if a.Pickup == nil || b.Pickup == nil || a.Pickup.DistanceKm(*b.Pickup) > radiusKm {
+		return false
+	}
+	if a.HasWindow() != b.HasWindow() {
+		return false
+	}
+	if a.HasWindow() {
+		return a.WindowStart.Before(*b.WindowEnd) && b.WindowStart.Before(*a.WindowEnd)
+	}
+	return true
+}
+
+func fitsCapacity(load, o model.ExternalOrder, capacity model.TransportLimits) bool {
+	if capacity.MaxWeightKg > 0 && load.Weight+o.Weight > capacity.MaxWeightKg {
+		return false
+	}
+	if capacity.MaxVolumeL > 0 && load.Volume+o.Volume > capacity.MaxVolumeL {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRouteRepository struct {
+	mock.Mock
+}
+
+func (m *MockRouteRepository) CreateTx(ctx context.Context, tx pgx.Tx, r *model.Route) error {
+	args := m.Called(ctx, tx, r)
+	return args.Error(0)
+}
+
+func (m *MockRouteRepository) GetByID(ctx context.Context, id int) (model.Route, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(model.Route), args.Error(1)
+}
+
+func (m *MockRouteRepository) UpdateStops(ctx context.Context, id int, fn func(r *model.Route) error) (model.Route, error) {
+	args := m.Called(ctx, id)
+	if err := args.Error(1); err != nil {
+		return model.Route{}, err
+	}
+	route := args.Get(0).(model.Route)
+	if err := fn(&route); err != nil {
+		return model.Route{}, err
+	}
+	return route, nil
+}
+
+func (m *MockRouteRepository) CompleteIfDoneTx(ctx context.Context, tx pgx.Tx, id int) (bool, error) {
+	args := m.Called(ctx, tx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRouteRepository) Collect(ctx context.Context, o model.ExternalOrder) error {
+	args := m.Called(ctx, o)
+	return args.Error(0)
+}
+
+func (m *MockRouteRepository) TakeCollected(ctx context.Context, limit int) ([]model.ExternalOrder, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]model.ExternalOrder), args.Error(1)
+}
+
+func (m *MockRouteRepository) Uncollect(ctx context.Context, orderID string) (bool, error) {
+	args := m.Called(ctx, orderID)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockBatchAssigner struct {
+	mock.Mock
+}
+
+func (m *MockBatchAssigner) AssignBatch(ctx context.Context, orders []model.ExternalOrder) (model.Route, error) {
+	args := m.Called(ctx, orders)
+	return args.Get(0).(model.Route), args.Error(1)
+}
+
+func TestGroupOrders(t *testing.T) {
+	near := &model.GeoPoint{Lat: 55.7558, Lon: 37.6173}
+	nearby := &model.GeoPoint{Lat: 55.7600, Lon: 37.6200}
+	far := &model.GeoPoint{Lat: 55.9000, Lon: 37.9000}
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	orders := []model.ExternalOrder{
+		{ID: "a", Region: 1, Pickup: near, Weight: 10},
+		{ID: "b", Region: 1, Pickup: nearby, Weight: 10},
+		{ID: "c", Region: 1, Pickup: far},
+		{ID: "d", Region: 2, Pickup: near},
+		{ID: "e", Region: 1, Pickup: near, Priority: model.PriorityExpress},
+		{ID: "f", Region: 1, Pickup: near, WindowStart: &start, WindowEnd: &end},
+		{ID: "g", Region: 1, Pickup: nearby, Weight: 40},
+		{ID: "h", Region: 1, Pickup: near},
+	}
+	cfg := RouteBatcherConfig{MaxOrders: 3, RadiusKm: 2, Capacity: model.TransportLimits{MaxWeightKg: 50}}
+
+	var ids [][]string
+	for _, g := range groupOrders(orders, cfg) {
+		var group []string
+		for _, o := range g {
+			group = append(group, o.ID)
+		}
+		ids = append(ids, group)
+	}
+
+	assert.Equal(t, [][]string{{"a", "b", "h"}, {"c"}, {"d"}, {"e"}, {"f"}, {"g"}}, ids)
+}
+
+func TestMaxCapacity(t *testing.T) {
+	assert.Equal(t, model.TransportLimits{MaxWeightKg: 50, MaxVolumeL: 500}, MaxCapacity(DefaultTransportLimits))
+	assert.Equal(t, model.TransportLimits{}, MaxCapacity(nil))
+	assert.Equal(t, model.TransportLimits{MaxVolumeL: 20}, MaxCapacity(map[string]model.TransportLimits{
+		"on_foot": {MaxWeightKg: 5, MaxVolumeL: 20},
+		"truck":   {MaxVolumeL: 10},
+	}))
+}
+
+func TestRouteBatcher_RunOnce(t *testing.T) {
+	mockRepo := new(MockRouteRepository)
+	mockAssigner := new(MockBatchAssigner)
+	pickup := &model.GeoPoint{Lat: 55.7558, Lon: 37.6173}
+	orders := []model.ExternalOrder{
+		{ID: "o1", Region: 1, Pickup: pickup},
+		{ID: "o2", Region: 1, Pickup: pickup},
+		{ID: "o3", Region: 2, Pickup: pickup},
+	}
+
+	fromBatcher := mock.MatchedBy(func(ctx context.Context) bool {
+		return model.StatusChangeFrom(ctx).Actor == model.ActorRouteBatcher
+	})
+	mockRepo.On("TakeCollected", mock.Anything, 500).Return(orders, nil)
+	mockAssigner.On("AssignBatch", fromBatcher, orders[:2]).Return(model.Route{ID: 7, CourierID: 3}, nil)
+	mockAssigner.On("AssignBatch", fromBatcher, orders[2:]).Return(model.Route{}, nil)
+
+	b := NewRouteBatcher(mockRepo, mockAssigner, new(MockLocker), RouteBatcherConfig{})
+	b.runOnce(context.Background())
+
+	mockRepo.AssertExpectations(t)
+	mockAssigner.AssertExpectations(t)
+}
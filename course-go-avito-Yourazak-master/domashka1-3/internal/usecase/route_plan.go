@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"time"
+
+	"avito-courier/internal/model"
+)
+
+// RoutePlanner orders route stops and estimates when the courier reaches
+// each of them. The order is built nearest-neighbour first and then improved
+// with 2-opt; a dropoff never comes before the pickup of the same order.
+type RoutePlanner struct {
+	estimator   TravelTimeEstimator
+	serviceTime time.Duration
+}
+
+// NewRoutePlanner uses the straight-line estimator when e is nil.
+// serviceTime is spent at every stop before moving on.
+func NewRoutePlanner(e TravelTimeEstimator, serviceTime time.Duration) *RoutePlanner {
+	if e == nil {
+		e = NewStraightLineEstimator(nil)
+	}
+	if serviceTime < 0 {
+		serviceTime = 0
+	}
+	return &RoutePlanner{estimator: e, serviceTime: serviceTime}
+}
+
+// Plan returns the stops in visiting order with their ETAs for a courier
+// leaving start at startAt. Without a start point the route begins at its
+// first stop. Stops without coordinates, and the dropoffs of orders whose
+// pickup has none, go last in the given order and get no ETA.
+func (p *RoutePlanner) Plan(start *model.GeoPoint, startAt time.Time, transport string, stops []model.RouteStop) []model.RouteStop {
+	unlocatedPickups := make(map[string]bool)
+	for _, s := range stops {
+		if s.Kind == model.StopPickup && s.Location == nil {
+			unlocatedPickups[s.OrderID] = true
+		}
+	}
+	var located, unlocated []model.RouteStop
+	for _, s := range stops {
+		s.ETA = nil
+		if s.Location == nil || (s.Kind == model.StopDropoff && unlocatedPickups[s.OrderID]) {
+			unlocated = append(unlocated, s)
+		} else {
+			located = append(located, s)
+		}
+	}
+
+	ordered := p.twoOpt(start, transport, p.nearestNeighbour(start, transport, located))
+
+	at, from := startAt, start
+	for i := range ordered {
+		if from != nil {
+			at = at.Add(p.estimator.Estimate(*from, *ordered[i].Location, transport))
+		}
+		eta := at
+		ordered[i].ETA = &eta
+		at = at.Add(p.serviceTime)
+		from = ordered[i].Location
+	}
+	return append(ordered, unlocated...)
+}
+
+func (p *RoutePlanner) nearestNeighbour(from *model.GeoPoint, transport string, stops []model.RouteStop) []model.RouteStop {
+	pending := pendingPickups(stops)
+	left := append([]model.RouteStop(nil), stops...)
+	ordered := make([]model.RouteStop, 0, len(stops))
+	for len(left) > 0 {
+		next := -1
+		var best time.Duration
+		for i, s := range left {
+			if s.Kind == model.StopDropoff && pending[s.OrderID] {
+				continue
+			}
+			if from == nil {
+				next = i
+				break
+			}
+			if d := p.estimator.Estimate(*from, *s.Location, transport); next < 0 || d < best {
+				next, best = i, d
+			}
+		}
+		s := left[next]
+		if s.Kind == model.StopPickup {
+			delete(pending, s.OrderID)
+		}
+		ordered = append(ordered, s)
+		from = s.Location
+		left = append(left[:next], left[next+1:]...)
+	}
+	return ordered
+}
+
+// twoOpt reverses segments of the route while that makes it shorter and
+// keeps every pickup ahead of its dropoff.
+func (p *RoutePlanner) twoOpt(start *model.GeoPoint, transport string, stops []model.RouteStop) []model.RouteStop {
+	best := p.duration(start, transport, stops)
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < len(stops)-1; i++ {
+			for j := i + 1; j < len(stops); j++ {
+				candidate := append([]model.RouteStop(nil), stops...)
+				for l, r := i, j; l < r; l, r = l+1, r-1 {
+					candidate[l], candidate[r] = candidate[r], candidate[l]
+				}
+				if !respectsPickups(candidate) {
+					continue
+				}
+				if d := p.duration(start, transport, candidate); d < best {
+					stops, best, improved = candidate, d, true
+				}
+			}
+		}
+	}
+	return stops
+}
+
+func (p *RoutePlanner) duration(start *model.GeoPoint, transport string, stops []model.RouteStop) time.Duration {
+	var total time.Duration
+	from := start
+	for _, s := range stops {
+		if from != nil {
+			total += p.estimator.Estimate(*from, *s.Location, transport)
+		}
+		from = s.Location
+	}
+	return total
+}
+
+func pendingPickups(stops []model.RouteStop) map[string]bool {
+	pending := make(map[string]bool)
+	for _, s := range stops {
+		if s.Kind == model.StopPickup {
+			pending[s.OrderID] = true
+		}
+	}
+	return pending
+}
+
+func respectsPickups(stops []model.RouteStop) bool {
+	pending := pendingPickups(stops)
+	for _, s := range stops {
+		switch {
+		case s.Kind == model.StopPickup:
+			delete(pending, s.OrderID)
+		case pending[s.OrderID]:
+			return false
+		}
+	}
+	return true
+}
+
+// routeStops lists the pickup and dropoff stop of every order.
+func routeStops(orders []model.ExternalOrder) []model.RouteStop {
+	stops := make([]model.RouteStop, 0, 2*len(orders))
+	for _, o := range orders {
+		stops = append(stops, model.RouteStop{OrderID: o.ID, Kind: model.StopPickup, Location: o.Pickup})
+	}
+	for _, o := range orders {
+		stops = append(stops, model.RouteStop{OrderID: o.ID, Kind: model.StopDropoff, Location: o.Dropoff})
+	}
+	return stops
+}
+
+// combineOrders describes a group of orders as one order for courier
+// selection: the loads add up, the trip runs from the first pickup to the
+// last dropoff and the most urgent priority class wins.
+func combineOrders(orders []model.ExternalOrder, stops []model.RouteStop) model.ExternalOrder {
+	combined := model.ExternalOrder{ID: orders[0].ID, Region: orders[0].Region, Priority: orders[0].Priority}
+	for _, o := range orders {
+		combined.Weight += o.Weight
+		combined.Volume += o.Volume
+		if model.PriorityRank(o.Priority) > model.PriorityRank(combined.Priority) {
+			combined.Priority = o.Priority
+		}
+	}
+	for _, s := range stops {
+		if s.Location == nil {
+			continue
+		}
+		if s.Kind == model.StopPickup && combined.Pickup == nil {
+			combined.Pickup = s.Location
+		}
+		if s.Kind == model.StopDropoff {
+			combined.Dropoff = s.Location
+		}
+	}
+	return combined
+}
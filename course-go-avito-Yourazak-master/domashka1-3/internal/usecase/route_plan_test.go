@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lineEstimator takes one minute per degree of longitude, so stops placed
+// on the equator have easy to follow travel times.
+type lineEstimator struct{}
+
+func (lineEstimator) Estimate(from, to model.GeoPoint, transport string) time.Duration {
+	return time.Duration(math.Abs(to.Lon-from.Lon) * float64(time.Minute))
+}
+
+func at(lon float64) *model.GeoPoint {
+	return &model.GeoPoint{Lon: lon}
+}
+
+func stopIDs(stops []model.RouteStop) []string {
+	var ids []string
+	for _, s := range stops {
+		ids = append(ids, s.Kind+":"+s.OrderID)
+	}
+	return ids
+}
+
+func TestRoutePlanner_Plan_ETAs(t *testing.T) {
+	p := NewRoutePlanner(lineEstimator{}, time.Minute)
+	departAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	stops := []model.RouteStop{
+		{OrderID: "c", Kind: model.StopPickup},
+		{OrderID: "a", Kind: model.StopDropoff, Location: at(5)},
+		{OrderID: "c", Kind: model.StopDropoff, Location: at(1)},
+		{OrderID: "a", Kind: model.StopPickup, Location: at(2)},
+	}
+
+	plan := p.Plan(at(0), departAt, "", stops)
+
+	assert.Equal(t, []string{"pickup:a", "dropoff:a", "pickup:c", "dropoff:c"}, stopIDs(plan))
+	assert.Equal(t, departAt.Add(2*time.Minute), *plan[0].ETA)
+	assert.Equal(t, departAt.Add(6*time.Minute), *plan[1].ETA)
+	assert.Nil(t, plan[2].ETA)
+	assert.Nil(t, plan[3].ETA)
+}
+
+func TestRoutePlanner_Plan_PickupBeforeDropoff(t *testing.T) {
+	p := NewRoutePlanner(lineEstimator{}, 0)
+	stops := []model.RouteStop{
+		{OrderID: "a", Kind: model.StopDropoff, Location: at(1)},
+		{OrderID: "a", Kind: model.StopPickup, Location: at(2)},
+		{OrderID: "b", Kind: model.StopPickup, Location: at(3)},
+	}
+
+	plan := p.Plan(at(0), time.Now(), "", stops)
+
+	assert.Equal(t, "pickup:a", stopIDs(plan)[0])
+	assert.True(t, respectsPickups(plan))
+}
+
+func TestRoutePlanner_TwoOpt(t *testing.T) {
+	p := NewRoutePlanner(lineEstimator{}, 0)
+	stops := []model.RouteStop{
+		{OrderID: "a", Kind: model.StopPickup, Location: at(0)},
+		{OrderID: "c", Kind: model.StopPickup, Location: at(2)},
+		{OrderID: "b", Kind: model.StopPickup, Location: at(1)},
+		{OrderID: "d", Kind: model.StopPickup, Location: at(3)},
+	}
+
+	improved := p.twoOpt(nil, "", stops)
+
+	assert.Equal(t, []string{"pickup:a", "pickup:b", "pickup:c", "pickup:d"}, stopIDs(improved))
+	assert.Equal(t, 3*time.Minute, p.duration(nil, "", improved))
+}
+
+func TestRespectsPickups(t *testing.T) {
+	assert.True(t, respectsPickups([]model.RouteStop{
+		{OrderID: "a", Kind: model.StopPickup},
+		{OrderID: "b", Kind: model.StopDropoff},
+		{OrderID: "a", Kind: model.StopDropoff},
+	}))
+	assert.False(t, respectsPickups([]model.RouteStop{
+		{OrderID: "a", Kind: model.StopDropoff},
+		{OrderID: "a", Kind: model.StopPickup},
+	}))
+}
+
+func TestStraightLineEstimator(t *testing.T) {
+	e := NewStraightLineEstimator(nil)
+	from := model.GeoPoint{Lat: 55.75, Lon: 37.60}
+	to := model.GeoPoint{Lat: 55.80, Lon: 37.60}
+	km := from.DistanceKm(to)
+
+	assert.InDelta(t, km/5*60, e.Estimate(from, to, string(OnFoot)).Minutes(), 0.01)
+	assert.InDelta(t, km/25*60, e.Estimate(from, to, string(Car)).Minutes(), 0.01)
+	assert.Equal(t, e.Estimate(from, to, string(OnFoot)), e.Estimate(from, to, "hoverboard"))
+}
+
+func TestCombineOrders(t *testing.T) {
+	orders := []model.ExternalOrder{
+		{ID: "a", Region: 3, Weight: 2, Volume: 5, Pickup: &model.GeoPoint{Lat: 1, Lon: 1}, Dropoff: &model.GeoPoint{Lat: 2, Lon: 2}},
+		{ID: "b", Region: 3, Weight: 3, Volume: 1, Priority: model.PriorityExpress, Pickup: &model.GeoPoint{Lat: 1, Lon: 1.1}, Dropoff: &model.GeoPoint{Lat: 3, Lon: 3}},
+	}
+	stops := NewRoutePlanner(nil, 0).Plan(nil, time.Now(), "", routeStops(orders))
+
+	combined := combineOrders(orders, stops)
+
+	assert.Equal(t, "a", combined.ID)
+	assert.Equal(t, 3, combined.Region)
+	assert.Equal(t, 5.0, combined.Weight)
+	assert.Equal(t, 6.0, combined.Volume)
+	assert.Equal(t, model.PriorityExpress, combined.Priority)
+	assert.Equal(t, stops[0].Location, combined.Pickup)
+	assert.Equal(t, stops[len(stops)-1].Location, combined.Dropoff)
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
+)
+
+const maxPlanStops = 100
+
+type RouteUsecase interface {
+	Get(ctx context.Context, id int) (model.Route, error)
+	Plan(ctx context.Context, req model.RoutePlanRequest) (model.RoutePlan, error)
+	CompleteStop(ctx context.Context, routeID, seq int) (model.Route, error)
+}
+
+type routeUsecase struct {
+	repo         repository.RouteRepository
+	courierRepo  repository.CourierRepository
+	locationRepo repository.LocationRepository
+	planner      *RoutePlanner
+}
+
+func NewRouteUsecase(r repository.RouteRepository, cr repository.CourierRepository, lr repository.LocationRepository, planner *RoutePlanner) RouteUsecase {
+	if planner == nil {
+		planner = NewRoutePlanner(nil, 0)
+	}
+	return &routeUsecase{
+		repo:         r,
+		courierRepo:  cr,
+		locationRepo: lr,
+		planner:      planner,
+	}
+}
+
+func (u *routeUsecase) Get(ctx context.Context, id int) (model.Route, error) {
+	if id <= 0 {
+		return model.Route{}, ErrBadInput
+	}
+	return u.repo.GetByID(ctx, id)
+}
+
+// Plan orders the stops for the courier and estimates the arrival at each.
+// The route starts at req.Start, else at the courier's last reported
+// position, else at the first stop.
+func (u *routeUsecase) Plan(ctx context.Context, req model.RoutePlanRequest) (model.RoutePlan, error) {
+	if !validPlanRequest(req) {
+		return model.RoutePlan{}, ErrBadInput
+	}
+	courier, err := u.courierRepo.GetByID(ctx, req.CourierID)
+	if err != nil {
+		return model.RoutePlan{}, err
+	}
+
+	start := req.Start
+	if start == nil && u.locationRepo != nil {
+		if loc, err := u.locationRepo.GetLatest(ctx, req.CourierID); err == nil {
+			p := loc.Point()
+			start = &p
+		}
+	}
+	departAt := time.Now().UTC()
+	if req.DepartAt != nil {
+		departAt = req.DepartAt.UTC()
+	}
+
+	stops := u.planner.Plan(start, departAt, courier.TransportType, req.Stops)
+	for i := range stops {
+		stops[i].Seq = i + 1
+	}
+	plan := model.RoutePlan{CourierID: courier.ID, TransportType: courier.TransportType, Stops: stops}
+	if last := stops[len(stops)-1].ETA; last != nil {
+		finish := last.Add(u.planner.serviceTime)
+		plan.FinishAt = &finish
+	}
+	return plan, nil
+}
+
+func validPlanRequest(req model.RoutePlanRequest) bool {
+	if req.CourierID <= 0 || len(req.Stops) == 0 || len(req.Stops) > maxPlanStops {
+		return false
+	}
+	if req.Start != nil && !req.Start.Valid() {
+		return false
+	}
+	seen := make(map[model.RouteStop]bool, len(req.Stops))
+	for _, s := range req.Stops {
+		if s.OrderID == "" || (s.Kind != model.StopPickup && s.Kind != model.StopDropoff) {
+			return false
+		}
+		if s.Location == nil || !s.Location.Valid() {
+			return false
+		}
+		key := model.RouteStop{OrderID: s.OrderID, Kind: s.Kind}
+		if seen[key] {
+			return false
+		}
+		seen[key] = true
+	}
+	return true
+}
+
+// CompleteStop marks the stop done and plans the rest of the route again
+// from there. Completed stops stay first, in the order they were done.
+func (u *routeUsecase) CompleteStop(ctx context.Context, routeID, seq int) (model.Route, error) {
+	if routeID <= 0 || seq <= 0 {
+		return model.Route{}, ErrBadInput
+	}
+
+	now := time.Now().UTC()
+	return u.repo.UpdateStops(ctx, routeID, func(route *model.Route) error {
+		if route.Status != model.RouteActive {
+			return ErrConflict
+		}
+		idx := -1
+		for i, s := range route.Stops {
+			if s.Seq == seq {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			return ErrNotFound
+		}
+		if route.Stops[idx].CompletedAt != nil {
+			return ErrConflict
+		}
+
+		courier, err := u.courierRepo.GetByID(ctx, route.CourierID)
+		if err != nil {
+			return err
+		}
+
+		completed := route.Stops[idx]
+		completed.CompletedAt = &now
+		var done, remaining []model.RouteStop
+		for i, s := range route.Stops {
+			switch {
+			case i == idx:
+			case s.CompletedAt != nil:
+				done = append(done, s)
+			default:
+				remaining = append(remaining, s)
+			}
+		}
+		done = append(done, completed)
+
+		start := completed.Location
+		route.Stops = append(done, u.planner.Plan(start, now, courier.TransportType, remaining)...)
+		return nil
+	})
+}
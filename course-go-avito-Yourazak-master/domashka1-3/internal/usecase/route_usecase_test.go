@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRouteUsecase_Plan_StartsAtCourierLocation(t *testing.T) {
+	mockCourierRepo := new(MockCourierRepository)
+	mockLocationRepo := new(MockLocationRepository)
+	u := NewRouteUsecase(nil, mockCourierRepo, mockLocationRepo, NewRoutePlanner(lineEstimator{}, time.Minute))
+	departAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	mockCourierRepo.On("GetByID", mock.Anything, 3).Return(model.Courier{ID: 3, TransportType: string(Car)}, nil)
+	mockLocationRepo.On("GetLatest", mock.Anything, 3).Return(model.CourierLocation{CourierID: 3, Lon: 10}, nil)
+
+	plan, err := u.Plan(context.Background(), model.RoutePlanRequest{
+		CourierID: 3,
+		DepartAt:  &departAt,
+		Stops: []model.RouteStop{
+			{OrderID: "a", Kind: model.StopPickup, Location: at(2)},
+			{OrderID: "b", Kind: model.StopPickup, Location: at(8)},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, string(Car), plan.TransportType)
+	assert.Equal(t, []string{"pickup:b", "pickup:a"}, stopIDs(plan.Stops))
+	assert.Equal(t, 1, plan.Stops[0].Seq)
+	assert.Equal(t, departAt.Add(2*time.Minute), *plan.Stops[0].ETA)
+	assert.Equal(t, departAt.Add(9*time.Minute), *plan.Stops[1].ETA)
+	assert.Equal(t, departAt.Add(10*time.Minute), *plan.FinishAt)
+}
+
+func TestRouteUsecase_Plan_InvalidRequest(t *testing.T) {
+	u := NewRouteUsecase(nil, nil, nil, nil)
+	valid := model.RouteStop{OrderID: "a", Kind: model.StopPickup, Location: at(1)}
+
+	for _, req := range []model.RoutePlanRequest{
+		{Stops: []model.RouteStop{valid}},
+		{CourierID: 1},
+		{CourierID: 1, Stops: []model.RouteStop{{OrderID: "a", Kind: model.StopPickup}}},
+		{CourierID: 1, Stops: []model.RouteStop{{OrderID: "a", Kind: "wait", Location: at(1)}}},
+		{CourierID: 1, Stops: []model.RouteStop{valid, valid}},
+	} {
+		_, err := u.Plan(context.Background(), req)
+		assert.Equal(t, ErrBadInput, err)
+	}
+}
+
+func TestRouteUsecase_CompleteStop_Replans(t *testing.T) {
+	mockRepo := new(MockRouteRepository)
+	mockCourierRepo := new(MockCourierRepository)
+	u := NewRouteUsecase(mockRepo, mockCourierRepo, nil, NewRoutePlanner(lineEstimator{}, 0))
+	route := model.Route{ID: 5, CourierID: 3, Status: model.RouteActive, Stops: []model.RouteStop{
+		{Seq: 1, OrderID: "a", Kind: model.StopPickup, Location: at(0)},
+		{Seq: 2, OrderID: "b", Kind: model.StopPickup, Location: at(9)},
+		{Seq: 3, OrderID: "a", Kind: model.StopDropoff, Location: at(1)},
+		{Seq: 4, OrderID: "b", Kind: model.StopDropoff, Location: at(10)},
+	}}
+
+	mockRepo.On("UpdateStops", mock.Anything, 5).Return(route, nil)
+	mockCourierRepo.On("GetByID", mock.Anything, 3).Return(model.Courier{ID: 3, TransportType: string(OnFoot)}, nil)
+
+	updated, err := u.CompleteStop(context.Background(), 5, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pickup:a", "dropoff:a", "pickup:b", "dropoff:b"}, stopIDs(updated.Stops))
+	assert.NotNil(t, updated.Stops[0].CompletedAt)
+	assert.Equal(t, updated.Stops[0].CompletedAt.Add(time.Minute), *updated.Stops[1].ETA)
+}
+
+func TestRouteUsecase_CompleteStop_Errors(t *testing.T) {
+	mockRepo := new(MockRouteRepository)
+	u := NewRouteUsecase(mockRepo, nil, nil, nil)
+	done := time.Now()
+	route := model.Route{ID: 5, Status: model.RouteActive, Stops: []model.RouteStop{
+		{Seq: 1, OrderID: "a", Kind: model.StopPickup, CompletedAt: &done},
+	}}
+	mockRepo.On("UpdateStops", mock.Anything, 5).Return(route, nil)
+
+	_, err := u.CompleteStop(context.Background(), 5, 1)
+	assert.Equal(t, ErrConflict, err)
+
+	_, err = u.CompleteStop(context.Background(), 5, 2)
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = u.CompleteStop(context.Background(), 0, 1)
+	assert.Equal(t, ErrBadInput, err)
+}
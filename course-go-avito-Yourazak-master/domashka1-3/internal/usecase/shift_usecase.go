@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
+)
+
+type ShiftUsecase interface {
+	Create(ctx context.Context, s *model.CourierShift) error
+	GetByID(ctx context.Context, id int) (model.CourierShift, error)
+	ListByCourier(ctx context.Context, courierID int, from, to time.Time) ([]model.CourierShift, error)
+	Update(ctx context.Context, s *model.CourierShift) error
+	Delete(ctx context.Context, id int) error
+}
+
+type shiftUsecase struct {
+	repo        repository.ShiftRepository
+	courierRepo repository.CourierRepository
+}
+
+func NewShiftUsecase(r repository.ShiftRepository, cr repository.CourierRepository) ShiftUsecase {
+	return &shiftUsecase{
+		repo:        r,
+		courierRepo: cr,
+	}
+}
+
+func (u *shiftUsecase) Create(ctx context.Context, s *model.CourierShift) error {
+	if s.CourierID <= 0 || !s.EndsAt.After(s.StartsAt) || !s.EndsAt.After(time.Now()) {
+		return ErrBadInput
+	}
+	if _, err := u.courierRepo.GetByID(ctx, s.CourierID); err != nil {
+		return err
+	}
+	if err := u.checkOverlap(ctx, s); err != nil {
+		return err
+	}
+	return u.repo.Create(ctx, s)
+}
+
+func (u *shiftUsecase) GetByID(ctx context.Context, id int) (model.CourierShift, error) {
+	if id <= 0 {
+		return model.CourierShift{}, ErrBadInput
+	}
+	return u.repo.GetByID(ctx, id)
+}
+
+func (u *shiftUsecase) ListByCourier(ctx context.Context, courierID int, from, to time.Time) ([]model.CourierShift, error) {
+	if courierID <= 0 {
+		return nil, ErrBadInput
+	}
+	if to.IsZero() {
+		to = time.Now().AddDate(0, 0, 7)
+	}
+	if from.IsZero() {
+		from = time.Now().AddDate(0, 0, -1)
+	}
+	if !to.After(from) {
+		return nil, ErrBadInput
+	}
+	return u.repo.ListByCourier(ctx, courierID, from, to)
+}
+
+func (u *shiftUsecase) Update(ctx context.Context, s *model.CourierShift) error {
+	if s.ID <= 0 || !s.EndsAt.After(s.StartsAt) {
+		return ErrBadInput
+	}
+	current, err := u.repo.GetByID(ctx, s.ID)
+	if err != nil {
+		return err
+	}
+	if current.State == model.ShiftFinished {
+		return ErrConflict
+	}
+	s.CourierID = current.CourierID
+	if err := u.checkOverlap(ctx, s); err != nil {
+		return err
+	}
+	return u.repo.Update(ctx, s)
+}
+
+func (u *shiftUsecase) Delete(ctx context.Context, id int) error {
+	if id <= 0 {
+		return ErrBadInput
+	}
+	return u.repo.Delete(ctx, id)
+}
+
+func (u *shiftUsecase) checkOverlap(ctx context.Context, s *model.CourierShift) error {
+	overlap, err := u.repo.HasOverlap(ctx, s.CourierID, s.StartsAt, s.EndsAt, s.ID)
+	if err != nil {
+		return err
+	}
+	if overlap {
+		return ErrConflict
+	}
+	return nil
+}
+
+// ShiftSchedulerLockKey is the pg advisory lock key that elects the single
+// replica allowed to start and finish shifts.
+const ShiftSchedulerLockKey int64 = 7_341_008
+
+type ShiftScheduler struct {
+	repo     repository.ShiftRepository
+	locker   repository.Locker
+	interval time.Duration
+}
+
+func NewShiftScheduler(r repository.ShiftRepository, locker repository.Locker, interval time.Duration) *ShiftScheduler {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &ShiftScheduler{
+		repo:     r,
+		locker:   locker,
+		interval: interval,
+	}
+}
+
+func (s *ShiftScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	defer s.locker.Release(context.Background())
+
+	log.Printf("Shift scheduler started (ticker: %v)", s.interval)
+
+	s.tick(ctx, time.Now().UTC())
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Shift scheduler stopped")
+			return
+		case t := <-ticker.C:
+			s.tick(ctx, t.UTC())
+		}
+	}
+}
+
+// tick runs the scheduler on the replica holding the lock only, so that a
+// shift is started and finished once.
+func (s *ShiftScheduler) tick(ctx context.Context, now time.Time) {
+	acquired, err := s.locker.TryAcquire(ctx)
+	if err != nil {
+		log.Printf("Shift scheduler: failed to acquire lock: %v", err)
+		return
+	}
+	if acquired {
+		s.runOnce(ctx, now)
+	}
+}
+
+func (s *ShiftScheduler) runOnce(ctx context.Context, now time.Time) {
+	ctx = model.WithStatusChange(ctx, model.StatusChange{Actor: model.ActorShiftScheduler})
+
+	started, err := s.repo.ActivateDue(model.WithStatusChange(ctx, model.StatusChange{Reason: "shift started"}), now)
+	if err != nil {
+		log.Printf("Shift scheduler: failed to activate shifts: %v", err)
+	} else if len(started) > 0 {
+		log.Printf("Shift scheduler: couriers %v are now available", started)
+	}
+
+	ended, err := s.repo.FinishDue(model.WithStatusChange(ctx, model.StatusChange{Reason: "shift ended"}), now)
+	if err != nil {
+		log.Printf("Shift scheduler: failed to finish shifts: %v", err)
+	} else if len(ended) > 0 {
+		log.Printf("Shift scheduler: couriers %v are now paused", ended)
+	}
+}
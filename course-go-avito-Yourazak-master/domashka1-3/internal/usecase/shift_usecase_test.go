@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockShiftRepository struct {
+	mock.Mock
+}
+
+func (m *MockShiftRepository) Create(ctx context.Context, s *model.CourierShift) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
+
+func (m *MockShiftRepository) GetByID(ctx context.Context, id int) (model.CourierShift, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(model.CourierShift), args.Error(1)
+}
+
+func (m *MockShiftRepository) ListByCourier(ctx context.Context, courierID int, from, to time.Time) ([]model.CourierShift, error) {
+	args := m.Called(ctx, courierID, from, to)
+	return args.Get(0).([]model.CourierShift), args.Error(1)
+}
+
+func (m *MockShiftRepository) Update(ctx context.Context, s *model.CourierShift) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
+
+func (m *MockShiftRepository) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockShiftRepository) HasOverlap(ctx context.Context, courierID int, startsAt, endsAt time.Time, excludeID int) (bool, error) {
+	args := m.Called(ctx, courierID, startsAt, endsAt, excludeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockShiftRepository) ActivateDue(ctx context.Context, now time.Time) ([]int, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockShiftRepository) FinishDue(ctx context.Context, now time.Time) ([]int, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func TestShiftUsecase_Create_Success(t *testing.T) {
+	mockRepo := new(MockShiftRepository)
+	mockCourierRepo := new(MockCourierRepository)
+	service := NewShiftUsecase(mockRepo, mockCourierRepo)
+
+	start := time.Now().Add(time.Hour)
+	shift := &model.CourierShift{CourierID: 1, StartsAt: start, EndsAt: start.Add(8 * time.Hour)}
+
+	mockCourierRepo.On("GetByID", mock.Anything, 1).Return(model.Courier{ID: 1}, nil)
+	mockRepo.On("HasOverlap", mock.Anything, 1, shift.StartsAt, shift.EndsAt, 0).Return(false, nil)
+	mockRepo.On("Create", mock.Anything, shift).Return(nil)
+
+	err := service.Create(context.Background(), shift)
+
+	assert.NoError(t, err)
+	mockRepo.AssertCalled(t, "Create", mock.Anything, shift)
+}
+
+func TestShiftUsecase_Create_Overlap(t *testing.T) {
+	mockRepo := new(MockShiftRepository)
+	mockCourierRepo := new(MockCourierRepository)
+	service := NewShiftUsecase(mockRepo, mockCourierRepo)
+
+	start := time.Now().Add(time.Hour)
+	shift := &model.CourierShift{CourierID: 1, StartsAt: start, EndsAt: start.Add(8 * time.Hour)}
+
+	mockCourierRepo.On("GetByID", mock.Anything, 1).Return(model.Courier{ID: 1}, nil)
+	mockRepo.On("HasOverlap", mock.Anything, 1, shift.StartsAt, shift.EndsAt, 0).Return(true, nil)
+
+	err := service.Create(context.Background(), shift)
+
+	assert.Equal(t, ErrConflict, err)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestShiftUsecase_Create_InvalidData(t *testing.T) {
+	service := NewShiftUsecase(new(MockShiftRepository), new(MockCourierRepository))
+	now := time.Now()
+
+	testCases := []struct {
+		name  string
+		shift *model.CourierShift
+	}{
+		{"No courier", &model.CourierShift{StartsAt: now, EndsAt: now.Add(time.Hour)}},
+		{"Ends before start", &model.CourierShift{CourierID: 1, StartsAt: now.Add(time.Hour), EndsAt: now}},
+		{"Already ended", &model.CourierShift{CourierID: 1, StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := service.Create(context.Background(), tc.shift)
+			assert.Equal(t, ErrBadInput, err)
+		})
+	}
+}
+
+func TestShiftUsecase_Update_Finished(t *testing.T) {
+	mockRepo := new(MockShiftRepository)
+	service := NewShiftUsecase(mockRepo, new(MockCourierRepository))
+
+	now := time.Now()
+	mockRepo.On("GetByID", mock.Anything, 5).Return(model.CourierShift{ID: 5, CourierID: 1, State: model.ShiftFinished}, nil)
+
+	err := service.Update(context.Background(), &model.CourierShift{ID: 5, StartsAt: now, EndsAt: now.Add(time.Hour)})
+
+	assert.Equal(t, ErrConflict, err)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestShiftScheduler_RunOnce(t *testing.T) {
+	mockRepo := new(MockShiftRepository)
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	taggedWith := func(reason string) any {
+		return mock.MatchedBy(func(ctx context.Context) bool {
+			return model.StatusChangeFrom(ctx) == model.StatusChange{Actor: model.ActorShiftScheduler, Reason: reason}
+		})
+	}
+	mockRepo.On("ActivateDue", taggedWith("shift started"), now).Return([]int{1}, nil)
+	mockRepo.On("FinishDue", taggedWith("shift ended"), now).Return([]int{2}, nil)
+
+	scheduler := NewShiftScheduler(mockRepo, new(MockLocker), time.Minute)
+	scheduler.runOnce(context.Background(), now)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestShiftScheduler_RunsOnlyWithLock(t *testing.T) {
+	mockRepo := new(MockShiftRepository)
+	mockLocker := new(MockLocker)
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	mockLocker.On("TryAcquire", mock.Anything).Return(false, nil).Once()
+	mockLocker.On("TryAcquire", mock.Anything).Return(true, nil).Once()
+	mockRepo.On("ActivateDue", mock.Anything, now).Return([]int{}, nil).Once()
+	mockRepo.On("FinishDue", mock.Anything, now).Return([]int{}, nil).Once()
+
+	scheduler := NewShiftScheduler(mockRepo, mockLocker, time.Minute)
+	scheduler.tick(context.Background(), now)
+	scheduler.tick(context.Background(), now)
+
+	mockLocker.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
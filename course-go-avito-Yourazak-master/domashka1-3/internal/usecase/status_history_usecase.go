@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"context"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
+)
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+type StatusHistoryUsecase interface {
+	List(ctx context.Context, courierID, limit int) ([]model.StatusHistoryEntry, error)
+}
+
+type statusHistoryUsecase struct {
+	repo        repository.StatusHistoryRepository
+	courierRepo repository.CourierRepository
+}
+
+func NewStatusHistoryUsecase(r repository.StatusHistoryRepository, cr repository.CourierRepository) StatusHistoryUsecase {
+	return &statusHistoryUsecase{
+		repo:        r,
+		courierRepo: cr,
+	}
+}
+
+// List returns the latest status changes of the courier, newest first. A
+// zero limit means the default of 100.
+func (u *statusHistoryUsecase) List(ctx context.Context, courierID, limit int) ([]model.StatusHistoryEntry, error) {
+	if courierID <= 0 || limit < 0 || limit > maxHistoryLimit {
+		return nil, ErrBadInput
+	}
+	if limit == 0 {
+		limit = defaultHistoryLimit
+	}
+	if _, err := u.courierRepo.GetByID(ctx, courierID); err != nil {
+		return nil, err
+	}
+	return u.repo.ListByCourier(ctx, courierID, limit)
+}
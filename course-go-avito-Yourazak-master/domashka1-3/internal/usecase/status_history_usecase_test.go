@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"avito-courier/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStatusHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockStatusHistoryRepository) ListByCourier(ctx context.Context, courierID, limit int) ([]model.StatusHistoryEntry, error) {
+	args := m.Called(ctx, courierID, limit)
+	return args.Get(0).([]model.StatusHistoryEntry), args.Error(1)
+}
+
+func TestStatusHistoryUsecase_List_DefaultLimit(t *testing.T) {
+	mockRepo := new(MockStatusHistoryRepository)
+	mockCourierRepo := new(MockCourierRepository)
+	service := NewStatusHistoryUsecase(mockRepo, mockCourierRepo)
+
+	entries := []model.StatusHistoryEntry{{ID: 1, CourierID: 3, NewStatus: "busy", Actor: model.ActorEvent, OrderID: "o-1"}}
+	mockCourierRepo.On("GetByID", mock.Anything, 3).Return(model.Courier{ID: 3}, nil)
+	mockRepo.On("ListByCourier", mock.Anything, 3, defaultHistoryLimit).Return(entries, nil)
+
+	got, err := service.List(context.Background(), 3, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entries, got)
+}
+
+func TestStatusHistoryUsecase_List_CourierNotFound(t *testing.T) {
+	mockRepo := new(MockStatusHistoryRepository)
+	mockCourierRepo := new(MockCourierRepository)
+	service := NewStatusHistoryUsecase(mockRepo, mockCourierRepo)
+
+	mockCourierRepo.On("GetByID", mock.Anything, 3).Return(model.Courier{}, ErrNotFound)
+
+	_, err := service.List(context.Background(), 3, 10)
+
+	assert.Equal(t, ErrNotFound, err)
+	mockRepo.AssertNotCalled(t, "ListByCourier", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStatusHistoryUsecase_List_InvalidInput(t *testing.T) {
+	service := NewStatusHistoryUsecase(new(MockStatusHistoryRepository), new(MockCourierRepository))
+
+	_, err := service.List(context.Background(), 0, 10)
+	assert.Equal(t, ErrBadInput, err)
+
+	_, err = service.List(context.Background(), 3, maxHistoryLimit+1)
+	assert.Equal(t, ErrBadInput, err)
+}
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"time"
+
+	"avito-courier/internal/model"
+)
+
+// TravelTimeEstimator estimates how long a courier with the given transport
+// needs to get from one point to another.
+type TravelTimeEstimator interface {
+	Estimate(from, to model.GeoPoint, transport string) time.Duration
+}
+
+// DefaultTransportSpeeds holds average city speeds in km/h.
+var DefaultTransportSpeeds = map[string]float64{
+	string(OnFoot):  5,
+	string(Scooter): 15,
+	string(Car):     25,
+}
+
+const defaultSpeedKmh = 5
+
+// StraightLineEstimator divides the great-circle distance by an average
+// speed per transport type. Unknown transport types move at walking speed.
+type StraightLineEstimator struct {
+	speeds map[string]float64
+}
+
+func NewStraightLineEstimator(speedsKmh map[string]float64) *StraightLineEstimator {
+	if len(speedsKmh) == 0 {
+		speedsKmh = DefaultTransportSpeeds
+	}
+	return &StraightLineEstimator{speeds: speedsKmh}
+}
+
+func (e *StraightLineEstimator) Estimate(from, to model.GeoPoint, transport string) time.Duration {
+	speed := e.speeds[transport]
+	if speed <= 0 {
+		speed = defaultSpeedKmh
+	}
+	return time.Duration(from.DistanceKm(to) / speed * float64(time.Hour))
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"avito-courier/internal/gateway/webhook"
+	"avito-courier/internal/middleware"
+	"avito-courier/internal/repository"
+)
+
+// WebhookDispatcherLockKey is the pg advisory lock key that elects the single
+// replica allowed to send webhooks.
+const WebhookDispatcherLockKey int64 = 7_341_006
+
+type WebhookDispatcherConfig struct {
+	Interval  time.Duration
+	BatchSize int
+	// MaxAttempts is how often a delivery is tried before it is given up.
+	MaxAttempts int
+	// BackoffBase is the wait after the first failed attempt; it doubles
+	// with every further failure up to BackoffMax.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// DisableAfter is the number of consecutive failed attempts, over all
+	// deliveries, after which a subscription is deactivated.
+	DisableAfter int
+}
+
+// WebhookDispatcher sends queued webhook deliveries. It runs on every tick
+// and whenever Wake is called, e.g. when the deliveries trigger queued new
+// ones.
+type WebhookDispatcher struct {
+	repo   repository.WebhookRepository
+	sender webhook.Sender
+	locker repository.Locker
+	cfg    WebhookDispatcherConfig
+	wake   chan struct{}
+}
+
+func NewWebhookDispatcher(r repository.WebhookRepository, sender webhook.Sender, locker repository.Locker, cfg WebhookDispatcherConfig) *WebhookDispatcher {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 10
+	}
+	if cfg.BackoffBase <= 0 {
+		cfg.BackoffBase = 30 * time.Second
+	}
+	if cfg.BackoffMax <= 0 {
+		cfg.BackoffMax = time.Hour
+	}
+	if cfg.BackoffMax < cfg.BackoffBase {
+		cfg.BackoffMax = cfg.BackoffBase
+	}
+	if cfg.DisableAfter <= 0 {
+		cfg.DisableAfter = 20
+	}
+	return &WebhookDispatcher{
+		repo:   r,
+		sender: sender,
+		locker: locker,
+		cfg:    cfg,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Wake asks the dispatcher to send due deliveries now. It never blocks;
+// wake-ups that arrive while one is pending are merged.
+func (d *WebhookDispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *WebhookDispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+	defer d.locker.Release(context.Background())
+
+	log.Printf("Webhook dispatcher started (ticker: %v, batch: %d, max attempts: %d)", d.cfg.Interval, d.cfg.BatchSize, d.cfg.MaxAttempts)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Webhook dispatcher stopped")
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+
+		acquired, err := d.locker.TryAcquire(ctx)
+		if err != nil {
+			log.Printf("Webhook dispatcher: failed to acquire lock: %v", err)
+			continue
+		}
+		if acquired {
+			d.runOnce(ctx, time.Now().UTC())
+		}
+	}
+}
+
+func (d *WebhookDispatcher) runOnce(ctx context.Context, now time.Time) {
+	due, err := d.repo.ClaimDue(ctx, now, d.cfg.BatchSize)
+	if err != nil {
+		log.Printf("Webhook dispatcher: failed to load due deliveries: %v", err)
+		return
+	}
+
+	disabled := make(map[int64]bool)
+	for _, w := range due {
+		if disabled[w.SubscriptionID] {
+			continue
+		}
+
+		status, err := d.sender.Send(ctx, w)
+		if err == nil {
+			middleware.WebhookDeliveriesTotal.WithLabelValues("delivered").Inc()
+			if err := d.repo.MarkDelivered(ctx, w.ID, status, time.Now().UTC()); err != nil {
+				log.Printf("Webhook dispatcher: failed to mark delivery %d delivered: %v", w.ID, err)
+			}
+			continue
+		}
+
+		var statusPtr *int
+		if status != 0 {
+			statusPtr = &status
+		}
+		var next *time.Time
+		result := "failed"
+		if w.Attempts+1 < d.cfg.MaxAttempts {
+			t := now.Add(d.backoff(w.Attempts + 1))
+			next = &t
+			result = "retry"
+		}
+		middleware.WebhookDeliveriesTotal.WithLabelValues(result).Inc()
+
+		active, err := d.repo.RecordFailure(ctx, w.ID, statusPtr, err.Error(), next, d.cfg.DisableAfter)
+		if err != nil {
+			log.Printf("Webhook dispatcher: failed to record attempt of delivery %d: %v", w.ID, err)
+			continue
+		}
+		if !active {
+			disabled[w.SubscriptionID] = true
+			middleware.WebhookSubscriptionsDisabledTotal.Inc()
+			log.Printf("Webhook dispatcher: subscription %d disabled after %d consecutive failures", w.SubscriptionID, d.cfg.DisableAfter)
+		}
+	}
+}
+
+// backoff is the wait before the next attempt after failed attempts.
+func (d *WebhookDispatcher) backoff(failed int) time.Duration {
+	wait := d.cfg.BackoffBase
+	for i := 1; i < failed && wait < d.cfg.BackoffMax; i++ {
+		wait *= 2
+	}
+	return min(wait, d.cfg.BackoffMax)
+}
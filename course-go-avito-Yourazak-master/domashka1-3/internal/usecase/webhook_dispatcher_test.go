@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookSender struct {
+	mock.Mock
+}
+
+func (m *MockWebhookSender) Send(ctx context.Context, d model.WebhookDelivery) (int, error) {
+	args := m.Called(ctx, d)
+	return args.Int(0), args.Error(1)
+}
+
+func TestWebhookDispatcher_Backoff(t *testing.T) {
+	d := NewWebhookDispatcher(nil, nil, nil, WebhookDispatcherConfig{BackoffBase: 30 * time.Second, BackoffMax: 5 * time.Minute})
+
+	assert.Equal(t, 30*time.Second, d.backoff(1))
+	assert.Equal(t, time.Minute, d.backoff(2))
+	assert.Equal(t, 4*time.Minute, d.backoff(4))
+	assert.Equal(t, 5*time.Minute, d.backoff(5))
+	assert.Equal(t, 5*time.Minute, d.backoff(40))
+}
+
+func TestWebhookDispatcher_RunOnce(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	sender := new(MockWebhookSender)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	ok := model.WebhookDelivery{ID: 1, SubscriptionID: 10}
+	retry := model.WebhookDelivery{ID: 2, SubscriptionID: 20, Attempts: 1}
+	last := model.WebhookDelivery{ID: 3, SubscriptionID: 30, Attempts: 2}
+	repo.On("ClaimDue", mock.Anything, now, 50).Return([]model.WebhookDelivery{ok, retry, last}, nil)
+
+	sender.On("Send", mock.Anything, ok).Return(200, nil)
+	repo.On("MarkDelivered", mock.Anything, int64(1), 200, mock.Anything).Return(nil)
+
+	sender.On("Send", mock.Anything, retry).Return(503, errors.New("unexpected status: 503"))
+	status := 503
+	next := now.Add(time.Minute)
+	repo.On("RecordFailure", mock.Anything, int64(2), &status, "unexpected status: 503", &next, 5).Return(true, nil)
+
+	sender.On("Send", mock.Anything, last).Return(0, errors.New("do request: timeout"))
+	repo.On("RecordFailure", mock.Anything, int64(3), (*int)(nil), "do request: timeout", (*time.Time)(nil), 5).Return(true, nil)
+
+	d := NewWebhookDispatcher(repo, sender, new(MockLocker), WebhookDispatcherConfig{MaxAttempts: 3, BackoffBase: 30 * time.Second, DisableAfter: 5})
+	d.runOnce(context.Background(), now)
+
+	repo.AssertExpectations(t)
+	sender.AssertExpectations(t)
+}
+
+func TestWebhookDispatcher_SkipsDisabledSubscription(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	sender := new(MockWebhookSender)
+	now := time.Now()
+
+	first := model.WebhookDelivery{ID: 1, SubscriptionID: 10}
+	second := model.WebhookDelivery{ID: 2, SubscriptionID: 10}
+	repo.On("ClaimDue", mock.Anything, now, 50).Return([]model.WebhookDelivery{first, second}, nil)
+	sender.On("Send", mock.Anything, first).Return(500, errors.New("unexpected status: 500"))
+	repo.On("RecordFailure", mock.Anything, int64(1), mock.Anything, mock.Anything, mock.Anything, 20).Return(false, nil)
+
+	d := NewWebhookDispatcher(repo, sender, new(MockLocker), WebhookDispatcherConfig{})
+	d.runOnce(context.Background(), now)
+
+	sender.AssertNumberOfCalls(t, "Send", 1)
+	repo.AssertExpectations(t)
+}
+
+func TestWebhookDispatcher_SendsExpiredAfterAutoRelease(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	sender := new(MockWebhookSender)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// The deliveries trigger queues the expired event for subscribers once
+	// the releaser expires the delivery; stand in for it.
+	var queued []model.WebhookDelivery
+	couriers := new(MockCourierRepository)
+	couriers.On("ReleaseOverdue", fromAutoRelease()).Run(func(mock.Arguments) {
+		queued = append(queued, model.WebhookDelivery{
+			ID:             1,
+			SubscriptionID: 10,
+			Event:          model.WebhookEventExpired,
+			Payload:        []byte(`{"event":"expired","delivery_id":5,"order_id":"o1","courier_id":7,"status":"expired","kind":"delivery"}`),
+		})
+	}).Return([]int{7}, nil)
+	u := NewDeliveryUsecase(nil, couriers, nil, nil, nil, nil, nil, NewDeliveryTimeFactory(), nil, nil, nil, nil, AssignmentConfig{})
+	NewDeliveryReleaser(u, new(MockLocker), DeliveryReleaserConfig{}).runOnce(context.Background())
+
+	repo.On("ClaimDue", mock.Anything, now, 50).Return(queued, nil)
+	sender.On("Send", mock.Anything, mock.MatchedBy(func(w model.WebhookDelivery) bool {
+		return w.Event == model.WebhookEventExpired && w.SubscriptionID == 10
+	})).Return(204, nil)
+	repo.On("MarkDelivered", mock.Anything, int64(1), 204, mock.Anything).Return(nil)
+
+	d := NewWebhookDispatcher(repo, sender, new(MockLocker), WebhookDispatcherConfig{})
+	d.runOnce(context.Background(), now)
+
+	couriers.AssertExpectations(t)
+	repo.AssertExpectations(t)
+	sender.AssertExpectations(t)
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
+)
+
+const (
+	webhookSecretBytes     = 32
+	webhookMinSecretLength = 16
+)
+
+type WebhookUsecase interface {
+	// Create stores the subscription and generates its secret unless one
+	// was given. The returned subscription is the only place the secret is
+	// shown.
+	Create(ctx context.Context, s *model.WebhookSubscription) error
+	GetByID(ctx context.Context, id int64) (model.WebhookSubscription, error)
+	List(ctx context.Context) ([]model.WebhookSubscription, error)
+	Update(ctx context.Context, s *model.WebhookSubscription) error
+	Delete(ctx context.Context, id int64) error
+	// ListDeliveries is the delivery log of a subscription, newest first.
+	ListDeliveries(ctx context.Context, subscriptionID int64, state string, limit int) ([]model.WebhookDelivery, error)
+}
+
+type webhookUsecase struct {
+	repo repository.WebhookRepository
+}
+
+func NewWebhookUsecase(r repository.WebhookRepository) WebhookUsecase {
+	return &webhookUsecase{repo: r}
+}
+
+func validateWebhookSubscription(s *model.WebhookSubscription) error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrBadInput
+	}
+	if s.Secret != "" && len(s.Secret) < webhookMinSecretLength {
+		return ErrBadInput
+	}
+
+	events := make([]string, 0, len(s.EventTypes))
+	seen := make(map[string]bool, len(s.EventTypes))
+	for _, e := range s.EventTypes {
+		if !model.ValidWebhookEvent(e) {
+			return ErrBadInput
+		}
+		if !seen[e] {
+			seen[e] = true
+			events = append(events, e)
+		}
+	}
+	if len(events) == 0 {
+		return ErrBadInput
+	}
+	s.EventTypes = events
+	s.MerchantID = strings.TrimSpace(s.MerchantID)
+	return nil
+}
+
+func (u *webhookUsecase) Create(ctx context.Context, s *model.WebhookSubscription) error {
+	s.ID = 0
+	if err := validateWebhookSubscription(s); err != nil {
+		return err
+	}
+	if s.Secret == "" {
+		buf := make([]byte, webhookSecretBytes)
+		if _, err := rand.Read(buf); err != nil {
+			return err
+		}
+		s.Secret = hex.EncodeToString(buf)
+	}
+	return u.repo.Create(ctx, s)
+}
+
+func (u *webhookUsecase) GetByID(ctx context.Context, id int64) (model.WebhookSubscription, error) {
+	if id <= 0 {
+		return model.WebhookSubscription{}, ErrBadInput
+	}
+	return u.repo.GetByID(ctx, id)
+}
+
+func (u *webhookUsecase) List(ctx context.Context) ([]model.WebhookSubscription, error) {
+	return u.repo.List(ctx)
+}
+
+// Update leaves the secret unchanged when s.Secret is empty; a new secret is
+// not echoed back.
+func (u *webhookUsecase) Update(ctx context.Context, s *model.WebhookSubscription) error {
+	if s.ID <= 0 {
+		return ErrBadInput
+	}
+	if err := validateWebhookSubscription(s); err != nil {
+		return err
+	}
+	if err := u.repo.Update(ctx, s); err != nil {
+		return err
+	}
+	s.Secret = ""
+	return nil
+}
+
+func (u *webhookUsecase) Delete(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return ErrBadInput
+	}
+	return u.repo.Delete(ctx, id)
+}
+
+func (u *webhookUsecase) ListDeliveries(ctx context.Context, subscriptionID int64, state string, limit int) ([]model.WebhookDelivery, error) {
+	if subscriptionID <= 0 || limit < 0 || limit > 1000 {
+		return nil, ErrBadInput
+	}
+	if state != "" && !model.ValidWebhookDeliveryState(state) {
+		return nil, ErrBadInput
+	}
+	if limit == 0 {
+		limit = 100
+	}
+	if _, err := u.repo.GetByID(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	return u.repo.ListDeliveries(ctx, subscriptionID, state, limit)
+}
@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"avito-courier/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) Create(ctx context.Context, s *model.WebhookSubscription) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetByID(ctx context.Context, id int64) (model.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) List(ctx context.Context) ([]model.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Update(ctx context.Context, s *model.WebhookSubscription) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, state string, limit int) ([]model.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionID, state, limit)
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ClaimDue(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) MarkDelivered(ctx context.Context, id int64, status int, at time.Time) error {
+	args := m.Called(ctx, id, status, at)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) RecordFailure(ctx context.Context, id int64, status *int, reason string, nextAttempt *time.Time, disableAfter int) (bool, error) {
+	args := m.Called(ctx, id, status, reason, nextAttempt, disableAfter)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestWebhookUsecase_Create(t *testing.T) {
+	testCases := []struct {
+		name string
+		sub  model.WebhookSubscription
+		err  error
+	}{
+		{"valid", model.WebhookSubscription{URL: "https://shop.example/hooks", EventTypes: []string{"assigned", "completed", "assigned"}}, nil},
+		{"merchant", model.WebhookSubscription{URL: "https://shop.example/hooks", EventTypes: []string{"completed"}, MerchantID: " shop "}, nil},
+		{"own secret", model.WebhookSubscription{URL: "http://shop.example/hooks", EventTypes: []string{"expired"}, Secret: "0123456789abcdef"}, nil},
+		{"short secret", model.WebhookSubscription{URL: "https://shop.example/hooks", EventTypes: []string{"expired"}, Secret: "short"}, ErrBadInput},
+		{"no events", model.WebhookSubscription{URL: "https://shop.example/hooks"}, ErrBadInput},
+		{"unknown event", model.WebhookSubscription{URL: "https://shop.example/hooks", EventTypes: []string{"picked_up"}}, ErrBadInput},
+		{"relative url", model.WebhookSubscription{URL: "/hooks", EventTypes: []string{"assigned"}}, ErrBadInput},
+		{"bad scheme", model.WebhookSubscription{URL: "ftp://shop.example/hooks", EventTypes: []string{"assigned"}}, ErrBadInput},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(MockWebhookRepository)
+			if tc.err == nil {
+				repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+			}
+			u := NewWebhookUsecase(repo)
+
+			sub := tc.sub
+			err := u.Create(context.Background(), &sub)
+
+			assert.Equal(t, tc.err, err)
+			if tc.err == nil {
+				assert.GreaterOrEqual(t, len(sub.Secret), webhookMinSecretLength)
+				assert.NotContains(t, sub.EventTypes[1:], sub.EventTypes[0])
+				assert.Equal(t, strings.TrimSpace(tc.sub.MerchantID), sub.MerchantID)
+				repo.AssertExpectations(t)
+			} else {
+				repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWebhookUsecase_UpdateHidesSecret(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(s *model.WebhookSubscription) bool {
+		return s.Secret == "new-secret-0123456789"
+	})).Return(nil)
+	u := NewWebhookUsecase(repo)
+
+	sub := model.WebhookSubscription{ID: 3, URL: "https://shop.example/hooks", EventTypes: []string{"assigned"}, Secret: "new-secret-0123456789", Active: true}
+	err := u.Update(context.Background(), &sub)
+
+	assert.NoError(t, err)
+	assert.Empty(t, sub.Secret)
+	repo.AssertExpectations(t)
+
+	sub.ID = 0
+	assert.Equal(t, ErrBadInput, u.Update(context.Background(), &sub))
+}
+
+func TestWebhookUsecase_ListDeliveries(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	repo.On("GetByID", mock.Anything, int64(3)).Return(model.WebhookSubscription{ID: 3}, nil)
+	repo.On("GetByID", mock.Anything, int64(4)).Return(model.WebhookSubscription{}, ErrNotFound)
+	repo.On("ListDeliveries", mock.Anything, int64(3), "failed", 100).Return([]model.WebhookDelivery{{ID: 1, State: "failed"}}, nil)
+	u := NewWebhookUsecase(repo)
+
+	log, err := u.ListDeliveries(context.Background(), 3, "failed", 0)
+	assert.NoError(t, err)
+	assert.Len(t, log, 1)
+
+	_, err = u.ListDeliveries(context.Background(), 4, "", 10)
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = u.ListDeliveries(context.Background(), 3, "retrying", 10)
+	assert.Equal(t, ErrBadInput, err)
+	_, err = u.ListDeliveries(context.Background(), 3, "", 5000)
+	assert.Equal(t, ErrBadInput, err)
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
+)
+
+type ZoneUsecase interface {
+	Create(ctx context.Context, z *model.DeliveryZone) error
+	GetByID(ctx context.Context, id int) (model.DeliveryZone, error)
+	List(ctx context.Context) ([]model.DeliveryZone, error)
+	Update(ctx context.Context, z *model.DeliveryZone) error
+	Delete(ctx context.Context, id int) error
+	ListByCourier(ctx context.Context, courierID int) ([]model.DeliveryZone, error)
+	SetCourierZones(ctx context.Context, courierID int, zoneIDs []int) error
+	// Resolve returns the zone serving the order, or nil when no zone matches.
+	Resolve(ctx context.Context, o model.ExternalOrder) (*model.DeliveryZone, error)
+}
+
+type zoneUsecase struct {
+	repo        repository.ZoneRepository
+	courierRepo repository.CourierRepository
+}
+
+func NewZoneUsecase(r repository.ZoneRepository, cr repository.CourierRepository) ZoneUsecase {
+	return &zoneUsecase{
+		repo:        r,
+		courierRepo: cr,
+	}
+}
+
+func (u *zoneUsecase) validate(ctx context.Context, z *model.DeliveryZone) error {
+	if z.Name == "" {
+		return ErrBadInput
+	}
+	if z.RegionID != nil && *z.RegionID <= 0 {
+		return ErrBadInput
+	}
+	if z.RegionID == nil && len(z.Polygon) == 0 {
+		return ErrBadInput
+	}
+	if len(z.Polygon) > 0 {
+		if len(z.Polygon) < 3 {
+			return ErrBadInput
+		}
+		for _, p := range z.Polygon {
+			if !p.Valid() {
+				return ErrBadInput
+			}
+		}
+	}
+
+	z.NeighbourIDs = dedupIDs(z.NeighbourIDs)
+	for _, id := range z.NeighbourIDs {
+		if id <= 0 || id == z.ID {
+			return ErrBadInput
+		}
+		if _, err := u.repo.GetByID(ctx, id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrBadInput
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *zoneUsecase) Create(ctx context.Context, z *model.DeliveryZone) error {
+	z.ID = 0
+	if err := u.validate(ctx, z); err != nil {
+		return err
+	}
+	return u.repo.Create(ctx, z)
+}
+
+func (u *zoneUsecase) GetByID(ctx context.Context, id int) (model.DeliveryZone, error) {
+	if id <= 0 {
+		return model.DeliveryZone{}, ErrBadInput
+	}
+	return u.repo.GetByID(ctx, id)
+}
+
+func (u *zoneUsecase) List(ctx context.Context) ([]model.DeliveryZone, error) {
+	return u.repo.List(ctx)
+}
+
+func (u *zoneUsecase) Update(ctx context.Context, z *model.DeliveryZone) error {
+	if z.ID <= 0 {
+		return ErrBadInput
+	}
+	if err := u.validate(ctx, z); err != nil {
+		return err
+	}
+	return u.repo.Update(ctx, z)
+}
+
+func (u *zoneUsecase) Delete(ctx context.Context, id int) error {
+	if id <= 0 {
+		return ErrBadInput
+	}
+	return u.repo.Delete(ctx, id)
+}
+
+func (u *zoneUsecase) ListByCourier(ctx context.Context, courierID int) ([]model.DeliveryZone, error) {
+	if courierID <= 0 {
+		return nil, ErrBadInput
+	}
+	if _, err := u.courierRepo.GetByID(ctx, courierID); err != nil {
+		return nil, err
+	}
+	return u.repo.ListByCourier(ctx, courierID)
+}
+
+func (u *zoneUsecase) SetCourierZones(ctx context.Context, courierID int, zoneIDs []int) error {
+	if courierID <= 0 {
+		return ErrBadInput
+	}
+	for _, id := range zoneIDs {
+		if id <= 0 {
+			return ErrBadInput
+		}
+	}
+	if _, err := u.courierRepo.GetByID(ctx, courierID); err != nil {
+		return err
+	}
+	return u.repo.SetCourierZones(ctx, courierID, dedupIDs(zoneIDs))
+}
+
+// Resolve matches the order region first and falls back to the first polygon
+// containing the pickup point.
+func (u *zoneUsecase) Resolve(ctx context.Context, o model.ExternalOrder) (*model.DeliveryZone, error) {
+	if o.Region > 0 {
+		z, err := u.repo.FindByRegion(ctx, o.Region)
+		if err == nil {
+			return &z, nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	if o.Pickup == nil {
+		return nil, nil
+	}
+
+	zones, err := u.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range zones {
+		if zones[i].Contains(*o.Pickup) {
+			return &zones[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func dedupIDs(ids []int) []int {
+	seen := make(map[int]struct{}, len(ids))
+	out := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"avito-courier/internal/model"
+	"avito-courier/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockZoneRepository struct {
+	mock.Mock
+}
+
+func (m *MockZoneRepository) Create(ctx context.Context, z *model.DeliveryZone) error {
+	args := m.Called(ctx, z)
+	return args.Error(0)
+}
+
+func (m *MockZoneRepository) GetByID(ctx context.Context, id int) (model.DeliveryZone, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(model.DeliveryZone), args.Error(1)
+}
+
+func (m *MockZoneRepository) List(ctx context.Context) ([]model.DeliveryZone, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.DeliveryZone), args.Error(1)
+}
+
+func (m *MockZoneRepository) Update(ctx context.Context, z *model.DeliveryZone) error {
+	args := m.Called(ctx, z)
+	return args.Error(0)
+}
+
+func (m *MockZoneRepository) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockZoneRepository) FindByRegion(ctx context.Context, regionID int) (model.DeliveryZone, error) {
+	args := m.Called(ctx, regionID)
+	return args.Get(0).(model.DeliveryZone), args.Error(1)
+}
+
+func (m *MockZoneRepository) ListByCourier(ctx context.Context, courierID int) ([]model.DeliveryZone, error) {
+	args := m.Called(ctx, courierID)
+	return args.Get(0).([]model.DeliveryZone), args.Error(1)
+}
+
+func (m *MockZoneRepository) SetCourierZones(ctx context.Context, courierID int, zoneIDs []int) error {
+	args := m.Called(ctx, courierID, zoneIDs)
+	return args.Error(0)
+}
+
+var testSquare = []model.GeoPoint{
+	{Lat: 55.70, Lon: 37.50},
+	{Lat: 55.70, Lon: 37.70},
+	{Lat: 55.80, Lon: 37.70},
+	{Lat: 55.80, Lon: 37.50},
+}
+
+func TestZoneUsecase_Create_Validation(t *testing.T) {
+	mockRepo := new(MockZoneRepository)
+	uc := NewZoneUsecase(mockRepo, new(MockCourierRepository))
+	region := 0
+
+	cases := []model.DeliveryZone{
+		{Polygon: testSquare},
+		{Name: "empty"},
+		{Name: "bad region", RegionID: &region},
+		{Name: "triangle missing", Polygon: testSquare[:2]},
+		{Name: "bad point", Polygon: []model.GeoPoint{{Lat: 91}, {Lat: 0}, {Lon: 1}}},
+	}
+	for _, z := range cases {
+		assert.Equal(t, ErrBadInput, uc.Create(context.Background(), &z), z.Name)
+	}
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestZoneUsecase_Create_UnknownNeighbour(t *testing.T) {
+	mockRepo := new(MockZoneRepository)
+	mockRepo.On("GetByID", mock.Anything, 5).Return(model.DeliveryZone{}, repository.ErrNotFound)
+
+	uc := NewZoneUsecase(mockRepo, new(MockCourierRepository))
+	zone := model.DeliveryZone{Name: "center", Polygon: testSquare, NeighbourIDs: []int{5, 5}}
+
+	err := uc.Create(context.Background(), &zone)
+
+	assert.Equal(t, ErrBadInput, err)
+	mockRepo.AssertNumberOfCalls(t, "GetByID", 1)
+}
+
+func TestZoneUsecase_Resolve_ByRegion(t *testing.T) {
+	mockRepo := new(MockZoneRepository)
+	region := 77
+	mockRepo.On("FindByRegion", mock.Anything, 77).Return(model.DeliveryZone{ID: 3, RegionID: &region}, nil)
+
+	uc := NewZoneUsecase(mockRepo, new(MockCourierRepository))
+	zone, err := uc.Resolve(context.Background(), model.ExternalOrder{ID: "o1", Region: 77})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, zone.ID)
+	mockRepo.AssertNotCalled(t, "List", mock.Anything)
+}
+
+func TestZoneUsecase_Resolve_FallsBackToPolygon(t *testing.T) {
+	mockRepo := new(MockZoneRepository)
+	mockRepo.On("FindByRegion", mock.Anything, 50).Return(model.DeliveryZone{}, repository.ErrNotFound)
+	mockRepo.On("List", mock.Anything).Return([]model.DeliveryZone{
+		{ID: 1, Polygon: []model.GeoPoint{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 1}, {Lat: 1, Lon: 1}}},
+		{ID: 2, Polygon: testSquare},
+	}, nil)
+
+	uc := NewZoneUsecase(mockRepo, new(MockCourierRepository))
+	zone, err := uc.Resolve(context.Background(), model.ExternalOrder{
+		ID:     "o1",
+		Region: 50,
+		Pickup: &model.GeoPoint{Lat: 55.75, Lon: 37.60},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, zone.ID)
+
+	zone, err = uc.Resolve(context.Background(), model.ExternalOrder{
+		ID:     "o2",
+		Pickup: &model.GeoPoint{Lat: 10, Lon: 10},
+	})
+
+	assert.NoError(t, err)
+	assert.Nil(t, zone)
+}
+
+func TestDeliveryUsecase_ZoneTiers(t *testing.T) {
+	mockRepo := new(MockZoneRepository)
+	region := 77
+	mockRepo.On("FindByRegion", mock.Anything, 77).Return(model.DeliveryZone{ID: 3, RegionID: &region, NeighbourIDs: []int{4, 5}}, nil)
+	zones := NewZoneUsecase(mockRepo, new(MockCourierRepository))
+	order := model.ExternalOrder{ID: "o1", Region: 77}
+
+	uc := NewDeliveryUsecase(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, zones, AssignmentConfig{ZoneSpillover: true})
+	tiers, err := uc.zoneTiers(context.Background(), order)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]int{{3}, {4, 5}}, tiers)
+
+	uc = NewDeliveryUsecase(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, zones, AssignmentConfig{})
+	tiers, err = uc.zoneTiers(context.Background(), order)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]int{{3}}, tiers)
+
+	uc = NewDeliveryUsecase(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, AssignmentConfig{})
+	tiers, err = uc.zoneTiers(context.Background(), order)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]int{nil}, tiers)
+}
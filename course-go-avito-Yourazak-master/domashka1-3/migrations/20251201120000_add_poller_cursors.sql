@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS poller_cursors (
+    name       TEXT PRIMARY KEY,
+    cursor_at  TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS poller_cursors;
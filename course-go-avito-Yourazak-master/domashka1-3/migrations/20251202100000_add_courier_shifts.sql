@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS courier_shifts (
+    id         BIGSERIAL PRIMARY KEY,
+    courier_id BIGINT NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+    starts_at  TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at    TIMESTAMP WITH TIME ZONE NOT NULL,
+    state      TEXT NOT NULL DEFAULT 'scheduled' CHECK (state IN ('scheduled','active','finished')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_courier_shifts_courier_id ON courier_shifts(courier_id, starts_at);
+CREATE INDEX IF NOT EXISTS idx_courier_shifts_state ON courier_shifts(state, starts_at, ends_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS courier_shifts;
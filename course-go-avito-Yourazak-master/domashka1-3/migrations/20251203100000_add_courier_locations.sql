@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS courier_locations (
+    courier_id  BIGINT PRIMARY KEY REFERENCES couriers(id) ON DELETE CASCADE,
+    lat         DOUBLE PRECISION NOT NULL,
+    lon         DOUBLE PRECISION NOT NULL,
+    accuracy_m  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at  TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_courier_locations_lat_lon ON courier_locations(lat, lon);
+
+CREATE TABLE IF NOT EXISTS courier_location_history (
+    id          BIGSERIAL PRIMARY KEY,
+    courier_id  BIGINT NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+    lat         DOUBLE PRECISION NOT NULL,
+    lon         DOUBLE PRECISION NOT NULL,
+    accuracy_m  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_courier_location_history_courier ON courier_location_history(courier_id, recorded_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS courier_location_history;
+DROP TABLE IF EXISTS courier_locations;
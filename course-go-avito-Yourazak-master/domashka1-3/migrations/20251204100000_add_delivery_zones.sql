@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS delivery_zones (
+    id            BIGSERIAL PRIMARY KEY,
+    name          TEXT NOT NULL,
+    region_id     INTEGER UNIQUE,
+    polygon       JSONB,
+    neighbour_ids BIGINT[] NOT NULL DEFAULT '{}',
+    created_at    TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at    TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    CHECK (region_id IS NOT NULL OR polygon IS NOT NULL)
+);
+
+CREATE TABLE IF NOT EXISTS courier_zones (
+    courier_id BIGINT NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+    zone_id    BIGINT NOT NULL REFERENCES delivery_zones(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    PRIMARY KEY (courier_id, zone_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_courier_zones_zone_id ON courier_zones(zone_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS courier_zones;
+DROP TABLE IF EXISTS delivery_zones;
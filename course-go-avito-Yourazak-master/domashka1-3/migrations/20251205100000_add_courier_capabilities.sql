@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS courier_capabilities (
+    courier_id      BIGINT PRIMARY KEY REFERENCES couriers(id) ON DELETE CASCADE,
+    max_weight_kg   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    max_volume_l    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    max_distance_km DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS courier_capabilities;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE couriers
+    ADD COLUMN IF NOT EXISTS email                TEXT,
+    ADD COLUMN IF NOT EXISTS vehicle_plate        TEXT,
+    ADD COLUMN IF NOT EXISTS rating               DOUBLE PRECISION CHECK (rating BETWEEN 0 AND 5),
+    ADD COLUMN IF NOT EXISTS external_employee_id TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_couriers_external_employee_id
+    ON couriers(external_employee_id) WHERE external_employee_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_couriers_external_employee_id;
+ALTER TABLE couriers
+    DROP COLUMN IF EXISTS email,
+    DROP COLUMN IF EXISTS vehicle_plate,
+    DROP COLUMN IF EXISTS rating,
+    DROP COLUMN IF EXISTS external_employee_id;
+-- +goose StatementEnd
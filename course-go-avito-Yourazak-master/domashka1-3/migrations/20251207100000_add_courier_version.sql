@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE couriers ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE couriers DROP COLUMN IF EXISTS version;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE INDEX IF NOT EXISTS idx_couriers_transport_type ON couriers(transport_type);
+CREATE INDEX IF NOT EXISTS idx_couriers_created_at_id ON couriers(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_couriers_updated_at_id ON couriers(updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_couriers_name_id ON couriers(name, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_couriers_name_id;
+DROP INDEX IF EXISTS idx_couriers_updated_at_id;
+DROP INDEX IF EXISTS idx_couriers_created_at_id;
+DROP INDEX IF EXISTS idx_couriers_transport_type;
@@ -0,0 +1,44 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS courier_status_history (
+    id         BIGSERIAL PRIMARY KEY,
+    courier_id BIGINT NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+    old_status TEXT,
+    new_status TEXT NOT NULL,
+    reason     TEXT,
+    actor      TEXT NOT NULL,
+    order_id   TEXT,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_courier_status_history_courier ON courier_status_history(courier_id, changed_at DESC, id DESC);
+
+-- The application describes a change with transaction-local settings
+-- (courier_audit.*) before touching couriers.status, so every writer is
+-- covered, including bulk jobs that update many rows at once.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION log_courier_status_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.status IS DISTINCT FROM OLD.status THEN
+        INSERT INTO courier_status_history (courier_id, old_status, new_status, reason, actor, order_id)
+        VALUES (
+            NEW.id,
+            CASE WHEN TG_OP = 'UPDATE' THEN OLD.status END,
+            NEW.status,
+            NULLIF(current_setting('courier_audit.reason', true), ''),
+            COALESCE(NULLIF(current_setting('courier_audit.actor', true), ''), 'system'),
+            NULLIF(current_setting('courier_audit.order_id', true), '')
+        );
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER couriers_status_history
+    AFTER INSERT OR UPDATE OF status ON couriers
+    FOR EACH ROW EXECUTE FUNCTION log_courier_status_change();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS couriers_status_history ON couriers;
+DROP FUNCTION IF EXISTS log_courier_status_change();
+DROP TABLE IF EXISTS courier_status_history;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS courier_pauses (
+    courier_id BIGINT PRIMARY KEY REFERENCES couriers(id) ON DELETE CASCADE,
+    reason     TEXT NOT NULL CHECK (reason IN ('break','vehicle_issue','end_of_day')),
+    state      TEXT NOT NULL CHECK (state IN ('pending','active')),
+    resume_at  TIMESTAMP WITH TIME ZONE,
+    paused_at  TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_courier_pauses_resume_at ON courier_pauses(resume_at) WHERE resume_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS courier_pauses;
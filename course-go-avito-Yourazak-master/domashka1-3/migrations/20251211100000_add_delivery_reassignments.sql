@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS delivery_reassignments (
+    id              BIGSERIAL PRIMARY KEY,
+    delivery_id     BIGINT NOT NULL,
+    order_id        TEXT NOT NULL,
+    from_courier_id BIGINT NOT NULL,
+    to_courier_id   BIGINT NOT NULL,
+    actor           TEXT NOT NULL,
+    reason          TEXT,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_reassignments_delivery ON delivery_reassignments(delivery_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS delivery_reassignments;
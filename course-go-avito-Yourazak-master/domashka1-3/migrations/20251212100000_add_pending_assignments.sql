@@ -0,0 +1,35 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS pending_assignments (
+    id              BIGSERIAL PRIMARY KEY,
+    order_id        TEXT NOT NULL UNIQUE,
+    payload         JSONB NOT NULL,
+    priority        INTEGER NOT NULL DEFAULT 0,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    enqueued_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    last_attempt_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_assignments_order ON pending_assignments(priority DESC, enqueued_at, id);
+
+-- Wakes the assignment queue worker whenever a courier becomes available,
+-- whichever code path freed it.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_courier_available() RETURNS trigger AS $$
+BEGIN
+    IF NEW.status = 'available' AND (TG_OP = 'INSERT' OR OLD.status IS DISTINCT FROM 'available') THEN
+        PERFORM pg_notify('courier_available', NEW.id::text);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER couriers_notify_available
+    AFTER INSERT OR UPDATE OF status ON couriers
+    FOR EACH ROW EXECUTE FUNCTION notify_courier_available();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS couriers_notify_available ON couriers;
+DROP FUNCTION IF EXISTS notify_courier_available();
+DROP TABLE IF EXISTS pending_assignments;
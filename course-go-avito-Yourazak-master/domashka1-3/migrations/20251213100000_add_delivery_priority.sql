@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS priority TEXT NOT NULL DEFAULT 'standard'
+    CHECK (priority IN ('express','standard','scheduled'));
+
+-- +goose Down
+ALTER TABLE deliveries DROP COLUMN IF EXISTS priority;
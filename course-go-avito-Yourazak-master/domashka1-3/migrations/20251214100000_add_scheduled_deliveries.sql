@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS scheduled_deliveries (
+    id           BIGSERIAL PRIMARY KEY,
+    order_id     TEXT NOT NULL UNIQUE,
+    payload      JSONB NOT NULL,
+    courier_id   BIGINT REFERENCES couriers(id) ON DELETE SET NULL,
+    delivery_id  BIGINT,
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    window_end   TIMESTAMP WITH TIME ZONE NOT NULL,
+    state        TEXT NOT NULL DEFAULT 'scheduled' CHECK (state IN ('scheduled','assigned','queued','cancelled')),
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    CHECK (window_end > window_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_deliveries_due ON scheduled_deliveries(window_start) WHERE state = 'scheduled';
+CREATE INDEX IF NOT EXISTS idx_scheduled_deliveries_courier ON scheduled_deliveries(courier_id, window_start);
+
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS window_start TIMESTAMP WITH TIME ZONE;
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS window_end TIMESTAMP WITH TIME ZONE;
+
+-- +goose Down
+ALTER TABLE deliveries DROP COLUMN IF EXISTS window_end;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS window_start;
+DROP TABLE IF EXISTS scheduled_deliveries;
@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS routes (
+    id         BIGSERIAL PRIMARY KEY,
+    courier_id BIGINT NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+    status     TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active','completed')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_routes_courier ON routes(courier_id, created_at);
+
+CREATE TABLE IF NOT EXISTS route_stops (
+    route_id BIGINT NOT NULL REFERENCES routes(id) ON DELETE CASCADE,
+    seq      INTEGER NOT NULL,
+    order_id TEXT NOT NULL,
+    kind     TEXT NOT NULL CHECK (kind IN ('pickup','dropoff')),
+    lat      DOUBLE PRECISION,
+    lon      DOUBLE PRECISION,
+    PRIMARY KEY (route_id, seq)
+);
+
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS route_id BIGINT REFERENCES routes(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_deliveries_route ON deliveries(route_id) WHERE route_id IS NOT NULL;
+
+-- Orders collected by the route batcher until its next run.
+CREATE TABLE IF NOT EXISTS route_batch_orders (
+    order_id     TEXT PRIMARY KEY,
+    payload      JSONB NOT NULL,
+    collected_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS route_batch_orders;
+DROP INDEX IF EXISTS idx_deliveries_route;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS route_id;
+DROP TABLE IF EXISTS route_stops;
+DROP TABLE IF EXISTS routes;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE route_stops ADD COLUMN IF NOT EXISTS eta TIMESTAMP WITH TIME ZONE;
+ALTER TABLE route_stops ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS eta TIMESTAMP WITH TIME ZONE;
+
+-- +goose Down
+ALTER TABLE deliveries DROP COLUMN IF EXISTS eta;
+ALTER TABLE route_stops DROP COLUMN IF EXISTS completed_at;
+ALTER TABLE route_stops DROP COLUMN IF EXISTS eta;
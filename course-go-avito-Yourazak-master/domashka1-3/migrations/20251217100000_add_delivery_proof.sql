@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS pin_hash TEXT;
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS pin_verified BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS signature_key TEXT;
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS photo_key TEXT;
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS completion_lat DOUBLE PRECISION;
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS completion_lon DOUBLE PRECISION;
+
+-- +goose Down
+ALTER TABLE deliveries DROP COLUMN IF EXISTS completion_lon;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS completion_lat;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS photo_key;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS signature_key;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS pin_verified;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS completed_at;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS pin_hash;
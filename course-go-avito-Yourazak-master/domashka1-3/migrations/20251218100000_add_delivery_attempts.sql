@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'delivery'
+    CHECK (kind IN ('delivery', 'return'));
+
+CREATE TABLE IF NOT EXISTS delivery_attempts (
+    id          BIGSERIAL PRIMARY KEY,
+    order_id    TEXT NOT NULL,
+    delivery_id BIGINT NOT NULL,
+    courier_id  BIGINT NOT NULL,
+    attempt     INT NOT NULL,
+    reason      TEXT NOT NULL,
+    comment     TEXT,
+    outcome     TEXT NOT NULL CHECK (outcome IN ('retry', 'return')),
+    lat         DOUBLE PRECISION,
+    lon         DOUBLE PRECISION,
+    actor       TEXT NOT NULL,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (order_id, attempt)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS delivery_attempts;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS picked_up_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_deliveries_courier_active ON deliveries(courier_id, assigned_at)
+    WHERE status IN ('assigned', 'picked_up');
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_deliveries_courier_active;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS picked_up_at;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS delivery_offers (
+    id           BIGSERIAL PRIMARY KEY,
+    order_id     TEXT NOT NULL,
+    courier_id   BIGINT NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+    payload      JSONB NOT NULL,
+    state        TEXT NOT NULL DEFAULT 'pending'
+        CHECK (state IN ('pending', 'accepted', 'declined', 'expired', 'cancelled')),
+    expires_at   TIMESTAMP WITH TIME ZONE NOT NULL,
+    responded_at TIMESTAMP WITH TIME ZONE,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- An order is offered to one courier at a time, and a courier holds at most
+-- one offer: while it is pending the courier is reserved for it.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_delivery_offers_order_pending ON delivery_offers(order_id) WHERE state = 'pending';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_delivery_offers_courier_pending ON delivery_offers(courier_id) WHERE state = 'pending';
+CREATE INDEX IF NOT EXISTS idx_delivery_offers_expiry ON delivery_offers(expires_at) WHERE state = 'pending';
+CREATE INDEX IF NOT EXISTS idx_delivery_offers_courier ON delivery_offers(courier_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS delivery_offers;
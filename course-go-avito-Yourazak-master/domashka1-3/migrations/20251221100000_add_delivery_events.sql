@@ -0,0 +1,53 @@
+-- +goose Up
+-- Publishes every delivery change on the delivery_events channel so that
+-- each replica can push it to its stream subscribers, whichever replica or
+-- code path made the change.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_delivery_event() RETURNS trigger AS $$
+DECLARE
+    event_type TEXT;
+    cur        deliveries;
+    prev       deliveries;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        event_type := 'assigned';
+        cur := NEW;
+    ELSIF TG_OP = 'DELETE' THEN
+        event_type := 'unassigned';
+        cur := OLD;
+        prev := OLD;
+    ELSIF NEW.courier_id IS DISTINCT FROM OLD.courier_id THEN
+        event_type := 'reassigned';
+        cur := NEW;
+        prev := OLD;
+    ELSIF NEW.status IS DISTINCT FROM OLD.status THEN
+        event_type := CASE WHEN NEW.status = 'expired' THEN 'expired' ELSE 'status_changed' END;
+        cur := NEW;
+        prev := OLD;
+    ELSE
+        RETURN NULL;
+    END IF;
+
+    PERFORM pg_notify('delivery_events', json_build_object(
+        'type', event_type,
+        'delivery_id', cur.id,
+        'order_id', cur.order_id,
+        'courier_id', cur.courier_id,
+        'previous_courier_id', CASE WHEN prev.courier_id IS DISTINCT FROM cur.courier_id THEN prev.courier_id END,
+        'status', cur.status,
+        'previous_status', CASE WHEN TG_OP = 'UPDATE' THEN prev.status END,
+        'kind', cur.kind,
+        'at', now()
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER deliveries_notify_event
+    AFTER INSERT OR UPDATE OF status, courier_id OR DELETE ON deliveries
+    FOR EACH ROW EXECUTE FUNCTION notify_delivery_event();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS deliveries_notify_event ON deliveries;
+DROP FUNCTION IF EXISTS notify_delivery_event();
@@ -0,0 +1,89 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id                   BIGSERIAL PRIMARY KEY,
+    url                  TEXT NOT NULL,
+    event_types          TEXT[] NOT NULL,
+    secret               TEXT NOT NULL,
+    active               BOOLEAN NOT NULL DEFAULT TRUE,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    disabled_at          TIMESTAMP WITH TIME ZONE,
+    created_at           TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at           TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event           TEXT NOT NULL,
+    payload         JSONB NOT NULL,
+    state           TEXT NOT NULL DEFAULT 'pending' CHECK (state IN ('pending', 'delivered', 'failed')),
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    last_status     INTEGER,
+    last_error      TEXT,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    delivered_at    TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at, id)
+    WHERE state = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, id DESC);
+
+-- Queues a webhook delivery for every active subscription to the event in the
+-- same transaction as the delivery change, so no event is lost whichever
+-- code path made it.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION enqueue_delivery_webhooks() RETURNS trigger AS $$
+DECLARE
+    event_type TEXT;
+    cur        deliveries;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        event_type := 'assigned';
+        cur := NEW;
+    ELSIF TG_OP = 'DELETE' THEN
+        IF OLD.status NOT IN ('assigned', 'picked_up') THEN
+            RETURN NULL;
+        END IF;
+        event_type := 'unassigned';
+        cur := OLD;
+    ELSIF NEW.courier_id IS DISTINCT FROM OLD.courier_id THEN
+        event_type := 'assigned';
+        cur := NEW;
+    ELSIF NEW.status IS DISTINCT FROM OLD.status AND NEW.status IN ('completed', 'expired') THEN
+        event_type := NEW.status;
+        cur := NEW;
+    ELSE
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO webhook_deliveries (subscription_id, event, payload)
+    SELECT s.id, event_type, json_build_object(
+        'event', event_type,
+        'delivery_id', cur.id,
+        'order_id', cur.order_id,
+        'courier_id', cur.courier_id,
+        'status', cur.status,
+        'kind', cur.kind,
+        'occurred_at', now()
+    )
+    FROM webhook_subscriptions s
+    WHERE s.active AND event_type = ANY(s.event_types);
+
+    IF FOUND THEN
+        PERFORM pg_notify('webhook_pending', '');
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER deliveries_enqueue_webhooks
+    AFTER INSERT OR UPDATE OF status, courier_id OR DELETE ON deliveries
+    FOR EACH ROW EXECUTE FUNCTION enqueue_delivery_webhooks();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS deliveries_enqueue_webhooks ON deliveries;
+DROP FUNCTION IF EXISTS enqueue_delivery_webhooks();
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- An order that keeps failing waits longer between attempts and is given up
+-- ('failed') after the attempt limit, so it cannot hold the head of the
+-- queue forever.
+ALTER TABLE pending_assignments
+    ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT 'pending' CHECK (state IN ('pending', 'failed')),
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS idx_pending_assignments_due ON pending_assignments(next_attempt_at) WHERE state = 'pending';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_pending_assignments_due;
+ALTER TABLE pending_assignments
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS state;
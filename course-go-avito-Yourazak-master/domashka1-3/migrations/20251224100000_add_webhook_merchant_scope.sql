@@ -0,0 +1,104 @@
+-- +goose Up
+-- Deliveries remember the merchant whose order they carry, and a
+-- subscription with a merchant only receives events for that merchant's
+-- deliveries. Subscriptions without one still receive every event.
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS merchant_id TEXT;
+ALTER TABLE webhook_subscriptions ADD COLUMN IF NOT EXISTS merchant_id TEXT;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION enqueue_delivery_webhooks() RETURNS trigger AS $$
+DECLARE
+    event_type TEXT;
+    cur        deliveries;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        event_type := 'assigned';
+        cur := NEW;
+    ELSIF TG_OP = 'DELETE' THEN
+        IF OLD.status NOT IN ('assigned', 'picked_up') THEN
+            RETURN NULL;
+        END IF;
+        event_type := 'unassigned';
+        cur := OLD;
+    ELSIF NEW.courier_id IS DISTINCT FROM OLD.courier_id THEN
+        event_type := 'assigned';
+        cur := NEW;
+    ELSIF NEW.status IS DISTINCT FROM OLD.status AND NEW.status IN ('completed', 'expired') THEN
+        event_type := NEW.status;
+        cur := NEW;
+    ELSE
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO webhook_deliveries (subscription_id, event, payload)
+    SELECT s.id, event_type, json_build_object(
+        'event', event_type,
+        'delivery_id', cur.id,
+        'order_id', cur.order_id,
+        'courier_id', cur.courier_id,
+        'status', cur.status,
+        'kind', cur.kind,
+        'merchant_id', cur.merchant_id,
+        'occurred_at', now()
+    )
+    FROM webhook_subscriptions s
+    WHERE s.active AND event_type = ANY(s.event_types)
+      AND (s.merchant_id IS NULL OR s.merchant_id = cur.merchant_id);
+
+    IF FOUND THEN
+        PERFORM pg_notify('webhook_pending', '');
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION enqueue_delivery_webhooks() RETURNS trigger AS $$
+DECLARE
+    event_type TEXT;
+    cur        deliveries;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        event_type := 'assigned';
+        cur := NEW;
+    ELSIF TG_OP = 'DELETE' THEN
+        IF OLD.status NOT IN ('assigned', 'picked_up') THEN
+            RETURN NULL;
+        END IF;
+        event_type := 'unassigned';
+        cur := OLD;
+    ELSIF NEW.courier_id IS DISTINCT FROM OLD.courier_id THEN
+        event_type := 'assigned';
+        cur := NEW;
+    ELSIF NEW.status IS DISTINCT FROM OLD.status AND NEW.status IN ('completed', 'expired') THEN
+        event_type := NEW.status;
+        cur := NEW;
+    ELSE
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO webhook_deliveries (subscription_id, event, payload)
+    SELECT s.id, event_type, json_build_object(
+        'event', event_type,
+        'delivery_id', cur.id,
+        'order_id', cur.order_id,
+        'courier_id', cur.courier_id,
+        'status', cur.status,
+        'kind', cur.kind,
+        'occurred_at', now()
+    )
+    FROM webhook_subscriptions s
+    WHERE s.active AND event_type = ANY(s.event_types);
+
+    IF FOUND THEN
+        PERFORM pg_notify('webhook_pending', '');
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+ALTER TABLE webhook_subscriptions DROP COLUMN IF EXISTS merchant_id;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS merchant_id;